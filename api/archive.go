@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+)
+
+// maxArchiveUpload caps the multipart body of an archive upload
+const maxArchiveUpload = converter.MaxArchiveBytes + 1<<20
+
+// ArchiveHandler converts a zip or tar.gz of HTML files uploaded in the
+// multipart field "archive" into a zip of Go files sharing one package, plus
+// manifest.json with per-file diagnostics. Conversion options are read from
+// the form fields package, packagePrefix, vuetifyPrefix, vuetifyXPrefix and
+// childrenMode.
+func ArchiveHandler(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	// Parse the upload
+	r.Body = http.MaxBytesReader(w, r.Body, maxArchiveUpload)
+	if err := r.ParseMultipartForm(maxArchiveUpload); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			apierror.Write(w, r, converter.ErrArchiveTooLarge)
+			return
+		}
+		apierror.Write(w, r, apierror.New(http.StatusBadRequest, apierror.CodeInvalidMultipart, "Invalid request format"))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	var errs converter.ValidationError
+	childrenMode := parseBoolField(&errs, "childrenMode", r.FormValue("childrenMode"))
+	verify := parseBoolField(&errs, "verify", r.FormValue("verify"))
+	if err := errs.Err(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	upload, _, err := r.FormFile("archive")
+	if err != nil {
+		e := apierror.New(http.StatusBadRequest, apierror.CodeArchiveRequired, "Archive file is required")
+		e.Field = "archive"
+		apierror.Write(w, r, e)
+		return
+	}
+	defer upload.Close()
+	data, err := io.ReadAll(upload)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(http.StatusBadRequest, apierror.CodeArchiveRequired, "Archive file could not be read"))
+		return
+	}
+
+	files, skipped, err := converter.ReadArchive(data)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	opts := converter.ArchiveOptions{
+		Package: r.FormValue("package"),
+		Options: converter.Request{
+			PackagePrefix:  r.FormValue("packagePrefix"),
+			VuetifyPrefix:  r.FormValue("vuetifyPrefix"),
+			VuetifyXPrefix: r.FormValue("vuetifyXPrefix"),
+			ChildrenMode:   childrenMode,
+			Verify:         verify,
+		},
+		Skipped: skipped,
+	}
+	output, manifest, err := converter.ConvertArchive(r.Context(), files, opts)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Send response
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", manifest.Package+".zip"))
+	w.Header().Set("X-Conversion-Converted", strconv.Itoa(manifest.Converted))
+	w.Header().Set("X-Conversion-Failed", strconv.Itoa(manifest.Failed))
+	if verify {
+		w.Header().Set("X-Conversion-Mismatched", strconv.Itoa(manifest.Mismatched))
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
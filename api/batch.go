@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+)
+
+// BatchHandler converts a batch of named HTML snippets concurrently. By
+// default it answers with a single JSON document once every item is done;
+// with ?stream=1 or Accept: application/x-ndjson it streams one JSON line
+// per item as soon as that item finishes.
+func BatchHandler(w http.ResponseWriter, r *http.Request) {
+	// Only allow POST requests
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	// Parse request body
+	limitBody(w, r)
+	var batch converter.BatchRequest
+	if err := decodeJSON(r, &batch); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := converter.ValidateBatch(batch); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	serveBatch(w, r, batch)
+}
+
+// serveBatch runs a validated batch, streaming the results if the client
+// asked for it
+func serveBatch(w http.ResponseWriter, r *http.Request, batch converter.BatchRequest) {
+	if wantsNDJSON(r) {
+		streamBatch(w, r, batch)
+		return
+	}
+
+	response := converter.ConvertBatch(r.Context(), batch, converter.DefaultBatchWorkers)
+
+	// Send response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
+
+// streamBatch writes each batch result as a line of NDJSON as it finishes
+func streamBatch(w http.ResponseWriter, r *http.Request, batch converter.BatchRequest) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	converter.RunBatch(r.Context(), batch, converter.DefaultBatchWorkers, func(result converter.BatchResult) {
+		encoder.Encode(result)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	})
+}
+
+// wantsNDJSON reports whether the client asked for a streamed response
+func wantsNDJSON(r *http.Request) bool {
+	if r.URL.Query().Get("stream") == "1" {
+		return true
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, _ := mime.ParseMediaType(accept); mediaType == "application/x-ndjson" {
+			return true
+		}
+	}
+	return false
+}
@@ -2,284 +2,253 @@ package api
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
+	"log"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 
-	"github.com/zhangshanwen/html2go/parse"
+	"html2go-converter/apierror"
+	"html2go-converter/config"
+	"html2go-converter/converter"
 )
 
 // ConversionRequest represents the JSON request body for conversion
-type ConversionRequest struct {
-	HTML           string `json:"html"`
-	PackagePrefix  string `json:"packagePrefix"`
-	VuetifyPrefix  string `json:"vuetifyPrefix"`
-	VuetifyXPrefix string `json:"vuetifyXPrefix"`
-	Direction      string `json:"direction"`
-	ChildrenMode   bool   `json:"childrenMode"`
-}
+type ConversionRequest = converter.Request
 
 // ConversionResponse represents the JSON response for conversion
-type ConversionResponse struct {
-	Code  string `json:"code,omitempty"`
-	HTML  string `json:"html,omitempty"`
-	Error string `json:"error,omitempty"`
-}
-
-// Handler is the API entry point for Vercel serverless functions
+type ConversionResponse = converter.Response
+
+// Handler is the API entry point for Vercel serverless functions. It serves
+// the legacy /convert and /api/convert routes unchanged for existing clients;
+// new clients use /api/v1/convert.
+//
+// Besides JSON, the body may be raw HTML sent as text/html with the options
+// in the query string, and clients that accept text/x-go or text/plain
+// receive the bare generated code:
+//
+//	curl --data-binary @page.html -H 'Content-Type: text/html' -H 'Accept: text/x-go' .../api/convert
 func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", `</api/v1/convert>; rel="successor-version"`)
+
 	// Only allow POST requests
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if !allowMethod(w, r, http.MethodPost) {
 		return
 	}
 
 	// Parse request body
+	limitBody(w, r)
 	var req ConversionRequest
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&req); err != nil {
-		sendJSONError(w, "Invalid request format", http.StatusBadRequest)
-		return
-	}
-
-	// Validate request
-	if req.HTML == "" {
-		sendJSONError(w, "HTML content is required", http.StatusBadRequest)
-		return
-	}
-
-	// Process based on direction
-	var response ConversionResponse
-	switch req.Direction {
-	case "html2go":
-		code, err := convertHTMLToGo(req.HTML, req.PackagePrefix, req.VuetifyPrefix, req.VuetifyXPrefix, req.ChildrenMode)
-		if err != nil {
-			sendJSONError(w, fmt.Sprintf("HTML to Go conversion error: %v", err), http.StatusInternalServerError)
+	if isHTMLBody(r) {
+		var err error
+		if req, err = readHTMLRequest(r); err != nil {
+			apierror.Write(w, r, err)
 			return
 		}
-		response.Code = code
-	case "go2html":
-		// Not implemented yet - might be added in a future update
-		sendJSONError(w, "Go to HTML conversion is not implemented yet", http.StatusNotImplemented)
-		return
-	default:
-		sendJSONError(w, "Invalid conversion direction", http.StatusBadRequest)
+	} else if err := decodeJSON(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// Send response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	response, cacheStatus, err := converter.ConvertCached(r.Context(), req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
+	sendConversion(w, r, response, cacheStatus)
 }
 
-func convertHTMLToGo(htmlContent, packagePrefix, vuetifyPrefix, vuetifyXPrefix string, childrenMode bool) (string, error) {
-	// The function takes a reader, so we need to convert our string to a reader
-	reader := strings.NewReader(htmlContent)
+// RemoveBodyWrapper removes "var n = Body(" or "var n = packagePrefix.Body(" from the beginning and ")" from the end of the code
+func RemoveBodyWrapper(code string) string {
+	return converter.RemoveBodyWrapper(code)
+}
 
-	// Using the Vuetify branch API
-	// Generate HTML Go code with support for Vuetify components
-	goCode := parse.GenerateHTMLGo(packagePrefix, vuetifyPrefix, vuetifyXPrefix, childrenMode, reader)
+// UseLimits sets the limits of every conversion and the quarantine of
+// crashing inputs, overriding the ones read from the environment
+func UseLimits(l converter.Limits, q *converter.Quarantine) {
+	limitsOnce.Do(func() {})
+	converter.UseLimits(l)
+	converter.UseQuarantine(q)
+}
 
-	// Process the generated code to extract important parts
-	goCode = stripWrappers(goCode)
+// UseCache sets the result cache, overriding the one configured by the
+// environment; nil disables caching
+func UseCache(c *converter.Cache) {
+	limitsOnce.Do(func() {})
+	converter.UseCache(c)
+}
 
-	// Always apply the RemoveBodyWrapper for all cases
-	goCode = RemoveBodyWrapper(goCode)
+var limitsOnce sync.Once
 
-	return goCode, nil
+// conversionLimits returns the limits of every conversion
+func conversionLimits() converter.Limits {
+	setupConversion()
+	return converter.CurrentLimits()
 }
 
-// stripWrappers removes the package declaration and h.Body wrapper, and trims whitespace
-func stripWrappers(code string) string {
-	// 移除包声明
-	if strings.Contains(code, "package hello") {
-		// 找到包声明之后的第一个非空行
-		lines := strings.Split(code, "\n")
-		startLine := 0
-		for i, line := range lines {
-			if strings.Contains(line, "package hello") {
-				startLine = i + 1
-				break
+// setupConversion configures the limits, the quarantine and the result
+// cache from the environment on first use, unless UseLimits or UseCache was
+// called
+func setupConversion() {
+	limitsOnce.Do(func() {
+		converter.UseLimits(config.ConversionLimits())
+		if maxBytes, dir, dirMaxBytes := config.Cache(); maxBytes > 0 {
+			if c, err := converter.NewCache(maxBytes, dir, dirMaxBytes); err == nil {
+				converter.UseCache(c)
+			} else {
+				log.Printf("Result cache disabled: %v", err)
 			}
 		}
-
-		// 跳过空行
-		for startLine < len(lines) && strings.TrimSpace(lines[startLine]) == "" {
-			startLine++
+		if dir, maxBytes := config.Quarantine(); dir != "" {
+			if q, err := converter.NewQuarantine(dir, maxBytes); err == nil {
+				converter.UseQuarantine(q)
+			} else {
+				log.Printf("Quarantine disabled: %v", err)
+			}
 		}
+	})
+}
 
-		if startLine < len(lines) {
-			code = strings.Join(lines[startLine:], "\n")
-		}
+// limitBody caps the body of r at the configured size
+func limitBody(w http.ResponseWriter, r *http.Request) {
+	if max := conversionLimits().MaxBodyBytes; max > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, max)
 	}
+}
 
-	// 移除 var n = 声明
-	if strings.Contains(code, "var n =") {
-		// 移除var n =行
-		lines := strings.Split(code, "\n")
-		startLine := 0
-		for i, line := range lines {
-			if strings.Contains(line, "var n =") {
-				startLine = i + 1
-				// 如果这行不包含h.Body，可能h.Body在下一行
-				if !strings.Contains(line, "h.Body(") {
-					for j := startLine; j < len(lines); j++ {
-						if strings.Contains(lines[j], "h.Body(") {
-							startLine = j + 1
-							break
-						}
-					}
-				}
-				break
-			}
-		}
-
-		if startLine > 0 && startLine < len(lines) {
-			// 拼接剩余内容
-			code = strings.Join(lines[startLine-1:], "\n")
+// decodeJSON decodes the JSON body of r into v
+func decodeJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		if tooLarge := bodyTooLarge(err); tooLarge != nil {
+			return tooLarge
 		}
+		return apierror.New(http.StatusBadRequest, apierror.CodeInvalidJSON, "Invalid request format")
 	}
+	return nil
+}
 
-	// 尝试定位并提取h.Body的内容
-	bodyStart := strings.Index(code, "h.Body(")
-	if bodyStart >= 0 {
-		// 找到第一个左括号后的内容
-		openingIndex := bodyStart + 7
-		// 找到匹配的最后一个闭合括号
-		depth := 1
-		closingIndex := -1
+// bodyTooLarge returns a *converter.LimitError if err comes from reading a
+// body past the limit set by limitBody, and nil otherwise
+func bodyTooLarge(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return &converter.LimitError{Err: converter.ErrBodyTooLarge, Limit: maxErr.Limit}
+	}
+	return nil
+}
 
-		for i := openingIndex; i < len(code); i++ {
-			if code[i] == '(' {
-				depth++
-			} else if code[i] == ')' {
-				depth--
-				if depth == 0 {
-					closingIndex = i
-					break
-				}
-			}
-		}
+// isHTMLBody reports whether the body of r is raw HTML
+func isHTMLBody(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "text/html"
+}
 
-		if closingIndex > openingIndex {
-			// 提取 h.Body(...) 内部的内容
-			innerCode := code[openingIndex:closingIndex]
-			code = innerCode
+// readHTMLRequest builds a request from a raw HTML body and the options in
+// the query string. The direction defaults to html2go.
+func readHTMLRequest(r *http.Request) (converter.Request, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if tooLarge := bodyTooLarge(err); tooLarge != nil {
+			return converter.Request{}, tooLarge
 		}
+		return converter.Request{}, apierror.New(http.StatusBadRequest, apierror.CodeBodyRequired, "Request body could not be read")
 	}
 
-	// 移除最后可能存在的闭合括号
-	code = removeTrailingParentheses(code)
-
-	// 移除末尾的逗号
-	code = removeTrailingCommas(code)
-
-	// 清理代码：修剪前后的空白
-	return strings.TrimSpace(code)
+	query := r.URL.Query()
+	req := converter.Request{
+		HTML:           string(body),
+		PackagePrefix:  query.Get("packagePrefix"),
+		VuetifyPrefix:  query.Get("vuetifyPrefix"),
+		VuetifyXPrefix: query.Get("vuetifyXPrefix"),
+		Direction:      converter.Direction(query.Get("direction")),
+	}
+	if req.Direction == "" {
+		req.Direction = converter.DirectionHTMLToGo
+	}
+	var errs converter.ValidationError
+	req.ChildrenMode = parseBoolField(&errs, "childrenMode", query.Get("childrenMode"))
+	req.Verify = parseBoolField(&errs, "verify", query.Get("verify"))
+	if err := errs.Err(); err != nil {
+		return converter.Request{}, err
+	}
+	return req, nil
 }
 
-// removeTrailingParentheses 移除字符串末尾多余的闭合括号
-func removeTrailingParentheses(s string) string {
-	s = strings.TrimSpace(s)
-	// 计算开括号和闭括号数量
-	openCount := strings.Count(s, "(")
-	closeCount := strings.Count(s, ")")
-
-	// 如果闭括号比开括号多，移除末尾多余的闭括号
-	if closeCount > openCount {
-		// 从末尾开始寻找多余的闭括号
-		excess := closeCount - openCount
-		for i := 0; i < excess; i++ {
-			if strings.HasSuffix(s, ")") {
-				s = strings.TrimSuffix(s, ")")
-				s = strings.TrimSpace(s)
-			}
-		}
+// parseBoolField parses the optional boolean value of a query or form
+// field, adding a field error to errs if it is not a boolean
+func parseBoolField(errs *converter.ValidationError, field, value string) bool {
+	if value == "" {
+		return false
 	}
-	return s
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		errs.Add(field, converter.FieldInvalidType, "must be a boolean")
+	}
+	return b
 }
 
-// removeTrailingCommas 移除代码末尾的逗号
-func removeTrailingCommas(s string) string {
-	s = strings.TrimSpace(s)
-	if strings.HasSuffix(s, ",") {
-		return strings.TrimSuffix(s, ",")
+// sendConversion sends response as JSON, or as bare code if the client
+// prefers text/x-go or text/plain. Cached results carry an ETag derived
+// from their hash.
+func sendConversion(w http.ResponseWriter, r *http.Request, response converter.Response, cacheStatus converter.CacheStatus) {
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("X-Cache", string(cacheStatus))
+	contentType := negotiateCode(r)
+	if response.Hash != "" {
+		w.Header().Set("ETag", conversionETag(response.Hash, contentType))
 	}
-
-	// 逐行检查，移除最后一行非空行末尾的逗号
-	lines := strings.Split(s, "\n")
-	lastNonEmptyLine := -1
-
-	for i := len(lines) - 1; i >= 0; i-- {
-		if strings.TrimSpace(lines[i]) != "" {
-			lastNonEmptyLine = i
-			break
-		}
+	if contentType == "application/json" {
+		sendJSON(w, response, http.StatusOK)
+		return
 	}
 
-	if lastNonEmptyLine >= 0 && strings.HasSuffix(strings.TrimSpace(lines[lastNonEmptyLine]), ",") {
-		lines[lastNonEmptyLine] = strings.TrimSuffix(strings.TrimSpace(lines[lastNonEmptyLine]), ",")
-		s = strings.Join(lines, "\n")
+	code := response.Code
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
 	}
-
-	return s
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, code)
 }
 
-// RemoveBodyWrapper removes "var n = Body(" or "var n = packagePrefix.Body(" from the beginning and ")" from the end of the code
-func RemoveBodyWrapper(code string) string {
-	// First trim any whitespace
-	code = strings.TrimSpace(code)
-
-	// Check if the code starts with "var n = Body("
-	if strings.HasPrefix(code, "var n = Body(") {
-		// Remove the prefix
-		code = code[len("var n = Body("):]
+// conversionETag returns the entity tag of a cached result sent as
+// contentType
+func conversionETag(hash, contentType string) string {
+	if contentType == "application/json" {
+		return `"` + hash + `"`
+	}
+	return `"` + hash + `.go"`
+}
 
-		// Remove the closing parenthesis at the end if exists
-		if strings.HasSuffix(code, ")") {
-			code = code[:len(code)-1]
+// negotiateCode picks the media type of a conversion response from the
+// Accept header: text/x-go or text/plain when the client ranks it above
+// JSON, and application/json otherwise
+func negotiateCode(r *http.Request) string {
+	plain, plainQ, jsonQ := "", 0.0, 0.0
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(accept)
+		if err != nil {
+			continue
 		}
-
-		// Trim any whitespace again
-		code = strings.TrimSpace(code)
-	} else {
-		// Check for "var n = <packagePrefix>.Body("
-		index := strings.Index(code, "var n = ")
-		if index == 0 {
-			bodyIndex := strings.Index(code, ".Body(")
-			if bodyIndex > 0 {
-				// Get the prefix length (var n = <packagePrefix>.Body()
-				prefixLength := bodyIndex + 6 // ".Body(" is 6 characters
-
-				// Remove the prefix
-				code = code[prefixLength:]
-
-				// Remove the closing parenthesis at the end if exists
-				if strings.HasSuffix(code, ")") {
-					code = code[:len(code)-1]
-				}
-
-				// Trim any whitespace again
-				code = strings.TrimSpace(code)
+		q := 1.0
+		if v, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			q = v
+		}
+		switch mediaType {
+		case "text/x-go", "text/plain":
+			if q > plainQ {
+				plain, plainQ = mediaType, q
 			}
+		case "application/json", "application/*", "*/*":
+			jsonQ = max(jsonQ, q)
 		}
 	}
-
-	return code
-}
-
-func sendJSONError(w http.ResponseWriter, errMsg string, statusCode int) {
-	response := ConversionResponse{
-		Error: errMsg,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		http.Error(w, errMsg, statusCode)
+	if plain != "" && plainQ > jsonQ {
+		return plain
 	}
+	return "application/json"
 }
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"html2go-converter/apierror"
+	"html2go-converter/config"
+	"html2go-converter/examples"
+)
+
+var (
+	// exampleRegistry is the example gallery. Unless UseExamples is called,
+	// the directory configured by the environment or the built-in examples
+	// are loaded on first use.
+	exampleRegistry *examples.Registry
+	examplesErr     error
+	examplesMu      sync.Mutex
+)
+
+// UseExamples serves the example gallery from r
+func UseExamples(r *examples.Registry) {
+	examplesMu.Lock()
+	defer examplesMu.Unlock()
+	exampleRegistry = r
+	examplesErr = nil
+}
+
+// currentExamples returns the example gallery, loading it if needed
+func currentExamples() (*examples.Registry, error) {
+	examplesMu.Lock()
+	defer examplesMu.Unlock()
+	if exampleRegistry == nil && examplesErr == nil {
+		fsys := examples.Embedded()
+		if dir := config.ExamplesDir(); dir != "" {
+			fsys = os.DirFS(dir)
+		}
+		exampleRegistry, examplesErr = examples.Load(fsys)
+	}
+	return exampleRegistry, examplesErr
+}
+
+// ExamplesResponse lists the examples matching a filter, with every
+// category and tag of the gallery to build filters from
+type ExamplesResponse struct {
+	Examples   []examples.Summary `json:"examples"`
+	Categories []string           `json:"categories"`
+	Tags       []string           `json:"tags"`
+}
+
+// ExamplesHandler serves the example gallery:
+//
+//	GET /api/examples?category=&tag=&q= list the matching examples
+//	GET /api/examples/{id}              an example with its converted code
+func ExamplesHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	registry, err := currentExamples()
+	if err != nil {
+		apierror.Write(w, r, &apierror.Error{
+			Status:  http.StatusInternalServerError,
+			Code:    apierror.CodeInternal,
+			Message: "Examples could not be loaded",
+			Err:     err,
+		})
+		return
+	}
+
+	// Examples only change with a deployment
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/examples"), "/")
+	if id == "" {
+		query := r.URL.Query()
+		sendJSON(w, ExamplesResponse{
+			Examples: registry.List(examples.Filter{
+				Category: query.Get("category"),
+				Tag:      query.Get("tag"),
+				Query:    query.Get("q"),
+			}),
+			Categories: registry.Categories(),
+			Tags:       registry.Tags(),
+		}, http.StatusOK)
+		return
+	}
+
+	example, err := registry.Get(id)
+	if err != nil {
+		w.Header().Del("Cache-Control")
+		apierror.Write(w, r, err)
+		return
+	}
+	sendJSON(w, example, http.StatusOK)
+}
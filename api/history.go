@@ -0,0 +1,307 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"html2go-converter/apierror"
+	"html2go-converter/config"
+	"html2go-converter/converter"
+	"html2go-converter/history"
+)
+
+var (
+	// historyStore keeps conversion histories. Unless UseHistoryStore is
+	// called, the store configured by the environment is opened on first use.
+	historyStore  history.Store
+	historyPolicy = history.DefaultPolicy
+	historyMu     sync.Mutex
+)
+
+// UseHistoryStore keeps conversion histories in s, bounded by p
+func UseHistoryStore(s history.Store, p history.Policy) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	historyStore = s
+	historyPolicy = p
+}
+
+// currentHistoryStore returns the history store and its policy, opening the
+// store configured by HISTORY_DB if needed
+func currentHistoryStore() (history.Store, history.Policy) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if historyStore == nil {
+		path, policy := config.History()
+		historyPolicy = policy
+		historyStore = history.NewMemoryStore()
+		if path != "" {
+			if s, err := history.OpenBoltStore(path); err == nil {
+				historyStore = s
+			} else {
+				log.Printf("Keeping conversion histories in memory: %v", err)
+			}
+		}
+	}
+	return historyStore, historyPolicy
+}
+
+// sweepHistories bounds the history store after a history was started, so
+// that it stays bounded without a background sweeper
+func sweepHistories(store history.Store, policy history.Policy, now time.Time) {
+	if n, err := store.Sweep(now, policy); err != nil {
+		log.Printf("Failed to sweep histories: %v", err)
+	} else if n > 0 {
+		log.Printf("Removed %d histories", n)
+	}
+}
+
+// RevisionSummary describes a revision without its input and output
+type RevisionSummary struct {
+	Number         int                 `json:"number"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	RestoredFrom   int                 `json:"restoredFrom,omitempty"`
+	PackagePrefix  string              `json:"packagePrefix"`
+	VuetifyPrefix  string              `json:"vuetifyPrefix"`
+	VuetifyXPrefix string              `json:"vuetifyXPrefix"`
+	Direction      converter.Direction `json:"direction"`
+	URL            string              `json:"url"`
+}
+
+// RevisionResponse is a revision of the history HistoryID
+type RevisionResponse struct {
+	HistoryID string `json:"historyId"`
+	history.Revision
+}
+
+// HistoryResponse lists the revisions of a history, oldest first
+type HistoryResponse struct {
+	ID        string            `json:"id"`
+	Head      int               `json:"head"`
+	Revisions []RevisionSummary `json:"revisions"`
+}
+
+func revisionURL(id string, number int) string {
+	return fmt.Sprintf("/api/history/%s/revisions/%d", id, number)
+}
+
+// HistoryHandler serves persisted conversion histories:
+//
+//	POST /api/history                              start a history with a first revision,
+//	                                               under an ID chosen by the server
+//	GET  /api/history/{id}/revisions               list the revisions
+//	POST /api/history/{id}/revisions               save a revision
+//	GET  /api/history/{id}/revisions/{n}           fetch a revision
+//	POST /api/history/{id}/revisions/{n}/restore   make a copy of revision n the head
+//	GET  /api/history/{id}/diff?from={a}&to={b}    compare two revisions, by default
+//	                                               the head and its predecessor
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/history"), "/")
+	parts := strings.Split(rest, "/")
+	id := parts[0]
+	if id != "" && !history.ValidID(id) {
+		apierror.Write(w, r, history.ErrNotFound)
+		return
+	}
+
+	switch {
+	case rest == "":
+		if allowMethod(w, r, http.MethodPost) {
+			saveRevision(w, r, "")
+		}
+	case len(parts) == 2 && parts[1] == "revisions":
+		switch r.Method {
+		case http.MethodGet:
+			listRevisions(w, r, id)
+		case http.MethodPost:
+			saveRevision(w, r, id)
+		default:
+			allowMethod(w, r, http.MethodGet, http.MethodPost)
+		}
+	case len(parts) == 3 && parts[1] == "revisions":
+		if allowMethod(w, r, http.MethodGet) {
+			getRevision(w, r, id, parts[2])
+		}
+	case len(parts) == 4 && parts[1] == "revisions" && parts[3] == "restore":
+		if allowMethod(w, r, http.MethodPost) {
+			restoreRevision(w, r, id, parts[2])
+		}
+	case len(parts) == 2 && parts[1] == "diff":
+		if allowMethod(w, r, http.MethodGet) {
+			diffRevisions(w, r, id)
+		}
+	default:
+		apierror.Write(w, r, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "Not found"))
+	}
+}
+
+// saveRevision converts the request and saves it as the head of history id,
+// or as the first revision of a new history if id is ""
+func saveRevision(w http.ResponseWriter, r *http.Request, id string) {
+	limitBody(w, r)
+	var req converter.Request
+	if err := decodeJSON(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Direction == "" {
+		req.Direction = "html2go"
+	}
+	response, _, err := converter.ConvertCached(r.Context(), req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	store, policy := currentHistoryStore()
+	now := time.Now()
+	var rev history.Revision
+	created := true
+	if id == "" {
+		if id, rev, err = history.Start(store, req, response.Code, now); err == nil {
+			sweepHistories(store, policy, now)
+		}
+	} else {
+		rev, created, err = history.Save(store, policy, id, req, response.Code, now)
+	}
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+	// Saving the head again is not an error, but creates nothing
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Location", revisionURL(id, rev.Number))
+	sendJSON(w, RevisionResponse{id, rev}, status)
+}
+
+func listRevisions(w http.ResponseWriter, r *http.Request, id string) {
+	store, _ := currentHistoryStore()
+	revisions, err := store.Revisions(id)
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+	resp := HistoryResponse{ID: id, Head: len(revisions), Revisions: make([]RevisionSummary, len(revisions))}
+	for i, rev := range revisions {
+		resp.Revisions[i] = RevisionSummary{
+			Number:         rev.Number,
+			CreatedAt:      rev.CreatedAt,
+			RestoredFrom:   rev.RestoredFrom,
+			PackagePrefix:  rev.PackagePrefix,
+			VuetifyPrefix:  rev.VuetifyPrefix,
+			VuetifyXPrefix: rev.VuetifyXPrefix,
+			Direction:      rev.Direction,
+			URL:            revisionURL(id, rev.Number),
+		}
+	}
+	sendJSON(w, resp, http.StatusOK)
+}
+
+func getRevision(w http.ResponseWriter, r *http.Request, id, number string) {
+	n, err := parseRevision("number", number)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	store, _ := currentHistoryStore()
+	rev, err := store.Revision(id, n)
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+	sendJSON(w, RevisionResponse{id, rev}, http.StatusOK)
+}
+
+func restoreRevision(w http.ResponseWriter, r *http.Request, id, number string) {
+	n, err := parseRevision("number", number)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	store, policy := currentHistoryStore()
+	rev, err := history.Restore(store, policy, id, n, time.Now())
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", revisionURL(id, rev.Number))
+	sendJSON(w, RevisionResponse{id, rev}, http.StatusCreated)
+}
+
+func diffRevisions(w http.ResponseWriter, r *http.Request, id string) {
+	store, _ := currentHistoryStore()
+	revisions, err := store.Revisions(id)
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+
+	to, from := len(revisions), len(revisions)-1
+	query := r.URL.Query()
+	if v := query.Get("to"); v != "" {
+		if to, err = parseRevision("to", v); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		from = to - 1
+	}
+	if v := query.Get("from"); v != "" {
+		if from, err = parseRevision("from", v); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	// A history of one revision compares it with itself
+	from = max(from, 1)
+
+	a, err := store.Revision(id, from)
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+	b, err := store.Revision(id, to)
+	if err != nil {
+		sendHistoryError(w, r, err)
+		return
+	}
+	d, err := history.Compare(a, b)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	sendJSON(w, d, http.StatusOK)
+}
+
+// parseRevision parses the revision number given in field
+func parseRevision(field, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		e := apierror.New(http.StatusBadRequest, apierror.CodeInvalidRevision, "Revision numbers are positive integers")
+		e.Field = field
+		return 0, e
+	}
+	return n, nil
+}
+
+// sendHistoryError maps a history store error to an HTTP error response
+func sendHistoryError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, history.ErrNotFound) || errors.Is(err, history.ErrRevisionNotFound) || errors.Is(err, history.ErrHistoryFull) {
+		apierror.Write(w, r, err)
+		return
+	}
+	apierror.Write(w, r, &apierror.Error{
+		Status:  http.StatusInternalServerError,
+		Code:    apierror.CodeInternal,
+		Message: "History store error",
+		Err:     err,
+	})
+}
@@ -1,13 +1,141 @@
 package api
 
 import (
+	"bytes"
+	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"html2go-converter/assets"
+	"html2go-converter/config"
+	"html2go-converter/middleware"
+)
+
+var (
+	// assetSource is where static files are served from. It defaults to the
+	// copy of the public directory embedded in the binary.
+	assetSource = assets.Embedded()
+
+	// assetCatalog holds the fingerprinted, precompressed files of
+	// assetSource. It is built on first use.
+	assetCatalog *assets.Catalog
+	catalogMu    sync.Mutex
+
+	// appConfig is injected into index.html; indexPage caches the rendered
+	// page until the assets or the configuration change.
+	appConfig = config.FromEnv()
+	indexPage *assets.File
 )
 
+// indexData is the data index.html is rendered with
+type indexData struct {
+	Config config.App
+	// Snippet pre-populates the editors on /s/{id}; SnippetError explains
+	// why a shared snippet could not be shown
+	Snippet      *SnippetResponse
+	SnippetError string
+}
+
+// UseAssets serves static files from src, e.g. an overlay of a theme
+// directory on top of the embedded files.
+func UseAssets(src assets.Source) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	assetSource = src
+	assetCatalog = nil
+	indexPage = nil
+}
+
+// SetAppConfig replaces the configuration injected into index.html
+func SetAppConfig(cfg config.App) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	appConfig = cfg
+	indexPage = nil
+}
+
+// UsePublicDir serves static files from dir on disk instead of the embedded
+// copy, so edits to the frontend show up without rebuilding. An empty dir
+// switches back to the embedded files.
+func UsePublicDir(dir string) {
+	if dir == "" {
+		UseAssets(assets.Embedded())
+		return
+	}
+	UseAssets(assets.Dir(dir))
+}
+
+// currentCatalog returns the catalog of the current asset source, building it
+// if needed or if the files of a directory source changed
+func currentCatalog() (*assets.Catalog, error) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if assetCatalog != nil && assetCatalog.Stale() {
+		assetCatalog = nil
+		indexPage = nil
+	}
+	if assetCatalog == nil {
+		catalog, err := assets.NewCatalog(assetSource)
+		if err != nil {
+			return nil, err
+		}
+		assetCatalog = catalog
+	}
+	return assetCatalog, nil
+}
+
+// renderedIndex returns index.html rendered with the application
+// configuration, rendering it on first use
+func renderedIndex() (*assets.File, error) {
+	catalog, err := currentCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	if indexPage == nil {
+		page, err := renderIndex(catalog, indexData{Config: appConfig})
+		if err != nil {
+			return nil, err
+		}
+		indexPage = page
+	}
+	return indexPage, nil
+}
+
+// renderIndex renders index.html of catalog with data
+func renderIndex(catalog *assets.Catalog, data indexData) (*assets.File, error) {
+	file, _, ok := catalog.Lookup("index.html")
+	if !ok {
+		return nil, fmt.Errorf("index.html not found in any asset layer")
+	}
+	return assets.Render(file, data)
+}
+
+// executeIndex renders index.html of catalog with data for a single response,
+// without preparing compressed variants that would not be served
+func executeIndex(catalog *assets.Catalog, data indexData) ([]byte, error) {
+	file, _, ok := catalog.Lookup("index.html")
+	if !ok {
+		return nil, fmt.Errorf("index.html not found in any asset layer")
+	}
+	return assets.Execute(file, data)
+}
+
+// currentAppConfig returns the configuration injected into index.html
+func currentAppConfig() config.App {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	return appConfig
+}
+
 // Index function for serving static files or redirecting to index.html
 func Index(w http.ResponseWriter, r *http.Request) {
 	// Set CORS headers
@@ -30,8 +158,8 @@ func Index(w http.ResponseWriter, r *http.Request) {
 	// Log the request path
 	log.Printf("Handling request for path: %s", r.URL.Path)
 
-	// If the request is for root, serve index.html
-	if r.URL.Path == "/" {
+	// If the request is for root, serve the rendered index.html
+	if r.URL.Path == "/" || r.URL.Path == "/index.html" || r.URL.Path == "/static/index.html" {
 		serveIndexHTML(w, r)
 		return
 	}
@@ -57,80 +185,110 @@ func isStaticFileRequest(path string) bool {
 	return false
 }
 
-// serveStaticFile serves a static file from the public directory
+// serveStaticFile serves a static file from the asset catalog
 func serveStaticFile(w http.ResponseWriter, r *http.Request) {
-	// Get the file path from the URL
-	filePath := r.URL.Path
-
-	// If the path starts with /static/, remove it
-	if strings.HasPrefix(filePath, "/static/") {
-		filePath = strings.TrimPrefix(filePath, "/static/")
-	}
-
-	// Construct the full path to the file in the public directory
-	fullPath := filepath.Join("public", filePath)
-
-	// Log the file path
-	log.Printf("Attempting to serve static file: %s", fullPath)
-
-	// Check if the file exists
-	_, err := os.Stat(fullPath)
-	if os.IsNotExist(err) {
-		// Try alternative paths in Vercel environment
-		if os.Getenv("VERCEL") != "" {
-			alternatives := []string{
-				filepath.Join("/var/task/public", filePath),
-				filepath.Join("/public", filePath),
-			}
-
-			for _, alt := range alternatives {
-				if _, err := os.Stat(alt); err == nil {
-					fullPath = alt
-					log.Printf("Found file at alternative path: %s", fullPath)
-					break
-				}
-			}
-		}
+	// Get the file path from the URL, dropping the optional /static/ prefix
+	filePath := strings.TrimPrefix(r.URL.Path, "/static/")
+	filePath = strings.TrimPrefix(path.Clean("/"+filePath), "/")
+
+	catalog, err := currentCatalog()
+	if err != nil {
+		log.Printf("Error: Could not load assets: %v", err)
+		http.Error(w, "Unable to load static files", http.StatusInternalServerError)
+		return
 	}
 
-	// Check if the file exists at the resolved path
-	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-		log.Printf("File not found: %s", fullPath)
+	file, immutable, ok := catalog.Lookup(filePath)
+	if !ok {
+		log.Printf("File not found: %s", filePath)
 		http.Error(w, "File not found", http.StatusNotFound)
 		return
 	}
 
-	// Set the appropriate content type based on file extension
-	setContentType(w, fullPath)
+	// Fingerprinted names never change content, so they can be cached forever
+	if immutable {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
 
-	// Serve the file
-	http.ServeFile(w, r, fullPath)
+	serveAsset(w, r, file)
 }
 
 // serveIndexHTML serves the index.html file
 func serveIndexHTML(w http.ResponseWriter, r *http.Request) {
-	// Define possible index.html paths
-	possiblePaths := []string{
-		"public/index.html",
-		"/var/task/public/index.html",
-		"/public/index.html",
-	}
-
-	// Try to find and serve the index.html file
-	for _, path := range possiblePaths {
-		content, err := os.ReadFile(path)
-		if err == nil {
-			log.Printf("Serving index.html from: %s", path)
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.WriteHeader(http.StatusOK)
-			w.Write(content)
-			return
-		}
+	file, err := renderedIndex()
+	if err != nil {
+		log.Printf("Error: Could not render index.html: %v", err)
+		http.Error(w, "Unable to find index.html file", http.StatusInternalServerError)
+		return
+	}
+
+	// With a CSP nonce the page differs per request and must not be cached
+	if nonce := middleware.Nonce(r); nonce != "" {
+		log.Printf("Serving %s from %s", file.Name, file.Layer)
+		w.Header().Set("X-Asset-Layer", file.Layer)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		w.Write(injectNonce(file.Content, nonce))
+		return
 	}
 
-	// If we couldn't find the index.html file, return an error
-	log.Printf("Error: Could not find index.html in any location")
-	http.Error(w, "Unable to find index.html file", http.StatusInternalServerError)
+	// index.html references fingerprinted assets, so it must be revalidated
+	w.Header().Set("Cache-Control", "no-cache")
+	serveAsset(w, r, file)
+}
+
+// injectNonce adds a nonce attribute to every script tag of an HTML document,
+// so inline scripts pass the Content-Security-Policy
+func injectNonce(content []byte, nonce string) []byte {
+	return bytes.ReplaceAll(content, []byte("<script"), []byte(`<script nonce="`+nonce+`"`))
+}
+
+// serveAsset writes a catalog file, choosing a precompressed variant the
+// client accepts and answering If-None-Match with 304 Not Modified
+func serveAsset(w http.ResponseWriter, r *http.Request, file *assets.File) {
+	// Report which asset layer served the file
+	log.Printf("Serving %s from %s", file.Name, file.Layer)
+	w.Header().Set("X-Asset-Layer", file.Layer)
+
+	// Set the appropriate content type based on file extension
+	setContentType(w, file.Name)
+
+	content, etag := file.Content, file.ETag
+	if file.Gzip != nil || file.Brotli != nil {
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	switch {
+	case file.Brotli != nil && acceptsEncoding(r, "br"):
+		w.Header().Set("Content-Encoding", "br")
+		content, etag = file.Brotli, strings.TrimSuffix(etag, `"`)+`-br"`
+	case file.Gzip != nil && acceptsEncoding(r, "gzip"):
+		w.Header().Set("Content-Encoding", "gzip")
+		content, etag = file.Gzip, strings.TrimSuffix(etag, `"`)+`-gz"`
+	}
+	w.Header().Set("ETag", etag)
+
+	// ServeContent handles If-None-Match against the ETag header
+	http.ServeContent(w, r, file.Name, time.Time{}, bytes.NewReader(content))
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header allows coding
+func acceptsEncoding(r *http.Request, coding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), coding) {
+			continue
+		}
+		// A quality of zero means "not acceptable"
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(v, 64)
+			return err == nil && q > 0
+		}
+		return true
+	}
+	return false
 }
 
 // setContentType sets the appropriate Content-Type header based on file extension
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"html2go-converter/apierror"
+	"html2go-converter/jobs"
+)
+
+var (
+	// jobManager runs asynchronous conversions. Unless UseJobManager is
+	// called, an in-memory manager is started on first use.
+	jobManager *jobs.Manager
+	jobsMu     sync.Mutex
+)
+
+// UseJobManager runs asynchronous conversions on m, which must be started
+func UseJobManager(m *jobs.Manager) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	jobManager = m
+}
+
+// currentJobManager returns the job manager, starting the in-memory default
+// if needed
+func currentJobManager() *jobs.Manager {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	if jobManager == nil {
+		m := jobs.NewManager(jobs.NewMemoryStore(), jobs.DefaultOptions)
+		if err := m.Start(context.Background()); err != nil {
+			log.Printf("Failed to start job manager: %v", err)
+		}
+		jobManager = m
+	}
+	return jobManager
+}
+
+// JobResponse describes a job without its input and result
+type JobResponse struct {
+	ID        string        `json:"id"`
+	Status    jobs.Status   `json:"status"`
+	Progress  jobs.Progress `json:"progress"`
+	Error     string        `json:"error,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+	ExpiresAt time.Time     `json:"expiresAt"`
+	StatusURL string        `json:"statusUrl"`
+	ResultURL string        `json:"resultUrl"`
+}
+
+func newJobResponse(job jobs.Job) JobResponse {
+	return JobResponse{
+		ID:        job.ID,
+		Status:    job.Status,
+		Progress:  job.Progress,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+		ExpiresAt: job.ExpiresAt,
+		StatusURL: "/api/jobs/" + job.ID,
+		ResultURL: "/api/jobs/" + job.ID + "/result",
+	}
+}
+
+// JobsHandler serves the asynchronous job API:
+//
+//	POST   /api/jobs             queue a conversion (a batch when "items" is set)
+//	GET    /api/jobs/{id}        poll status and progress
+//	GET    /api/jobs/{id}/result fetch the result of a finished job
+//	DELETE /api/jobs/{id}        cancel a queued or running job
+func JobsHandler(w http.ResponseWriter, r *http.Request) {
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/jobs"), "/")
+	id, action, _ := strings.Cut(rest, "/")
+
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		submitJob(w, r)
+	case id != "" && action == "" && r.Method == http.MethodGet:
+		getJob(w, r, id)
+	case id != "" && action == "" && r.Method == http.MethodDelete:
+		cancelJob(w, r, id)
+	case id != "" && action == "result" && r.Method == http.MethodGet:
+		getJobResult(w, r, id)
+	case id == "":
+		allowMethod(w, r, http.MethodPost)
+	case action == "":
+		allowMethod(w, r, http.MethodGet, http.MethodDelete)
+	case action == "result":
+		allowMethod(w, r, http.MethodGet)
+	default:
+		apierror.Write(w, r, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "Not found"))
+	}
+}
+
+func submitJob(w http.ResponseWriter, r *http.Request) {
+	// Jobs are meant for documents too large for a request, so their body
+	// has a limit of its own
+	if max := conversionLimits().JobMaxBodyBytes; max > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, max)
+	}
+	var input jobs.Input
+	if err := decodeJSON(r, &input); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	job, err := currentJobManager().Submit(input)
+	if err != nil {
+		if errors.Is(err, jobs.ErrQueueFull) {
+			w.Header().Set("Retry-After", "30")
+		}
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/jobs/"+job.ID)
+	sendJSON(w, newJobResponse(job), http.StatusAccepted)
+}
+
+func getJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, err := currentJobManager().Get(id)
+	if err != nil {
+		sendJobError(w, r, err)
+		return
+	}
+	sendJSON(w, newJobResponse(job), http.StatusOK)
+}
+
+func cancelJob(w http.ResponseWriter, r *http.Request, id string) {
+	job, err := currentJobManager().Cancel(id)
+	if err != nil {
+		sendJobError(w, r, err)
+		return
+	}
+	sendJSON(w, newJobResponse(job), http.StatusOK)
+}
+
+func getJobResult(w http.ResponseWriter, r *http.Request, id string) {
+	job, err := currentJobManager().Get(id)
+	if err != nil {
+		sendJobError(w, r, err)
+		return
+	}
+
+	switch job.Status {
+	case jobs.StatusSucceeded:
+		if job.BatchResult != nil {
+			sendJSON(w, job.BatchResult, http.StatusOK)
+		} else {
+			sendJSON(w, job.Result, http.StatusOK)
+		}
+	case jobs.StatusFailed:
+		if job.ErrorCode == "" {
+			apierror.Write(w, r, apierror.New(http.StatusInternalServerError, apierror.CodeConversionFailed, job.Error))
+			break
+		}
+		apierror.Write(w, r, &apierror.Error{
+			Status:  job.ErrorStatus,
+			Code:    apierror.Code(job.ErrorCode),
+			Message: job.Error,
+			Field:   job.ErrorField,
+			Details: job.ErrorDetails,
+		})
+	case jobs.StatusCancelled:
+		apierror.Write(w, r, apierror.New(http.StatusConflict, apierror.CodeJobCancelled, "Job was cancelled"))
+	default:
+		w.Header().Set("Retry-After", "1")
+		e := apierror.New(http.StatusConflict, apierror.CodeJobNotFinished, "Job has not finished yet")
+		apierror.Write(w, r, e.WithDetails(map[string]any{"status": job.Status, "progress": job.Progress}))
+	}
+}
+
+// sendJobError maps a job manager error to an HTTP error response
+func sendJobError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, jobs.ErrNotFound) || errors.Is(err, jobs.ErrJobFinished) {
+		apierror.Write(w, r, err)
+		return
+	}
+	apierror.Write(w, r, &apierror.Error{
+		Status:  http.StatusInternalServerError,
+		Code:    apierror.CodeInternal,
+		Message: "Job store error",
+		Err:     err,
+	})
+}
+
+func sendJSON(w http.ResponseWriter, v any, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "Error encoding response", http.StatusInternalServerError)
+	}
+}
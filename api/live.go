@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+	"html2go-converter/live"
+)
+
+// LiveHandler serves the live-conversion WebSocket. Clients send edits as
+// live.ClientMessage and receive live.ServerMessage results for the latest
+// edit. Serverless hosts cannot keep WebSockets open, so the route only
+// exists on the local server; clients fall back to /api/convert.
+func LiveHandler(w http.ResponseWriter, r *http.Request) {
+	// The websocket package hijacks the connection before validating the
+	// handshake, so plain HTTP requests must be turned away here
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		w.Header().Set("Upgrade", "websocket")
+		apierror.Write(w, r, apierror.New(http.StatusUpgradeRequired, apierror.CodeUpgradeRequired, "WebSocket upgrade required"))
+		return
+	}
+	liveServer.ServeHTTP(w, r)
+}
+
+var liveServer = websocket.Server{
+	Handshake: checkLiveOrigin,
+	Handler:   serveLive,
+}
+
+// checkLiveOrigin rejects cross-site handshakes. Browsers always send Origin,
+// so its absence means a non-browser client, which is allowed.
+func checkLiveOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return errors.New("cross-origin WebSocket handshake rejected")
+	}
+	config.Origin = u
+	return nil
+}
+
+func serveLive(ws *websocket.Conn) {
+	defer ws.Close()
+	// Messages carry a document, so they share the request body limit
+	ws.MaxPayloadBytes = int(conversionLimits().MaxBodyBytes)
+	// Lift the server timeouts, which would otherwise end the session
+	ws.SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+
+	catalogMu.Lock()
+	prefixes := appConfig.Defaults
+	catalogMu.Unlock()
+	defaults := converter.Request{
+		PackagePrefix:  prefixes.PackagePrefix,
+		VuetifyPrefix:  prefixes.VuetifyPrefix,
+		VuetifyXPrefix: prefixes.VuetifyXPrefix,
+	}
+	session := live.NewSession(defaults, live.DefaultDebounce, func(msg live.ServerMessage) error {
+		return websocket.JSON.Send(ws, msg)
+	})
+	go func() {
+		if err := session.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Live session ended: %v", err)
+		}
+		cancel()
+		ws.Close()
+	}()
+
+	for {
+		var msg live.ClientMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				log.Printf("Live session read error: %v", err)
+			}
+			return
+		}
+		session.Edit(msg)
+	}
+}
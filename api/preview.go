@@ -0,0 +1,187 @@
+package api
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"html2go-converter/apierror"
+	"html2go-converter/config"
+	"html2go-converter/converter"
+	"html2go-converter/preview"
+)
+
+var (
+	// previewStore keeps rendered previews and previewAssets holds the
+	// vendored stylesheets. Unless UsePreviews is called, they are set up
+	// from the environment on first use. Serverless functions keep previews
+	// in memory, so on Vercel a preview is only found by the instance that
+	// rendered it.
+	previewStore  *preview.Store
+	previewAssets fs.FS
+	previewMu     sync.Mutex
+)
+
+// UsePreviews keeps previews in s and serves their stylesheets from assets
+func UsePreviews(s *preview.Store, assets fs.FS) {
+	previewMu.Lock()
+	defer previewMu.Unlock()
+	previewStore = s
+	previewAssets = assets
+}
+
+// currentPreviews returns the preview store and stylesheets, setting them up
+// from the environment if needed
+func currentPreviews() (*preview.Store, fs.FS) {
+	previewMu.Lock()
+	defer previewMu.Unlock()
+	if previewStore == nil {
+		ttl, maxEntries, cssDir := config.Preview()
+		previewStore = preview.NewStore(int(maxEntries), ttl)
+		previewAssets = preview.Vendored()
+		if cssDir != "" {
+			previewAssets = os.DirFS(cssDir)
+		}
+	}
+	return previewStore, previewAssets
+}
+
+// PreviewRequest is the body of POST /api/preview
+type PreviewRequest struct {
+	HTML string `json:"html"`
+	// Stylesheets lists the vendored stylesheets to include, out of those
+	// returned by GET /api/preview
+	Stylesheets []string `json:"stylesheets,omitempty"`
+}
+
+// PreviewResponse describes a rendered preview. Removed lists the elements,
+// as "<name>", and attributes stripped from the HTML.
+type PreviewResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Removed   []string  `json:"removed"`
+}
+
+// PreviewStylesheets lists the stylesheets previews can include
+type PreviewStylesheets struct {
+	Stylesheets []string `json:"stylesheets"`
+}
+
+// PreviewHandler serves the sandboxed live preview:
+//
+//	GET  /api/preview                  list the available stylesheets
+//	POST /api/preview                  sanitize and store HTML as a preview
+//	GET  /preview/{token}              the preview document
+//	GET  /preview/assets/{name}.css    a vendored stylesheet
+//
+// Preview documents replace the policy of the application with
+// preview.ContentSecurityPolicy, so the markup runs in an opaque origin
+// whether it is framed by the web UI or opened directly.
+func PreviewHandler(w http.ResponseWriter, r *http.Request) {
+	if name, ok := strings.CutPrefix(r.URL.Path, preview.AssetPath); ok {
+		if allowMethod(w, r, http.MethodGet, http.MethodHead) {
+			servePreviewStylesheet(w, r, name)
+		}
+		return
+	}
+	if token, ok := strings.CutPrefix(r.URL.Path, "/preview/"); ok {
+		if allowMethod(w, r, http.MethodGet, http.MethodHead) {
+			servePreview(w, r, strings.Trim(token, "/"))
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		_, assets := currentPreviews()
+		sendJSON(w, PreviewStylesheets{Stylesheets: preview.Available(assets)}, http.StatusOK)
+	case http.MethodPost:
+		createPreview(w, r)
+	default:
+		allowMethod(w, r, http.MethodGet, http.MethodPost)
+	}
+}
+
+func createPreview(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req PreviewRequest
+	if err := decodeJSON(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if strings.TrimSpace(req.HTML) == "" {
+		apierror.Write(w, r, converter.ErrHTMLRequired)
+		return
+	}
+
+	store, assets := currentPreviews()
+	document, removed, err := preview.Render(assets, req.HTML, req.Stylesheets)
+	if errors.Is(err, preview.ErrUnknownStylesheet) {
+		apierror.Write(w, r, apierror.From(err).WithDetails(map[string]any{"available": preview.Available(assets)}))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	p := store.Put(document, time.Now())
+	resp := PreviewResponse{Token: p.Token, URL: "/preview/" + p.Token, ExpiresAt: p.ExpiresAt, Removed: removed}
+	w.Header().Set("Location", resp.URL)
+	sendJSON(w, resp, http.StatusCreated)
+}
+
+func servePreview(w http.ResponseWriter, r *http.Request, token string) {
+	store, _ := currentPreviews()
+	if !preview.ValidToken(token) {
+		apierror.Write(w, r, preview.ErrNotFound)
+		return
+	}
+	p, err := store.Get(token, time.Now())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Security-Policy", preview.ContentSecurityPolicy)
+	h.Del("Content-Security-Policy-Report-Only")
+	// The web UI frames previews; frame-ancestors keeps other sites out
+	h.Set("X-Frame-Options", "SAMEORIGIN")
+	h.Set("Referrer-Policy", "no-referrer")
+	h.Set("Cache-Control", "private, no-store")
+	h.Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write([]byte(p.Document))
+	}
+}
+
+func servePreviewStylesheet(w http.ResponseWriter, r *http.Request, file string) {
+	_, assets := currentPreviews()
+	name, ok := strings.CutSuffix(file, ".css")
+	if !ok {
+		apierror.Write(w, r, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "Not found"))
+		return
+	}
+	data, err := preview.Stylesheet(assets, name)
+	if err != nil {
+		apierror.Write(w, r, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "Not found"))
+		return
+	}
+
+	// Sandboxed previews have an opaque origin, so their stylesheet requests
+	// are cross-origin
+	w.Header().Set("Cross-Origin-Resource-Policy", "cross-origin")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(data)
+	}
+}
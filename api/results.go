@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+)
+
+// ResultsHandler serves GET /api/results/{hash}: a cached conversion result,
+// addressed by the hash returned with it, so it can be fetched again without
+// resending the HTML. Results are immutable; a result evicted from the cache
+// or produced by another converter version answers 404. Serverless
+// functions do not share their cache, so on Vercel a result is only found
+// by the instance that converted it.
+func ResultsHandler(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	setupConversion()
+
+	hash := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/"), "/api/results/")
+	response, ok := converter.CachedResult(hash)
+	if !ok {
+		apierror.Write(w, r, apierror.New(http.StatusNotFound, apierror.CodeResultNotFound, "Result is not cached; convert the HTML again"))
+		return
+	}
+
+	etag := conversionETag(response.Hash, negotiateCode(r))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if match := r.Header.Get("If-None-Match"); match != "" && (match == etag || match == "*") {
+		w.Header().Set("ETag", etag)
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	sendConversion(w, r, response, converter.CacheHit)
+}
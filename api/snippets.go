@@ -0,0 +1,261 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"html2go-converter/apierror"
+	"html2go-converter/config"
+	"html2go-converter/converter"
+	"html2go-converter/middleware"
+	"html2go-converter/snippets"
+)
+
+var (
+	// snippetStore keeps shared snippets. Unless UseSnippetStore is called,
+	// the store configured by the environment is opened on first use.
+	snippetStore  snippets.Store
+	snippetPolicy = snippets.DefaultPolicy
+	snippetsMu    sync.Mutex
+)
+
+// UseSnippetStore keeps shared snippets in s, expiring them by p
+func UseSnippetStore(s snippets.Store, p snippets.Policy) {
+	snippetsMu.Lock()
+	defer snippetsMu.Unlock()
+	snippetStore = s
+	snippetPolicy = p
+}
+
+// currentSnippetStore returns the snippet store and its policy, opening the
+// store configured by SNIPPETS_DB if needed. Serverless functions fall back
+// to memory, so on Vercel a snippet is only found by the instance that
+// stored it.
+func currentSnippetStore() (snippets.Store, snippets.Policy) {
+	snippetsMu.Lock()
+	defer snippetsMu.Unlock()
+	if snippetStore == nil {
+		path, policy := config.Snippets()
+		snippetPolicy = policy
+		snippetStore = snippets.NewMemoryStore()
+		if path != "" {
+			if s, err := snippets.OpenBoltStore(path); err == nil {
+				snippetStore = s
+			} else {
+				log.Printf("Keeping snippets in memory: %v", err)
+			}
+		}
+	}
+	return snippetStore, snippetPolicy
+}
+
+// SnippetRequest is the body of POST /api/snippets
+type SnippetRequest struct {
+	converter.Request
+	// ExpiresIn is one of snippets.Expiries; empty uses the default expiry
+	ExpiresIn string `json:"expiresIn,omitempty"`
+}
+
+// SnippetResponse describes a shared snippet. DeleteToken is only sent to
+// the creator.
+type SnippetResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	converter.Request
+	Code        string     `json:"code"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	DeleteToken string     `json:"deleteToken,omitempty"`
+}
+
+func newSnippetResponse(s snippets.Snippet) SnippetResponse {
+	return SnippetResponse{
+		ID:        s.ID,
+		URL:       "/s/" + s.ID,
+		Request:   s.Request,
+		Code:      s.Code,
+		CreatedAt: s.CreatedAt,
+		ExpiresAt: s.ExpiresAt,
+	}
+}
+
+// SnippetsHandler serves shareable permalinks:
+//
+//	POST   /api/snippets      convert and store a snippet
+//	GET    /api/snippets/{id} fetch a snippet
+//	DELETE /api/snippets/{id} delete a snippet, given its deletion token in
+//	                          the X-Delete-Token header or the token parameter
+//	GET    /s/{id}            the editor pre-populated with a snippet
+func SnippetsHandler(w http.ResponseWriter, r *http.Request) {
+	if id, ok := strings.CutPrefix(r.URL.Path, "/s/"); ok {
+		if allowMethod(w, r, http.MethodGet, http.MethodHead) {
+			serveSnippetPage(w, r, strings.Trim(id, "/"))
+		}
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/snippets"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodPost:
+		createSnippet(w, r)
+	case id != "" && r.Method == http.MethodGet:
+		getSnippet(w, r, id)
+	case id != "" && r.Method == http.MethodDelete:
+		deleteSnippet(w, r, id)
+	case id == "":
+		allowMethod(w, r, http.MethodPost)
+	default:
+		allowMethod(w, r, http.MethodGet, http.MethodDelete)
+	}
+}
+
+func createSnippet(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req SnippetRequest
+	if err := decodeJSON(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	store, policy := currentSnippetStore()
+	ttl, err := policy.TTL(req.ExpiresIn)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Direction == "" {
+		req.Direction = "html2go"
+	}
+	response, _, err := converter.ConvertCached(r.Context(), req.Request)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// IDs are random; retry the rare collision with another snippet
+	var snippet snippets.Snippet
+	var token string
+	for attempt := 0; attempt < 3; attempt++ {
+		snippet, token = snippets.New(req.Request, response.Code, ttl, time.Now())
+		if err = store.Create(snippet); !errors.Is(err, snippets.ErrExists) {
+			break
+		}
+	}
+	if err != nil {
+		sendSnippetError(w, r, err)
+		return
+	}
+
+	resp := newSnippetResponse(snippet)
+	resp.DeleteToken = token
+	w.Header().Set("Location", resp.URL)
+	sendJSON(w, resp, http.StatusCreated)
+}
+
+func getSnippet(w http.ResponseWriter, r *http.Request, id string) {
+	snippet, err := findSnippet(id)
+	if err != nil {
+		sendSnippetError(w, r, err)
+		return
+	}
+	sendJSON(w, newSnippetResponse(snippet), http.StatusOK)
+}
+
+func deleteSnippet(w http.ResponseWriter, r *http.Request, id string) {
+	snippet, err := findSnippet(id)
+	if err != nil {
+		sendSnippetError(w, r, err)
+		return
+	}
+	token := r.Header.Get("X-Delete-Token")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if !snippet.CheckToken(token) {
+		sendSnippetError(w, r, snippets.ErrInvalidToken)
+		return
+	}
+
+	store, _ := currentSnippetStore()
+	if err := store.Delete(id); err != nil {
+		sendSnippetError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// findSnippet returns the snippet with the given ID unless it has expired
+func findSnippet(id string) (snippets.Snippet, error) {
+	if !snippets.ValidID(id) {
+		return snippets.Snippet{}, snippets.ErrNotFound
+	}
+	store, _ := currentSnippetStore()
+	snippet, err := store.Get(id)
+	if err != nil {
+		return snippets.Snippet{}, err
+	}
+	if snippet.Expired(time.Now()) {
+		return snippets.Snippet{}, snippets.ErrNotFound
+	}
+	return snippet, nil
+}
+
+// sendSnippetError maps a snippet store error to an HTTP error response
+func sendSnippetError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, snippets.ErrNotFound) || errors.Is(err, snippets.ErrInvalidToken) {
+		apierror.Write(w, r, err)
+		return
+	}
+	apierror.Write(w, r, &apierror.Error{
+		Status:  http.StatusInternalServerError,
+		Code:    apierror.CodeInternal,
+		Message: "Snippet store error",
+		Err:     err,
+	})
+}
+
+// serveSnippetPage renders index.html with the snippet injected, so the
+// editors open pre-populated. A missing or expired snippet renders the
+// empty editor with an explanation and status 404.
+func serveSnippetPage(w http.ResponseWriter, r *http.Request, id string) {
+	data := indexData{Config: currentAppConfig()}
+	status := http.StatusOK
+	snippet, err := findSnippet(id)
+	switch {
+	case err == nil:
+		resp := newSnippetResponse(snippet)
+		data.Snippet = &resp
+	case errors.Is(err, snippets.ErrNotFound):
+		status = http.StatusNotFound
+		data.SnippetError = apierror.Titles[apierror.CodeSnippetNotFound]
+	default:
+		log.Printf("Failed to load snippet %s: %v", id, err)
+		status = http.StatusInternalServerError
+		data.SnippetError = "Snippet store error"
+	}
+
+	catalog, err := currentCatalog()
+	var page []byte
+	if err == nil {
+		page, err = executeIndex(catalog, data)
+	}
+	if err != nil {
+		log.Printf("Error: Could not render index.html: %v", err)
+		http.Error(w, "Unable to find index.html file", http.StatusInternalServerError)
+		return
+	}
+
+	if nonce := middleware.Nonce(r); nonce != "" {
+		page = injectNonce(page, nonce)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(page)
+	}
+}
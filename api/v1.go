@@ -0,0 +1,270 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"html2go-converter/apierror"
+	"html2go-converter/config"
+	"html2go-converter/converter"
+	"html2go-converter/openapi"
+)
+
+// ErrorResponse is the error body of the API
+type ErrorResponse = apierror.Response
+
+// ErrorCode describes an error code at /api/v1/errors
+type ErrorCode struct {
+	Code  apierror.Code `json:"code" doc:"Error code"`
+	Title string        `json:"title" doc:"Short description of the error"`
+}
+
+var (
+	// v1Document is the OpenAPI document of the v1 API, built on first use
+	v1Document     []byte
+	v1DocumentErr  error
+	v1DocumentOnce sync.Once
+)
+
+// V1Handler serves the versioned API under /api/v1. Its contract is
+// published at /api/v1/openapi.json; see OpenAPIDocument.
+func V1Handler(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/api/v1/convert":
+		if allowMethod(w, r, http.MethodPost) {
+			v1Convert(w, r)
+		}
+	case "/api/v1/convert/batch":
+		if allowMethod(w, r, http.MethodPost) {
+			v1Batch(w, r)
+		}
+	case "/api/v1/openapi.json":
+		if allowMethod(w, r, http.MethodGet, http.MethodHead) {
+			v1OpenAPI(w, r)
+		}
+	case "/api/v1/errors":
+		if allowMethod(w, r, http.MethodGet, http.MethodHead) {
+			sendJSON(w, errorCodes(), http.StatusOK)
+		}
+	default:
+		apierror.Write(w, r, apierror.New(http.StatusNotFound, apierror.CodeNotFound, "Not found"))
+	}
+}
+
+// allowMethod answers 405 unless r uses one of methods
+func allowMethod(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	e := apierror.New(http.StatusMethodNotAllowed, apierror.CodeMethodNotAllowed, "Method not allowed")
+	apierror.Write(w, r, e.WithDetails(map[string]any{"allow": methods}))
+	return false
+}
+
+func v1Convert(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var req converter.Request
+	if isHTMLBody(r) {
+		var err error
+		if req, err = readHTMLRequest(r); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	} else if err := decodeStrict(r.Body, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Direction == "" {
+		req.Direction = converter.DirectionHTMLToGo
+	}
+	if err := req.Validate(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	response, cacheStatus, err := converter.ConvertCached(r.Context(), req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	sendConversion(w, r, response, cacheStatus)
+}
+
+func v1Batch(w http.ResponseWriter, r *http.Request) {
+	limitBody(w, r)
+	var batch converter.BatchRequest
+	if err := decodeStrict(r.Body, &batch); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if batch.Defaults.Direction == "" {
+		batch.Defaults.Direction = converter.DirectionHTMLToGo
+	}
+	if err := batch.Validate(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	serveBatch(w, r, batch)
+}
+
+func v1OpenAPI(w http.ResponseWriter, r *http.Request) {
+	v1DocumentOnce.Do(func() {
+		v1Document, v1DocumentErr = json.MarshalIndent(OpenAPIDocument(), "", "  ")
+	})
+	if v1DocumentErr != nil {
+		apierror.Write(w, r, &apierror.Error{
+			Status:  http.StatusInternalServerError,
+			Code:    apierror.CodeInternal,
+			Message: "Error encoding OpenAPI document",
+			Err:     v1DocumentErr,
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(v1Document)
+}
+
+// errorCodes lists every error code with its title
+func errorCodes() []ErrorCode {
+	var codes []ErrorCode
+	for _, code := range apierror.Code("").EnumValues() {
+		codes = append(codes, ErrorCode{Code: apierror.Code(code), Title: apierror.Titles[apierror.Code(code)]})
+	}
+	return codes
+}
+
+// decodeStrict decodes a JSON body, rejecting unknown fields. Errors that
+// concern a single field are reported as a *converter.ValidationError.
+func decodeStrict(body io.Reader, v any) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	if tooLarge := bodyTooLarge(err); tooLarge != nil {
+		return tooLarge
+	}
+
+	var errs converter.ValidationError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return apierror.New(http.StatusBadRequest, apierror.CodeBodyRequired, "Request body is required")
+	case errors.As(err, &typeErr):
+		errs.Add(typeErr.Field, converter.FieldInvalidType, fmt.Sprintf("must not be a JSON %s", typeErr.Value))
+		return &errs
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		errs.Add(field, converter.FieldUnknown, "is not a known field")
+		return &errs
+	}
+	return apierror.New(http.StatusBadRequest, apierror.CodeInvalidJSON, "Invalid request format")
+}
+
+// OpenAPIDocument describes the v1 API. Its schemas are generated from the
+// request and response types the handlers use.
+func OpenAPIDocument() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "HTML to Go converter",
+		Version:     config.Version,
+		Description: "Converts HTML, including Vuetify and VuetifyX components, into htmlgo Go code.",
+	})
+
+	invalidResponse := errorResponse(doc, "Invalid request; fields lists every invalid field")
+	failedResponse := errorResponse(doc, "Conversion failed")
+
+	convertBody := doc.JSONBody(converter.Request{})
+	convertBody.Content["text/html"] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	convertResponse := doc.JSONResponse("Converted code", converter.Response{})
+	convertResponse.Content["text/x-go"] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	convertResponse.Content["text/plain"] = openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+	doc.Add(http.MethodPost, "/api/v1/convert", &openapi.Operation{
+		OperationID: "convert",
+		Summary:     "Convert a document",
+		Description: "The body is a JSON request, or raw HTML sent as text/html with the options in the query string. Clients that accept text/x-go or text/plain rather than JSON receive the bare generated code.",
+		Parameters:  htmlBodyParameters(doc),
+		RequestBody: convertBody,
+		Responses: map[string]openapi.Response{
+			"200": convertResponse,
+			"400": invalidResponse,
+			"413": errorResponse(doc, "The request body exceeds the size limit"),
+			"422": errorResponse(doc, "The HTML exceeds the depth or node limit"),
+			"500": failedResponse,
+			"501": errorResponse(doc, "The direction is not implemented"),
+			"503": errorResponse(doc, "The conversion exceeded its deadline or waited too long for a free slot"),
+		},
+	})
+
+	batchResponse := doc.JSONResponse("Results of every item, in item order", converter.BatchResponse{})
+	batchResponse.Content["application/x-ndjson"] = openapi.MediaType{Schema: doc.SchemaOf(converter.BatchResult{})}
+	doc.Add(http.MethodPost, "/api/v1/convert/batch", &openapi.Operation{
+		OperationID: "convertBatch",
+		Summary:     "Convert a batch of snippets",
+		Description: "Items are converted concurrently and fail individually. With stream=1 or Accept: application/x-ndjson, one result line is streamed per item as soon as it is ready.",
+		Parameters: []openapi.Parameter{{
+			Name:        "stream",
+			In:          "query",
+			Description: "Set to 1 to stream results as NDJSON",
+			Schema:      &openapi.Schema{Type: "string", Enum: []string{"1"}},
+		}},
+		RequestBody: doc.JSONBody(converter.BatchRequest{}),
+		Responses: map[string]openapi.Response{
+			"200": batchResponse,
+			"400": invalidResponse,
+			"413": errorResponse(doc, "The request body exceeds the size limit"),
+		},
+	})
+
+	doc.Add(http.MethodGet, "/api/v1/errors", &openapi.Operation{
+		OperationID: "errors",
+		Summary:     "List the error codes",
+		Description: "Every error response carries one of these codes. Send Accept: application/problem+json to receive errors as RFC 7807 problem documents.",
+		Responses: map[string]openapi.Response{
+			"200": doc.JSONResponse("Error codes", []ErrorCode{}),
+		},
+	})
+
+	doc.Add(http.MethodGet, "/api/v1/openapi.json", &openapi.Operation{
+		OperationID: "openapi",
+		Summary:     "This document",
+		Responses: map[string]openapi.Response{
+			"200": {Description: "OpenAPI document", Content: map[string]openapi.MediaType{
+				"application/json": {Schema: &openapi.Schema{Type: "object"}},
+			}},
+		},
+	})
+	return doc
+}
+
+// errorResponse describes an error response, in both of its formats
+func errorResponse(doc *openapi.Document, description string) openapi.Response {
+	response := doc.JSONResponse(description, ErrorResponse{})
+	response.Content["application/problem+json"] = openapi.MediaType{Schema: doc.SchemaOf(apierror.Problem{})}
+	return response
+}
+
+// htmlBodyParameters are the query options of a text/html request body
+func htmlBodyParameters(doc *openapi.Document) []openapi.Parameter {
+	option := func(name, description string, v any) openapi.Parameter {
+		return openapi.Parameter{Name: name, In: "query", Description: description + "; only with a text/html body", Schema: doc.SchemaOf(v)}
+	}
+	return []openapi.Parameter{
+		option("packagePrefix", "Qualifier of htmlgo identifiers; empty for a dot import", ""),
+		option("vuetifyPrefix", "Qualifier of Vuetify components; empty for a dot import", ""),
+		option("vuetifyXPrefix", "Qualifier of VuetifyX components; empty for a dot import", ""),
+		option("childrenMode", "Generate Children(...) calls instead of nesting elements as arguments", false),
+		option("verify", "Render the generated code back to HTML and report how it differs from the input", false),
+		option("direction", "Conversion direction, html2go by default", converter.DirectionHTMLToGo),
+	}
+}
@@ -0,0 +1,278 @@
+// Package apierror defines the error responses of the API. Every error has
+// a stable machine-readable code, the ID of the request it answers and, for
+// invalid requests, the path of each invalid field. Clients that accept
+// application/problem+json receive an RFC 7807 problem document instead of
+// the default JSON body.
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+
+	"html2go-converter/errcode"
+	"html2go-converter/middleware"
+)
+
+// Code identifies a kind of error. Codes never change once published;
+// clients match on them instead of on messages.
+type Code string
+
+// Error codes
+const (
+	CodeInvalidJSON      Code = "invalid_json"
+	CodeInvalidMultipart Code = "invalid_multipart"
+	CodeBodyRequired     Code = "body_required"
+	CodeValidationFailed Code = "validation_failed"
+	CodeHTMLRequired     Code = "html_required"
+	CodeInvalidDirection Code = "invalid_direction"
+	CodeNotImplemented   Code = "not_implemented"
+	CodeConversionFailed Code = "conversion_failed"
+	CodeBatchEmpty       Code = "batch_empty"
+	CodeBatchTooLarge    Code = "batch_too_large"
+	CodeArchiveRequired  Code = "archive_required"
+	CodeArchiveUnknown   Code = "archive_unsupported"
+	CodeArchiveTooLarge  Code = "archive_too_large"
+	CodeArchiveEmpty     Code = "archive_empty"
+	CodeInvalidPackage   Code = "invalid_package"
+	CodeJobNotFound      Code = "job_not_found"
+	CodeJobFinished      Code = "job_finished"
+	CodeJobNotFinished   Code = "job_not_finished"
+	CodeJobCancelled     Code = "job_cancelled"
+	CodeQueueFull        Code = "queue_full"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeNotFound         Code = "not_found"
+	CodeUpgradeRequired  Code = "upgrade_required"
+	CodeBodyTooLarge     Code = "body_too_large"
+	CodeTooDeep          Code = "html_too_deep"
+	CodeTooManyNodes     Code = "html_too_many_nodes"
+	CodeTimeout          Code = "conversion_timeout"
+	CodeServerBusy       Code = "server_busy"
+	CodeResultNotFound   Code = "result_not_found"
+	CodeConverterCrashed Code = "converter_crashed"
+	CodeSnippetNotFound  Code = "snippet_not_found"
+	CodeSnippetForbidden Code = "snippet_forbidden"
+	CodeInvalidExpiry    Code = "invalid_expiry"
+	CodeHistoryNotFound  Code = "history_not_found"
+	CodeRevisionNotFound Code = "revision_not_found"
+	CodeHistoryFull      Code = "history_full"
+	CodeInvalidRevision  Code = "invalid_revision"
+	CodeExampleNotFound  Code = "example_not_found"
+	CodePreviewNotFound  Code = "preview_not_found"
+	CodeBadStylesheet    Code = "stylesheet_unavailable"
+	CodeInternal         Code = "internal_error"
+)
+
+// Titles are the short, fixed descriptions of the codes, used as the title
+// of problem documents
+var Titles = map[Code]string{
+	CodeInvalidJSON:      "Request body is not valid JSON",
+	CodeInvalidMultipart: "Request body is not a valid multipart form",
+	CodeBodyRequired:     "Request body is required",
+	CodeValidationFailed: "Request has invalid fields",
+	CodeHTMLRequired:     "HTML content is required",
+	CodeInvalidDirection: "Invalid conversion direction",
+	CodeNotImplemented:   "Conversion direction is not implemented",
+	CodeConversionFailed: "Conversion failed",
+	CodeBatchEmpty:       "Batch is empty",
+	CodeBatchTooLarge:    "Batch is too large",
+	CodeArchiveRequired:  "Archive file is required",
+	CodeArchiveUnknown:   "Archive format is not supported",
+	CodeArchiveTooLarge:  "Archive is too large",
+	CodeArchiveEmpty:     "Archive contains no HTML files",
+	CodeInvalidPackage:   "Invalid Go package name",
+	CodeJobNotFound:      "Job not found",
+	CodeJobFinished:      "Job has already finished",
+	CodeJobNotFinished:   "Job has not finished yet",
+	CodeJobCancelled:     "Job was cancelled",
+	CodeQueueFull:        "Job queue is full",
+	CodeMethodNotAllowed: "Method not allowed",
+	CodeNotFound:         "Not found",
+	CodeUpgradeRequired:  "WebSocket upgrade required",
+	CodeBodyTooLarge:     "Request body is too large",
+	CodeTooDeep:          "HTML is nested too deeply",
+	CodeTooManyNodes:     "HTML has too many nodes",
+	CodeTimeout:          "Conversion took too long",
+	CodeServerBusy:       "Too many conversions are running",
+	CodeResultNotFound:   "Result is not cached",
+	CodeConverterCrashed: "The converter crashed on this input",
+	CodeSnippetNotFound:  "Snippet not found or expired",
+	CodeSnippetForbidden: "Deletion token does not match",
+	CodeInvalidExpiry:    "Invalid snippet expiry",
+	CodeHistoryNotFound:  "History not found",
+	CodeRevisionNotFound: "Revision not found",
+	CodeHistoryFull:      "History has too many revisions",
+	CodeInvalidRevision:  "Invalid revision number",
+	CodeExampleNotFound:  "Example not found",
+	CodePreviewNotFound:  "Preview not found or expired",
+	CodeBadStylesheet:    "Stylesheet is not available for previews",
+	CodeInternal:         "Internal server error",
+}
+
+// EnumValues lists every code, for the OpenAPI document
+func (Code) EnumValues() []string {
+	codes := make([]string, 0, len(Titles))
+	for code := range Titles {
+		codes = append(codes, string(code))
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// TypeBase prefixes the code in the type URI of problem documents. The
+// codes are listed at /api/v1/errors.
+const TypeBase = "/api/v1/errors#"
+
+// Error is an API error.
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	// Field is the JSON path of the invalid field, for errors about one field
+	Field string
+	// Fields lists every invalid field of a request that failed validation
+	Fields []errcode.FieldError
+	// Details holds code-specific data, such as the limit that was exceeded
+	Details map[string]any
+	// Err is the underlying error, logged for internal errors
+	Err error
+}
+
+// New returns an error with the given status and code
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails sets the details of e and returns e
+func (e *Error) WithDetails(details map[string]any) *Error {
+	e.Details = details
+	return e
+}
+
+// From converts err into an API error. Errors of the domain packages carry
+// their status and code through the interfaces of package errcode; any other
+// error is a conversion failure.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var coded errcode.Coded
+	if !errors.As(err, &coded) {
+		return &Error{Status: http.StatusInternalServerError, Code: CodeConversionFailed, Message: err.Error(), Err: err}
+	}
+	e := &Error{Status: coded.Status(), Code: Code(coded.Code()), Message: err.Error(), Err: err}
+	var fielded errcode.Fielded
+	if errors.As(err, &fielded) {
+		e.Field = fielded.Field()
+	}
+	var invalid errcode.Invalid
+	if errors.As(err, &invalid) {
+		e.Fields = invalid.FieldErrors()
+	}
+	var detailed errcode.Detailed
+	if errors.As(err, &detailed) {
+		e.Details = detailed.Details()
+	}
+	return e
+}
+
+// Response is the default JSON error body. Error keeps the message the API
+// has always returned.
+type Response struct {
+	Error     string               `json:"error" doc:"Human-readable error message"`
+	Code      Code                 `json:"code" doc:"Stable machine-readable error code; see /api/v1/errors"`
+	Field     string               `json:"field,omitempty" doc:"JSON path of the invalid field"`
+	Fields    []errcode.FieldError `json:"fields,omitempty" doc:"Every invalid field of the request"`
+	RequestID string               `json:"requestId,omitempty" doc:"ID of the request, also sent in the X-Request-ID header"`
+	Details   map[string]any       `json:"details,omitempty" doc:"Code-specific data, such as the limit that was exceeded"`
+}
+
+// Problem is the RFC 7807 problem document of an error, with the fields of
+// Response as extension members.
+type Problem struct {
+	Type      string               `json:"type" doc:"URI identifying the error code"`
+	Title     string               `json:"title" doc:"Short description of the error code"`
+	Status    int                  `json:"status" doc:"HTTP status code"`
+	Detail    string               `json:"detail,omitempty" doc:"Human-readable explanation of this occurrence"`
+	Instance  string               `json:"instance,omitempty" doc:"Path of the request"`
+	Code      Code                 `json:"code" doc:"Stable machine-readable error code"`
+	Field     string               `json:"field,omitempty" doc:"JSON path of the invalid field"`
+	Fields    []errcode.FieldError `json:"fields,omitempty" doc:"Every invalid field of the request"`
+	RequestID string               `json:"requestId,omitempty" doc:"ID of the request"`
+	Details   map[string]any       `json:"details,omitempty" doc:"Code-specific data"`
+}
+
+// Write sends err as the response to r, as a problem document if the client
+// accepts application/problem+json and as a Response otherwise
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	e := From(err)
+
+	requestID := middleware.RequestID(r)
+	if requestID == "" {
+		requestID = middleware.NewRequestID()
+	}
+	w.Header().Set(middleware.RequestIDHeader, requestID)
+	if e.Code == CodeServerBusy && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", "1")
+	}
+	if e.Status == http.StatusInternalServerError && e.Err != nil {
+		log.Printf("Request %s failed: %v", requestID, e.Err)
+	}
+
+	var body any
+	contentType := "application/json"
+	if WantsProblem(r) {
+		contentType = "application/problem+json"
+		body = Problem{
+			Type:      TypeBase + string(e.Code),
+			Title:     Titles[e.Code],
+			Status:    e.Status,
+			Detail:    e.Message,
+			Instance:  r.URL.Path,
+			Code:      e.Code,
+			Field:     e.Field,
+			Fields:    e.Fields,
+			RequestID: requestID,
+			Details:   e.Details,
+		}
+	} else {
+		body = Response{
+			Error:     e.Message,
+			Code:      e.Code,
+			Field:     e.Field,
+			Fields:    e.Fields,
+			RequestID: requestID,
+			Details:   e.Details,
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(e.Status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Error encoding error response: %v", err)
+	}
+}
+
+// WantsProblem reports whether the client asked for problem documents
+func WantsProblem(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, _ := mime.ParseMediaType(accept); mediaType == "application/problem+json" {
+			return true
+		}
+	}
+	return false
+}
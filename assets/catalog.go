@@ -0,0 +1,272 @@
+package assets
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// fingerprintLength is the number of hex digits of the content hash used in
+// fingerprinted file names.
+const fingerprintLength = 10
+
+// File is a static asset prepared for serving: content, validators and
+// precompressed variants.
+type File struct {
+	// Name is the path of the file in its source, e.g. "script.js".
+	Name string
+	// Layer is the source layer the file was read from.
+	Layer string
+	// Content is the file content, with asset references rewritten for HTML.
+	Content []byte
+	// ETag is the strong entity tag of Content, including quotes.
+	ETag string
+	// Fingerprinted is the content-addressed name, e.g. "script.1a2b3c4d5e.js".
+	// It is empty for HTML documents, which must always be revalidated.
+	Fingerprinted string
+	// Gzip and Brotli hold compressed variants of Content, or nil when
+	// compression does not make the file smaller.
+	Gzip   []byte
+	Brotli []byte
+
+	// parse guards tmpl and tmplErr, the content parsed by Execute
+	parse   sync.Once
+	tmpl    *template.Template
+	tmplErr error
+}
+
+// Catalog is an in-memory snapshot of a Source with fingerprinted names and
+// precompressed variants, built once at startup. The snapshot of a Mutable
+// source reports when it is Stale, so that it can be rebuilt.
+type Catalog struct {
+	files         map[string]*File
+	fingerprinted map[string]*File
+
+	// src and stamp are set for Mutable sources only
+	src   Source
+	stamp string
+}
+
+// NewCatalog reads every file of src into memory. Non-HTML files get a
+// fingerprinted name and references to them in HTML documents are rewritten
+// to it. Files named "x.gz" or "x.br" are used as build-time compressed
+// variants of "x" if they decompress to its content; other files are
+// compressed at startup.
+func NewCatalog(src Source) (*Catalog, error) {
+	c := &Catalog{
+		files:         make(map[string]*File),
+		fingerprinted: make(map[string]*File),
+	}
+	if Mutable(src) {
+		stamp, err := Stamp(src)
+		if err != nil {
+			return nil, err
+		}
+		c.src, c.stamp = src, stamp
+	}
+
+	var names []string
+	prebuilt := make(map[string][]byte)
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch path.Ext(name) {
+		case ".go":
+			// Go sources living next to the assets are never served
+			return nil
+		case ".gz", ".br":
+			// Compressed variants are attached to their original below
+			content, err := fs.ReadFile(src, name)
+			if err != nil {
+				return err
+			}
+			prebuilt[name] = content
+			return nil
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var documents []*File
+	for _, name := range names {
+		content, layer, err := ReadFile(src, name)
+		if err != nil {
+			return nil, err
+		}
+		f := &File{Name: name, Layer: layer, Content: content}
+		c.files[name] = f
+		if isDocument(name) {
+			documents = append(documents, f)
+			continue
+		}
+		f.Fingerprinted = fingerprint(name, content)
+		c.fingerprinted[f.Fingerprinted] = f
+	}
+
+	// Point documents at the fingerprinted names before hashing them
+	replacer := c.referenceReplacer()
+	for _, f := range documents {
+		f.Content = []byte(replacer.Replace(string(f.Content)))
+	}
+
+	for _, f := range c.files {
+		// Rewritten documents no longer match a build-time variant
+		if !isDocument(f.Name) {
+			f.Gzip = matching(prebuilt[f.Name+".gz"], f.Content, gunzip)
+			f.Brotli = matching(prebuilt[f.Name+".br"], f.Content, unbrotli)
+		}
+		f.prepare()
+	}
+
+	return c, nil
+}
+
+// Stale reports whether the files of a Mutable source changed since the
+// catalog was built. It is always false for other sources.
+func (c *Catalog) Stale() bool {
+	if c.src == nil {
+		return false
+	}
+	stamp, err := Stamp(c.src)
+	return err != nil || stamp != c.stamp
+}
+
+// matching returns the build-time variant compressed if it decompresses to
+// content, and nil if it is missing or out of date
+func matching(compressed, content []byte, decompress func([]byte) ([]byte, error)) []byte {
+	if compressed == nil {
+		return nil
+	}
+	original, err := decompress(compressed)
+	if err != nil || !bytes.Equal(original, content) {
+		return nil
+	}
+	return compressed
+}
+
+func gunzip(compressed []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(zr)
+}
+
+func unbrotli(compressed []byte) ([]byte, error) {
+	return io.ReadAll(brotli.NewReader(bytes.NewReader(compressed)))
+}
+
+// NewFile prepares content generated at runtime, such as a rendered page,
+// for serving: it computes the ETag and the compressed variants.
+func NewFile(name, layer string, content []byte) *File {
+	f := &File{Name: name, Layer: layer, Content: content}
+	f.prepare()
+	return f
+}
+
+// prepare computes the ETag and any compressed variants not already set.
+func (f *File) prepare() {
+	f.ETag = `"` + hash(f.Content)[:2*fingerprintLength] + `"`
+	if f.Gzip == nil {
+		f.Gzip = compressGzip(f.Content)
+	}
+	if f.Brotli == nil {
+		f.Brotli = compressBrotli(f.Content)
+	}
+}
+
+// Lookup returns the file for name, which is either an original or a
+// fingerprinted name. immutable reports whether name was fingerprinted, in
+// which case its content can never change.
+func (c *Catalog) Lookup(name string) (file *File, immutable bool, ok bool) {
+	if f, found := c.fingerprinted[name]; found {
+		return f, true, true
+	}
+	f, found := c.files[name]
+	return f, false, found
+}
+
+// referenceReplacer rewrites quoted root-relative references such as
+// "/script.js" or "/static/script.js" to their fingerprinted names.
+func (c *Catalog) referenceReplacer() *strings.Replacer {
+	var pairs []string
+	for name, f := range c.files {
+		if f.Fingerprinted == "" {
+			continue
+		}
+		for _, prefix := range []string{"/", "/static/"} {
+			for _, quote := range []string{`"`, `'`} {
+				pairs = append(pairs,
+					quote+prefix+name+quote,
+					quote+prefix+f.Fingerprinted+quote)
+			}
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// isDocument reports whether name is an HTML document.
+func isDocument(name string) bool {
+	return strings.EqualFold(path.Ext(name), ".html")
+}
+
+// fingerprint inserts a content hash before the extension of name.
+func fingerprint(name string, content []byte) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash(content)[:fingerprintLength] + ext
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// compressGzip returns the gzip encoding of content, or nil when it would
+// not be smaller.
+func compressGzip(content []byte) []byte {
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if _, err := zw.Write(content); err != nil {
+		return nil
+	}
+	if err := zw.Close(); err != nil {
+		return nil
+	}
+	return smaller(buf.Bytes(), content)
+}
+
+// compressBrotli returns the brotli encoding of content, or nil when it would
+// not be smaller.
+func compressBrotli(content []byte) []byte {
+	var buf bytes.Buffer
+	bw := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	if _, err := bw.Write(content); err != nil {
+		return nil
+	}
+	if err := bw.Close(); err != nil {
+		return nil
+	}
+	return smaller(buf.Bytes(), content)
+}
+
+func smaller(compressed, original []byte) []byte {
+	if len(compressed) >= len(original) {
+		return nil
+	}
+	return compressed
+}
@@ -0,0 +1,34 @@
+package assets
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// Render executes the content of f as an html/template with data and returns
+// the result as a new File from the same layer.
+func Render(f *File, data any) (*File, error) {
+	content, err := Execute(f, data)
+	if err != nil {
+		return nil, err
+	}
+	return NewFile(f.Name, f.Layer, content), nil
+}
+
+// Execute executes the content of f as an html/template with data. The
+// template is parsed on first use and kept with f. Unlike Render, the result
+// is not prepared for serving, which suits pages rendered on every request.
+func Execute(f *File, data any) ([]byte, error) {
+	f.parse.Do(func() {
+		f.tmpl, f.tmplErr = template.New(f.Name).Parse(string(f.Content))
+	})
+	if f.tmplErr != nil {
+		return nil, fmt.Errorf("parse %s: %w", f.Name, f.tmplErr)
+	}
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render %s: %w", f.Name, err)
+	}
+	return buf.Bytes(), nil
+}
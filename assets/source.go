@@ -0,0 +1,180 @@
+// Package assets provides the file sources the web UI is served from. A
+// source is either the embedded public directory, a directory on disk, or an
+// overlay that stacks several sources so a deployment can replace or add
+// files without forking.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"html2go-converter/web"
+)
+
+// Source is a file system of static assets that can report which layer holds
+// a given file.
+type Source interface {
+	fs.FS
+
+	// Layer returns the name of the layer that serves the named file.
+	Layer(name string) (string, error)
+}
+
+// layer is a Source backed by a single fs.FS. onDisk is set for layers
+// whose files can change while the server runs.
+type layer struct {
+	name   string
+	fsys   fs.FS
+	onDisk bool
+}
+
+// FS wraps fsys as a single-layer Source identified by name.
+func FS(name string, fsys fs.FS) Source {
+	return &layer{name: name, fsys: fsys}
+}
+
+// Embedded returns the public directory compiled into the binary.
+func Embedded() Source {
+	return FS("embedded", web.FS)
+}
+
+// Dir returns a Source reading from dir on disk. A Catalog of it notices
+// when its files change.
+func Dir(dir string) Source {
+	return &layer{name: "dir:" + dir, fsys: os.DirFS(dir), onDisk: true}
+}
+
+// Mutable reports whether the files of src can change while the server
+// runs, which is the case for sources with a Dir layer
+func Mutable(src Source) bool {
+	switch s := src.(type) {
+	case *layer:
+		return s.onDisk
+	case *overlay:
+		for _, l := range s.layers {
+			if Mutable(l) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Stamp summarizes the names, sizes and modification times of the files of
+// src. It changes whenever a file is added, removed or written.
+func Stamp(src Source) (string, error) {
+	h := sha256.New()
+	err := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", name, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (l *layer) Open(name string) (fs.File, error) {
+	return l.fsys.Open(name)
+}
+
+func (l *layer) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(l.fsys, name)
+}
+
+func (l *layer) Layer(name string) (string, error) {
+	if _, err := fs.Stat(l.fsys, name); err != nil {
+		return "", err
+	}
+	return l.name, nil
+}
+
+// overlay stacks sources; the first layer holding a file wins.
+type overlay struct {
+	layers []Source
+}
+
+// Overlay stacks layers so that files in earlier layers shadow files with the
+// same name in later ones. Directories are merged across all layers.
+func Overlay(layers ...Source) Source {
+	return &overlay{layers: layers}
+}
+
+func (o *overlay) Open(name string) (fs.File, error) {
+	for _, l := range o.layers {
+		f, err := l.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (o *overlay) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	found := false
+	for _, l := range o.layers {
+		list, err := fs.ReadDir(l, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, e := range list {
+			if !seen[e.Name()] {
+				seen[e.Name()] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (o *overlay) Layer(name string) (string, error) {
+	for _, l := range o.layers {
+		layerName, err := l.Layer(name)
+		if err == nil {
+			return layerName, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadFile reads the named file from src and returns its content together
+// with the name of the layer that served it.
+func ReadFile(src Source, name string) ([]byte, string, error) {
+	layerName, err := src.Layer(name)
+	if err != nil {
+		return nil, "", err
+	}
+	content, err := fs.ReadFile(src, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return content, layerName, nil
+}
@@ -0,0 +1,111 @@
+// Command html2go converts HTML on the command line with the same converter
+// as the web service.
+//
+//	go run ./cmd/html2go archive -o views.zip -package views site/
+//	go run ./cmd/html2go archive -o views.zip site.tar.gz
+//	go run ./cmd/html2go archive -verify site/
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"html2go-converter/converter"
+)
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "archive":
+		runArchive(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: html2go archive [flags] <folder|archive.zip|archive.tar.gz>")
+	os.Exit(2)
+}
+
+// runArchive converts a folder or archive of HTML files into a zip of Go files
+func runArchive(args []string) {
+	flags := flag.NewFlagSet("archive", flag.ExitOnError)
+	outPtr := flags.String("o", "views.zip", "输出的zip文件路径")
+	packagePtr := flags.String("package", "views", "生成代码的Go包名")
+	packagePrefixPtr := flags.String("package-prefix", "h", "htmlgo包前缀")
+	vuetifyPrefixPtr := flags.String("vuetify-prefix", "v", "Vuetify包前缀")
+	vuetifyXPrefixPtr := flags.String("vuetifyx-prefix", "vx", "VuetifyX包前缀")
+	childrenModePtr := flags.Bool("children-mode", false, "使用Children模式生成代码")
+	verifyPtr := flags.Bool("verify", false, "将生成的代码渲染回HTML并与输入比较，存在差异时以非零状态退出")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		usage()
+	}
+	input := flags.Arg(0)
+
+	// Read the input folder or archive
+	info, err := os.Stat(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var files []converter.SourceFile
+	var skipped []string
+	if info.IsDir() {
+		files, skipped, err = converter.ReadFolder(os.DirFS(input))
+	} else {
+		var data []byte
+		if data, err = os.ReadFile(input); err == nil {
+			files, skipped, err = converter.ReadArchive(data)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", input, err)
+	}
+
+	opts := converter.ArchiveOptions{
+		Package: *packagePtr,
+		Options: converter.Request{
+			PackagePrefix:  *packagePrefixPtr,
+			VuetifyPrefix:  *vuetifyPrefixPtr,
+			VuetifyXPrefix: *vuetifyXPrefixPtr,
+			ChildrenMode:   *childrenModePtr,
+			Verify:         *verifyPtr,
+		},
+		Skipped: skipped,
+	}
+	output, manifest, err := converter.ConvertArchive(context.Background(), files, opts)
+	if err != nil {
+		log.Fatalf("Failed to convert %s: %v", input, err)
+	}
+	if err := os.WriteFile(*outPtr, output, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *outPtr, err)
+	}
+
+	for _, entry := range manifest.Files {
+		if entry.Error != "" {
+			log.Printf("%s: %s", entry.Source, entry.Error)
+		}
+		for _, warning := range entry.Warnings {
+			log.Printf("%s: warning: %s", entry.Source, warning)
+		}
+		for _, mismatch := range entry.Mismatches {
+			log.Printf("%s: mismatch: %s", entry.Source, mismatch)
+		}
+	}
+	if *verifyPtr {
+		log.Printf("Wrote %s: %d converted, %d failed, %d mismatched", *outPtr, manifest.Converted, manifest.Failed, manifest.Mismatched)
+	} else {
+		log.Printf("Wrote %s: %d converted, %d failed", *outPtr, manifest.Converted, manifest.Failed)
+	}
+	if manifest.Failed > 0 || manifest.Mismatched > 0 {
+		os.Exit(1)
+	}
+}
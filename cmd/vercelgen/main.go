@@ -0,0 +1,32 @@
+// Command vercelgen writes vercel.json from the route table in package
+// routes, so the Vercel deployment and the local server never drift apart.
+//
+//	go run ./cmd/vercelgen -o vercel.json
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"html2go-converter/routes"
+)
+
+func main() {
+	outPtr := flag.String("o", "vercel.json", "输出文件路径，使用 - 输出到标准输出")
+	flag.Parse()
+
+	content, err := routes.VercelJSON()
+	if err != nil {
+		log.Fatalf("Failed to render vercel.json: %v", err)
+	}
+
+	if *outPtr == "-" {
+		os.Stdout.Write(content)
+		return
+	}
+	if err := os.WriteFile(*outPtr, content, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *outPtr, err)
+	}
+	log.Printf("Wrote %s", *outPtr)
+}
@@ -0,0 +1,173 @@
+// Package config holds the runtime configuration of the application, read
+// from environment variables.
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/history"
+	"html2go-converter/preview"
+	"html2go-converter/snippets"
+)
+
+// Version is the application version reported to the frontend.
+const Version = "1.0.0"
+
+// Defaults are the conversion options the web UI starts with.
+type Defaults struct {
+	PackagePrefix  string `json:"packagePrefix"`
+	VuetifyPrefix  string `json:"vuetifyPrefix"`
+	VuetifyXPrefix string `json:"vuetifyXPrefix"`
+}
+
+// App is the configuration injected into index.html for the frontend.
+type App struct {
+	// APIBaseURL is prepended to API paths; empty means same origin.
+	APIBaseURL string `json:"apiBaseUrl"`
+	// Version is the application version.
+	Version string `json:"version"`
+	// Environment is the deployment environment name, e.g. "dev" or "prod".
+	Environment string `json:"environment"`
+	// Features lists the enabled optional features.
+	Features []string `json:"features"`
+	// Defaults are the initial conversion options.
+	Defaults Defaults `json:"defaults"`
+}
+
+// FromEnv reads the frontend configuration from APP_ENV, API_BASE_URL,
+// APP_VERSION, APP_FEATURES (comma separated) and DEFAULT_PACKAGE_PREFIX,
+// DEFAULT_VUETIFY_PREFIX, DEFAULT_VUETIFYX_PREFIX.
+func FromEnv() App {
+	return App{
+		APIBaseURL:  strings.TrimSuffix(os.Getenv("API_BASE_URL"), "/"),
+		Version:     getEnv("APP_VERSION", Version),
+		Environment: getEnv("APP_ENV", "prod"),
+		Features:    splitList(os.Getenv("APP_FEATURES")),
+		Defaults: Defaults{
+			PackagePrefix:  getEnv("DEFAULT_PACKAGE_PREFIX", "h"),
+			VuetifyPrefix:  getEnv("DEFAULT_VUETIFY_PREFIX", "v"),
+			VuetifyXPrefix: getEnv("DEFAULT_VUETIFYX_PREFIX", "vx"),
+		},
+	}
+}
+
+// ConversionLimits reads the conversion limits from MAX_BODY_BYTES,
+// MAX_HTML_DEPTH, MAX_HTML_NODES, CONVERSION_TIMEOUT, MAX_CONCURRENT_CONVERSIONS,
+// CONVERSION_QUEUE_TIMEOUT, JOB_MAX_BODY_BYTES and JOB_TIMEOUT, falling back
+// to converter.DefaultLimits.
+// Timeouts are durations such as "5s". Zero disables a limit.
+func ConversionLimits() converter.Limits {
+	limits := converter.DefaultLimits
+	limits.MaxBodyBytes = getEnvInt("MAX_BODY_BYTES", limits.MaxBodyBytes)
+	limits.MaxDepth = int(getEnvInt("MAX_HTML_DEPTH", int64(limits.MaxDepth)))
+	limits.MaxNodes = int(getEnvInt("MAX_HTML_NODES", int64(limits.MaxNodes)))
+	limits.Timeout = getEnvDuration("CONVERSION_TIMEOUT", limits.Timeout)
+	limits.MaxConcurrent = int(getEnvInt("MAX_CONCURRENT_CONVERSIONS", int64(limits.MaxConcurrent)))
+	limits.QueueTimeout = getEnvDuration("CONVERSION_QUEUE_TIMEOUT", limits.QueueTimeout)
+	limits.JobMaxBodyBytes = getEnvInt("JOB_MAX_BODY_BYTES", limits.JobMaxBodyBytes)
+	limits.JobTimeout = getEnvDuration("JOB_TIMEOUT", limits.JobTimeout)
+	return limits
+}
+
+// Quarantine reads the directory that keeps the inputs crashing the
+// converter from QUARANTINE_DIR, empty when disabled, and its size cap in
+// bytes from QUARANTINE_MAX_BYTES.
+func Quarantine() (dir string, maxBytes int64) {
+	return os.Getenv("QUARANTINE_DIR"), getEnvInt("QUARANTINE_MAX_BYTES", converter.DefaultQuarantineBytes)
+}
+
+// Cache reads the size of the in-memory result cache from CACHE_MAX_BYTES,
+// zero disabling the cache, and its optional disk tier from CACHE_DIR and
+// CACHE_DIR_MAX_BYTES.
+func Cache() (maxBytes int64, dir string, dirMaxBytes int64) {
+	return getEnvInt("CACHE_MAX_BYTES", converter.DefaultCacheBytes), os.Getenv("CACHE_DIR"), getEnvInt("CACHE_DIR_MAX_BYTES", 10*converter.DefaultCacheBytes)
+}
+
+// Snippets reads the BoltDB file storing shared snippets from SNIPPETS_DB,
+// empty to keep them in memory, and their expiry policy from
+// SNIPPET_DEFAULT_EXPIRY and SNIPPET_MAX_TTL.
+func Snippets() (path string, policy snippets.Policy) {
+	policy = snippets.DefaultPolicy
+	policy.DefaultExpiry = getEnv("SNIPPET_DEFAULT_EXPIRY", policy.DefaultExpiry)
+	policy.MaxTTL = getEnvDuration("SNIPPET_MAX_TTL", policy.MaxTTL)
+	return os.Getenv("SNIPPETS_DB"), policy
+}
+
+// History reads the BoltDB file storing conversion histories from
+// HISTORY_DB, empty to keep them in memory, and their bounds from
+// HISTORY_TTL, HISTORY_MAX_ENTRIES and HISTORY_MAX_REVISIONS.
+func History() (path string, policy history.Policy) {
+	policy = history.DefaultPolicy
+	policy.TTL = getEnvDuration("HISTORY_TTL", policy.TTL)
+	policy.MaxHistories = int(getEnvInt("HISTORY_MAX_ENTRIES", int64(policy.MaxHistories)))
+	policy.MaxRevisions = int(getEnvInt("HISTORY_MAX_REVISIONS", int64(policy.MaxRevisions)))
+	return os.Getenv("HISTORY_DB"), policy
+}
+
+// ExamplesDir reads the directory of the example gallery from EXAMPLES_DIR,
+// empty for the examples built into the binary.
+func ExamplesDir() string {
+	return os.Getenv("EXAMPLES_DIR")
+}
+
+// Preview reads how long previews are kept from PREVIEW_TTL, how many at
+// most from PREVIEW_MAX_ENTRIES, and the directory of the vendored preview
+// stylesheets from PREVIEW_CSS_DIR, empty for those built into the binary.
+func Preview() (ttl time.Duration, maxEntries int64, cssDir string) {
+	return getEnvDuration("PREVIEW_TTL", preview.DefaultTTL), getEnvInt("PREVIEW_MAX_ENTRIES", preview.DefaultMaxEntries), os.Getenv("PREVIEW_CSS_DIR")
+}
+
+// getEnvInt returns the integer value of the environment variable key, or
+// fallback when it is unset or invalid.
+func getEnvInt(key string, fallback int64) int64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		log.Printf("Ignoring invalid %s %q", key, value)
+		return fallback
+	}
+	return n
+}
+
+// getEnvDuration returns the duration value of the environment variable
+// key, or fallback when it is unset or invalid.
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("Ignoring invalid %s %q", key, value)
+		return fallback
+	}
+	return d
+}
+
+// getEnv returns the value of the environment variable key, or fallback when
+// it is unset or empty.
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// splitList splits a comma separated list, dropping empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,452 @@
+package converter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+
+	"html2go-converter/diff"
+	"html2go-converter/errcode"
+)
+
+// Limits applied when reading an uploaded archive, protecting against
+// archive bombs. MaxArchiveFiles counts every file, skipped or not.
+const (
+	MaxArchiveFiles = 1000
+	MaxArchiveBytes = 32 << 20
+)
+
+// Errors returned when reading archives
+var (
+	ErrUnknownArchive   = errcode.New(http.StatusBadRequest, "archive_unsupported", "Archive must be a zip or tar.gz file").WithField("archive")
+	ErrArchiveTooLarge  = errcode.New(http.StatusRequestEntityTooLarge, "archive_too_large", "Archive is too large").WithField("archive").WithDetails(map[string]any{"maxBytes": MaxArchiveBytes, "maxFiles": MaxArchiveFiles})
+	ErrArchiveNoHTML    = errcode.New(http.StatusBadRequest, "archive_empty", "Archive contains no .html files").WithField("archive")
+	ErrInvalidGoPackage = errcode.New(http.StatusBadRequest, "invalid_package", "Invalid Go package name").WithField("package")
+)
+
+// Import paths of the packages generated code refers to
+const (
+	htmlgoImport   = "github.com/theplant/htmlgo"
+	vuetifyImport  = "github.com/qor5/x/v3/ui/vuetify"
+	vuetifyXImport = "github.com/qor5/x/v3/ui/vuetifyx"
+)
+
+// SourceFile is an HTML document to convert, identified by its path in the
+// uploaded archive or folder.
+type SourceFile struct {
+	Path    string
+	Content []byte
+}
+
+// ArchiveOptions configures the conversion of a folder of HTML files.
+type ArchiveOptions struct {
+	// Package is the package clause shared by every generated file.
+	Package string
+	// Options are the conversion options applied to every file; the HTML
+	// and Direction fields are ignored.
+	Options Request
+	// Skipped lists the archive entries that were not converted, reported
+	// in the manifest.
+	Skipped []string
+}
+
+// ManifestEntry describes the conversion of one source file.
+type ManifestEntry struct {
+	Source   string `json:"source"`
+	Output   string `json:"output,omitempty"`
+	Function string `json:"function,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// ErrorCode, ErrorField and ErrorDetails describe Error as in a batch
+	// result
+	ErrorCode    string         `json:"errorCode,omitempty"`
+	ErrorField   string         `json:"errorField,omitempty"`
+	ErrorDetails map[string]any `json:"errorDetails,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+	// Mismatches are the round trip differences of a file converted with
+	// Options.Verify
+	Mismatches []diff.Change `json:"mismatches,omitempty"`
+}
+
+// Manifest lists the diagnostics of an archive conversion. It is written to
+// manifest.json in the generated archive.
+type Manifest struct {
+	Package   string `json:"package"`
+	Converted int    `json:"converted"`
+	Failed    int    `json:"failed"`
+	// Mismatched counts the files whose code does not render back to their
+	// HTML, when converted with Options.Verify
+	Mismatched int             `json:"mismatched,omitempty"`
+	Files      []ManifestEntry `json:"files"`
+	Skipped    []string        `json:"skipped,omitempty"`
+}
+
+// ReadArchive extracts the files of a zip or tar.gz archive, detected by its
+// magic bytes. Directories and files that are not .html are reported in
+// skipped.
+func ReadArchive(data []byte) (files []SourceFile, skipped []string, err error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")), bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		files, skipped, err = readZip(data)
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		files, skipped, err = readTarGz(data)
+	default:
+		return nil, nil, ErrUnknownArchive
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, ErrArchiveNoHTML
+	}
+	return files, skipped, nil
+}
+
+func readZip(data []byte) ([]SourceFile, []string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read zip: %w", err)
+	}
+
+	var files []SourceFile
+	var skipped []string
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !isHTMLPath(f.Name) {
+			if skipped = append(skipped, f.Name); len(files)+len(skipped) > MaxArchiveFiles {
+				return nil, nil, ErrArchiveTooLarge
+			}
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, nil, fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		content, err := readLimited(rc, &total)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if files = append(files, SourceFile{Path: f.Name, Content: content}); len(files)+len(skipped) > MaxArchiveFiles {
+			return nil, nil, ErrArchiveTooLarge
+		}
+	}
+	return files, skipped, nil
+}
+
+func readTarGz(data []byte) ([]SourceFile, []string, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read gzip: %w", err)
+	}
+	defer gz.Close()
+
+	var files []SourceFile
+	var skipped []string
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read tar: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if !isHTMLPath(hdr.Name) {
+			if skipped = append(skipped, hdr.Name); len(files)+len(skipped) > MaxArchiveFiles {
+				return nil, nil, ErrArchiveTooLarge
+			}
+			continue
+		}
+		content, err := readLimited(tr, &total)
+		if err != nil {
+			return nil, nil, err
+		}
+		if files = append(files, SourceFile{Path: hdr.Name, Content: content}); len(files)+len(skipped) > MaxArchiveFiles {
+			return nil, nil, ErrArchiveTooLarge
+		}
+	}
+	return files, skipped, nil
+}
+
+// ReadFolder collects the HTML files below root of fsys, the way ReadArchive
+// reads an uploaded archive
+func ReadFolder(fsys fs.FS) (files []SourceFile, skipped []string, err error) {
+	var total int64
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip hidden directories such as .git
+			if name != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !isHTMLPath(name) {
+			if skipped = append(skipped, name); len(files)+len(skipped) > MaxArchiveFiles {
+				return ErrArchiveTooLarge
+			}
+			return nil
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		content, err := readLimited(f, &total)
+		if err != nil {
+			return err
+		}
+		if files = append(files, SourceFile{Path: name, Content: content}); len(files)+len(skipped) > MaxArchiveFiles {
+			return ErrArchiveTooLarge
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil, ErrArchiveNoHTML
+	}
+	return files, skipped, nil
+}
+
+// readLimited reads r, failing once the running total exceeds
+// MaxArchiveBytes
+func readLimited(r io.Reader, total *int64) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(r, MaxArchiveBytes-*total+1))
+	if err != nil {
+		return nil, err
+	}
+	*total += int64(len(content))
+	if *total > MaxArchiveBytes {
+		return nil, ErrArchiveTooLarge
+	}
+	return content, nil
+}
+
+// isHTMLPath reports whether name is an HTML document worth converting,
+// ignoring macOS resource forks and hidden files
+func isHTMLPath(name string) bool {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") || strings.HasPrefix(name, "__MACOSX/") {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(base))
+	return ext == ".html" || ext == ".htm"
+}
+
+// ConvertArchive converts every source file into a Go file declaring one
+// function per document, and returns a zip archive of the generated files
+// plus manifest.json. Files that fail to convert are listed in the manifest
+// and do not stop the others.
+func ConvertArchive(ctx context.Context, files []SourceFile, opts ArchiveOptions) ([]byte, Manifest, error) {
+	if opts.Package == "" {
+		opts.Package = "views"
+	}
+	if !token.IsIdentifier(opts.Package) || token.IsKeyword(opts.Package) {
+		return nil, Manifest{}, ErrInvalidGoPackage
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	// Convert every document with the shared options through the batch runner
+	batch := BatchRequest{Defaults: opts.Options, Items: make([]BatchItem, len(files))}
+	batch.Defaults.Direction = DirectionHTMLToGo
+	for i, f := range files {
+		batch.Items[i] = BatchItem{Name: f.Path, HTML: string(f.Content)}
+	}
+	results := ConvertBatch(ctx, batch, DefaultBatchWorkers).Results
+
+	manifest := Manifest{Package: opts.Package, Files: make([]ManifestEntry, len(files)), Skipped: opts.Skipped}
+	usedFuncs := make(map[string]bool)
+	usedFiles := map[string]bool{"manifest.json": true}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i, f := range files {
+		entry := ManifestEntry{Source: f.Path}
+		result := results[i]
+		if result.Error != "" {
+			entry.Error = result.Error
+			entry.ErrorCode, entry.ErrorField, entry.ErrorDetails = result.ErrorCode, result.ErrorField, result.ErrorDetails
+			manifest.Files[i] = entry
+			manifest.Failed++
+			continue
+		}
+
+		entry.Function = uniqueName(functionName(f.Path), usedFuncs, "")
+		entry.Output = uniqueName(fileName(f.Path), usedFiles, ".go")
+		if entry.Function != functionName(f.Path) {
+			entry.Warnings = append(entry.Warnings, fmt.Sprintf("function renamed to %s to avoid a name collision", entry.Function))
+		}
+
+		source, warnings := generateGoFile(opts, f.Path, entry.Function, result.Code)
+		entry.Warnings = append(entry.Warnings, warnings...)
+		if v := result.Verification; v != nil && !v.Equal {
+			if v.Error != "" {
+				entry.Warnings = append(entry.Warnings, "round trip failed: "+v.Error)
+			}
+			entry.Mismatches = v.Mismatches
+			manifest.Mismatched++
+		}
+
+		w, err := zw.Create(entry.Output)
+		if err != nil {
+			return nil, Manifest{}, err
+		}
+		if _, err := w.Write(source); err != nil {
+			return nil, Manifest{}, err
+		}
+		manifest.Files[i] = entry
+		manifest.Converted++
+	}
+
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return nil, Manifest{}, err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return nil, Manifest{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, Manifest{}, err
+	}
+	return buf.Bytes(), manifest, nil
+}
+
+// generateGoFile wraps converted code into a formatted Go source file. When
+// the result cannot be formatted the raw source is kept with a warning.
+func generateGoFile(opts ArchiveOptions, sourcePath, funcName, code string) ([]byte, []string) {
+	htmlgo := qualifier(opts.Options.PackagePrefix)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by html2go-converter from %s. DO NOT EDIT.\n\n", sourcePath)
+	fmt.Fprintf(&b, "package %s\n\n", opts.Package)
+	b.WriteString("import (\n")
+	writeImport(&b, opts.Options.PackagePrefix, htmlgoImport, true)
+	writeImport(&b, opts.Options.VuetifyPrefix, vuetifyImport, usesPackage(code, opts.Options.VuetifyPrefix, vuetifyComponent))
+	writeImport(&b, opts.Options.VuetifyXPrefix, vuetifyXImport, usesPackage(code, opts.Options.VuetifyXPrefix, vuetifyXComponent))
+	b.WriteString(")\n\n")
+	fmt.Fprintf(&b, "// %s renders %s.\n", funcName, sourcePath)
+	fmt.Fprintf(&b, "func %s() %sHTMLComponent {\n", funcName, htmlgo)
+	fmt.Fprintf(&b, "\treturn %sComponents(\n%s,\n\t)\n}\n", htmlgo, bodyArguments(code))
+
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return []byte(b.String()), []string{"generated code could not be formatted: " + err.Error()}
+	}
+	return formatted, nil
+}
+
+// writeImport writes an import line for a package referred to by prefix; an
+// empty prefix means the package is dot-imported
+func writeImport(b *strings.Builder, prefix, importPath string, used bool) {
+	if !used {
+		return
+	}
+	if prefix == "" {
+		prefix = "."
+	}
+	fmt.Fprintf(b, "\t%s %s\n", prefix, strconv.Quote(importPath))
+}
+
+// Calls of Vuetify and VuetifyX components, used to detect dot-imported
+// packages
+var (
+	vuetifyComponent  = regexp.MustCompile(`\bV[A-WYZ][A-Za-z0-9]*\(`)
+	vuetifyXComponent = regexp.MustCompile(`\bVX[A-Z][A-Za-z0-9]*\(`)
+)
+
+// usesPackage reports whether code refers to the package with the given
+// prefix. Dot-imported packages are detected by their component calls.
+func usesPackage(code, prefix string, component *regexp.Regexp) bool {
+	if prefix == "" {
+		return component.MatchString(code)
+	}
+	return strings.Contains(code, prefix+".")
+}
+
+// qualifier returns the selector prefix for identifiers of a package
+func qualifier(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "."
+}
+
+// functionName derives an exported Go identifier from a file path, e.g.
+// "pages/user-list.html" becomes "PagesUserList"
+func functionName(p string) string {
+	name := strcase.ToCamel(identifierWords(strings.TrimSuffix(p, path.Ext(p))))
+	if name == "" {
+		name = "Page"
+	}
+	if !unicode.IsLetter([]rune(name)[0]) {
+		name = "Page" + name
+	}
+	return name
+}
+
+// fileName derives a Go file name from a file path, e.g.
+// "pages/user-list.html" becomes "pages_user_list.go"
+func fileName(p string) string {
+	name := strcase.ToSnake(identifierWords(strings.TrimSuffix(p, path.Ext(p))))
+	if name == "" {
+		name = "page"
+	}
+	// Avoid names the go tool treats specially
+	if strings.HasSuffix(name, "_test") {
+		name += "_page"
+	}
+	return name + ".go"
+}
+
+// identifierWords replaces every character that cannot appear in an
+// identifier with a space, so strcase splits words on it
+func identifierWords(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, s)
+}
+
+// uniqueName returns name+ext, or name2+ext, name3+ext... if already used
+func uniqueName(name string, used map[string]bool, ext string) string {
+	base := strings.TrimSuffix(name, ext)
+	candidate := base + ext
+	for n := 2; used[candidate]; n++ {
+		candidate = base + strconv.Itoa(n) + ext
+	}
+	used[candidate] = true
+	return candidate
+}
@@ -0,0 +1,181 @@
+package converter
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"html2go-converter/errcode"
+)
+
+// DefaultBatchWorkers is the number of conversions a batch runs at once.
+const DefaultBatchWorkers = 4
+
+// MaxBatchItems is the largest number of items accepted in one batch.
+const MaxBatchItems = 500
+
+// Errors returned by ValidateBatch
+var (
+	ErrEmptyBatch    = errcode.New(http.StatusBadRequest, "batch_empty", "Batch must contain at least one item").WithField("items")
+	ErrBatchTooLarge = errcode.New(http.StatusBadRequest, "batch_too_large", "Batch contains too many items").WithField("items").WithDetails(map[string]any{"maxItems": MaxBatchItems})
+)
+
+// Overrides are per-item options of a batch. Nil fields keep the batch
+// defaults.
+type Overrides struct {
+	PackagePrefix  *string    `json:"packagePrefix,omitempty" doc:"Overrides defaults.packagePrefix"`
+	VuetifyPrefix  *string    `json:"vuetifyPrefix,omitempty" doc:"Overrides defaults.vuetifyPrefix"`
+	VuetifyXPrefix *string    `json:"vuetifyXPrefix,omitempty" doc:"Overrides defaults.vuetifyXPrefix"`
+	Direction      *Direction `json:"direction,omitempty" doc:"Overrides defaults.direction"`
+	ChildrenMode   *bool      `json:"childrenMode,omitempty" doc:"Overrides defaults.childrenMode"`
+	Verify         *bool      `json:"verify,omitempty" doc:"Overrides defaults.verify"`
+}
+
+// BatchItem is a named snippet of a batch.
+type BatchItem struct {
+	Name string `json:"name" doc:"Name echoed in the result of the item"`
+	HTML string `json:"html" doc:"HTML to convert; an empty document fails the item only"`
+	Overrides
+}
+
+// BatchRequest represents the JSON request body of a batch conversion. The
+// defaults apply to every item unless the item overrides them.
+type BatchRequest struct {
+	Defaults Request     `json:"defaults" doc:"Options applied to every item; defaults.html is ignored"`
+	Items    []BatchItem `json:"items" required:"true" doc:"Snippets to convert"`
+}
+
+// BatchResult is the outcome of converting one batch item.
+type BatchResult struct {
+	Index int    `json:"index" doc:"Position of the item in the request"`
+	Name  string `json:"name" doc:"Name of the item"`
+	Code  string `json:"code,omitempty" doc:"Generated Go code"`
+	Error string `json:"error,omitempty" doc:"Error message when the item failed"`
+	// ErrorCode, ErrorField and ErrorDetails describe the error like the
+	// problem document of a single conversion would
+	ErrorCode    string         `json:"errorCode,omitempty" doc:"Stable code of the error when the item failed, as listed at /api/v1/errors"`
+	ErrorField   string         `json:"errorField,omitempty" doc:"JSON path of the invalid field of the item"`
+	ErrorDetails map[string]any `json:"errorDetails,omitempty" doc:"Code-specific data of the error"`
+	// Verification is only set for items converted with verify
+	Verification *Verification `json:"verification,omitempty" doc:"Round trip of the generated code, for items converted with verify"`
+}
+
+// fail records err as the outcome of the item
+func (r *BatchResult) fail(err error) {
+	info := ErrorInfo(err)
+	r.Error = err.Error()
+	r.ErrorCode, r.ErrorField, r.ErrorDetails = info.Code, info.Field, info.Details
+}
+
+// BatchResponse represents the JSON response of a batch conversion, with
+// results in item order.
+type BatchResponse struct {
+	Results   []BatchResult `json:"results" doc:"Results in item order"`
+	Succeeded int           `json:"succeeded" doc:"Number of items converted"`
+	Failed    int           `json:"failed" doc:"Number of items that failed"`
+}
+
+// ValidateBatch checks the size of a batch before it runs
+func ValidateBatch(batch BatchRequest) error {
+	switch {
+	case len(batch.Items) == 0:
+		return ErrEmptyBatch
+	case len(batch.Items) > MaxBatchItems:
+		return ErrBatchTooLarge
+	}
+	return nil
+}
+
+// Request returns the conversion request of item i with the batch defaults
+// and the item overrides applied
+func (b BatchRequest) Request(i int) Request {
+	item := b.Items[i]
+	req := item.Apply(b.Defaults)
+	req.HTML = item.HTML
+	return req
+}
+
+// Apply returns req with the non-nil overrides applied
+func (o Overrides) Apply(req Request) Request {
+	if o.PackagePrefix != nil {
+		req.PackagePrefix = *o.PackagePrefix
+	}
+	if o.VuetifyPrefix != nil {
+		req.VuetifyPrefix = *o.VuetifyPrefix
+	}
+	if o.VuetifyXPrefix != nil {
+		req.VuetifyXPrefix = *o.VuetifyXPrefix
+	}
+	if o.Direction != nil {
+		req.Direction = *o.Direction
+	}
+	if o.ChildrenMode != nil {
+		req.ChildrenMode = *o.ChildrenMode
+	}
+	if o.Verify != nil {
+		req.Verify = *o.Verify
+	}
+	return req
+}
+
+// RunBatch converts every item of batch with at most workers conversions in
+// flight and calls emit with each result as soon as it is ready. Calls to
+// emit are serialized. A failing item is reported in its result and does not
+// stop the others; items not started before ctx is done report ctx.Err().
+func RunBatch(ctx context.Context, batch BatchRequest, workers int, emit func(BatchResult)) {
+	RunBatchWith(ctx, batch, workers, ConvertContext, emit)
+}
+
+// RunBatchWith runs a batch like RunBatch, converting each item with
+// convert, e.g. ConvertJob for asynchronous jobs
+func RunBatchWith(ctx context.Context, batch BatchRequest, workers int, convert func(context.Context, Request) (Response, error), emit func(BatchResult)) {
+	if workers <= 0 {
+		workers = DefaultBatchWorkers
+	}
+
+	jobs := make(chan int)
+	var emitMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := BatchResult{Index: i, Name: batch.Items[i].Name}
+				if err := ctx.Err(); err != nil {
+					result.fail(err)
+				} else if resp, err := convert(ctx, batch.Request(i)); err != nil {
+					result.fail(err)
+				} else {
+					result.Code = resp.Code
+					result.Verification = resp.Verification
+				}
+
+				emitMu.Lock()
+				emit(result)
+				emitMu.Unlock()
+			}
+		}()
+	}
+
+	for i := range batch.Items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// ConvertBatch runs a batch and collects the results in item order
+func ConvertBatch(ctx context.Context, batch BatchRequest, workers int) BatchResponse {
+	response := BatchResponse{Results: make([]BatchResult, len(batch.Items))}
+	RunBatch(ctx, batch, workers, func(result BatchResult) {
+		response.Results[result.Index] = result
+		if result.Error != "" {
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+	})
+	return response
+}
@@ -0,0 +1,360 @@
+package converter
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// html2goModule is the module path of the converter dependency
+const html2goModule = "github.com/zhangshanwen/html2go"
+
+// outputFormat changes whenever the post-processing of the html2go output
+// changes, invalidating cached results like a new html2go version does
+const outputFormat = "1"
+
+var converterVersion = sync.OnceValue(func() string {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path != html2goModule {
+				continue
+			}
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			version = dep.Version
+			if dep.Sum != "" {
+				version += "@" + dep.Sum
+			}
+		}
+	}
+	return version + "/" + outputFormat
+})
+
+// ConverterVersion identifies the html2go release and the post-processing
+// that produce conversion results. It is part of every RequestKey.
+func ConverterVersion() string {
+	return converterVersion()
+}
+
+// CacheStatus reports how ConvertCached answered a request
+type CacheStatus string
+
+// Cache statuses, sent in the X-Cache header
+const (
+	CacheHit    CacheStatus = "HIT"
+	CacheMiss   CacheStatus = "MISS"
+	CacheBypass CacheStatus = "BYPASS"
+)
+
+// DefaultCacheBytes is the default size of the in-memory cache
+const DefaultCacheBytes = 32 << 20
+
+// Cache keeps conversion responses by RequestKey in a least recently used
+// list in memory, and optionally in a directory that survives restarts.
+// Both tiers are bounded in bytes. Results of another ConverterVersion are
+// never returned: keys include the version, and NewCache removes the disk
+// entries of other versions.
+type Cache struct {
+	maxBytes int64
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+	bytes int64
+
+	dir          string
+	dirMaxBytes  int64
+	dirBytes     int64
+	dirMu        sync.Mutex
+	versionedDir string
+}
+
+type cacheItem struct {
+	key  string
+	resp Response
+	size int64
+}
+
+// NewCache returns a cache holding up to maxBytes of responses in memory.
+// If dir is not empty, responses are also stored there, up to dirMaxBytes.
+func NewCache(maxBytes int64, dir string, dirMaxBytes int64) (*Cache, error) {
+	c := &Cache{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+	if dir == "" {
+		return c, nil
+	}
+
+	version := versionDirName(ConverterVersion())
+	c.dir = dir
+	c.dirMaxBytes = dirMaxBytes
+	c.versionedDir = filepath.Join(dir, version)
+	if err := os.MkdirAll(c.versionedDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	// Drop the results of other converter versions. dir may be shared, so
+	// only directories named like a version are ours to remove.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != version && isVersionDir(e.Name()) {
+			if err := os.RemoveAll(filepath.Join(dir, e.Name())); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if c.dirBytes, err = trimDir(c.versionedDir, dirMaxBytes); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Get returns the response cached under key, looking in memory first
+func (c *Cache) Get(key string) (Response, bool) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		resp := el.Value.(*cacheItem).resp
+		c.mu.Unlock()
+		return resp, true
+	}
+	c.mu.Unlock()
+
+	if c.dir == "" || !validKey(key) {
+		return Response{}, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return Response{}, false
+	}
+	var resp Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return Response{}, false
+	}
+	touch(c.path(key))
+	c.putMemory(key, resp)
+	return resp, true
+}
+
+// Put caches resp under key
+func (c *Cache) Put(key string, resp Response) {
+	c.putMemory(key, resp)
+	if c.dir != "" && validKey(key) {
+		if err := c.putDisk(key, resp); err != nil {
+			log.Printf("Failed to cache result %s on disk: %v", key, err)
+		}
+	}
+}
+
+// Len returns the number of responses in memory
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// responseSize estimates the memory held by an entry
+func responseSize(key string, resp Response) int64 {
+	size := len(key) + len(resp.Code) + len(resp.HTML) + len(resp.Hash) + 64
+	if v := resp.Verification; v != nil {
+		size += len(v.HTML) + len(v.Error) + 64
+		for _, m := range v.Mismatches {
+			size += len(m.Op) + len(m.Path) + len(m.Old) + len(m.New) + 64
+		}
+	}
+	return int64(size)
+}
+
+func (c *Cache) putMemory(key string, resp Response) {
+	size := responseSize(key, resp)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&cacheItem{key: key, resp: resp, size: size})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		oldest := c.ll.Back()
+		item := oldest.Value.(*cacheItem)
+		c.ll.Remove(oldest)
+		delete(c.items, item.key)
+		c.bytes -= item.size
+	}
+}
+
+func (c *Cache) putDisk(key string, resp Response) error {
+	path := c.path(key)
+	if _, err := os.Stat(path); err == nil {
+		return touch(path)
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.versionedDir, path, data); err != nil {
+		return err
+	}
+
+	c.dirMu.Lock()
+	defer c.dirMu.Unlock()
+	c.dirBytes += int64(len(data))
+	if c.dirMaxBytes > 0 && c.dirBytes > c.dirMaxBytes {
+		c.dirBytes, err = trimDir(c.versionedDir, c.dirMaxBytes)
+	}
+	return err
+}
+
+// touch refreshes the modification time of a cached result, so trimDir
+// keeps recently used results
+func touch(path string) error {
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.versionedDir, key+".json")
+}
+
+// validKey reports whether key has the form of a RequestKey, so that it is
+// safe to use in a path
+// versionDirName returns the name of the directory holding the disk entries
+// of a converter version
+func versionDirName(version string) string {
+	sum := sha256.Sum256([]byte(version))
+	return hex.EncodeToString(sum[:6])
+}
+
+// isVersionDir reports whether name has the form of versionDirName
+func isVersionDir(name string) bool {
+	if len(name) != 12 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil && strings.ToLower(name) == name
+}
+
+func validKey(key string) bool {
+	if len(key) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil && strings.ToLower(key) == key
+}
+
+// CachedResult returns the cached response of the request with the given
+// RequestKey
+func CachedResult(key string) (Response, bool) {
+	cache := currentCache()
+	if cache == nil || !validKey(key) {
+		return Response{}, false
+	}
+	return cache.Get(key)
+}
+
+var (
+	cacheMu     sync.RWMutex
+	resultCache *Cache
+)
+
+// UseCache caches the results of ConvertCached in c; nil disables caching
+func UseCache(c *Cache) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	resultCache = c
+}
+
+func currentCache() *Cache {
+	cacheMu.RLock()
+	defer cacheMu.RUnlock()
+	return resultCache
+}
+
+// writeFileAtomic writes data to path through a temporary file in dir
+func writeFileAtomic(dir, path string, data []byte) error {
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// trimDir removes the least recently modified .json files of dir until the
+// rest fit in maxBytes, and returns their total size. A maxBytes of zero
+// removes nothing.
+func trimDir(dir string, maxBytes int64) (int64, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var entries []entry
+	var total int64
+	for _, d := range dirEntries {
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".json") {
+			continue
+		}
+		info, err := d.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{filepath.Join(dir, d.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	if maxBytes <= 0 {
+		return total, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+	for _, e := range entries {
+		if total <= maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return total, err
+		}
+		total -= e.size
+	}
+	return total, nil
+}
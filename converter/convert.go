@@ -0,0 +1,402 @@
+// Package converter turns HTML into htmlgo Go code. It is shared by the local
+// server and the Vercel serverless functions, so both produce identical output.
+package converter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/zhangshanwen/html2go/parse"
+
+	"html2go-converter/errcode"
+)
+
+// Direction selects what a request converts from and to
+type Direction string
+
+// Conversion directions accepted in Request.Direction
+const (
+	DirectionHTMLToGo Direction = "html2go"
+	DirectionGoToHTML Direction = "go2html"
+)
+
+// EnumValues lists the valid directions, for the OpenAPI document
+func (Direction) EnumValues() []string {
+	return []string{string(DirectionHTMLToGo), string(DirectionGoToHTML)}
+}
+
+// Valid reports whether d is a known direction
+func (d Direction) Valid() bool {
+	return d == DirectionHTMLToGo || d == DirectionGoToHTML
+}
+
+// Errors returned by Convert for requests that cannot be processed
+var (
+	ErrHTMLRequired     = errcode.New(http.StatusBadRequest, "html_required", "HTML content is required").WithField("html")
+	ErrNotImplemented   = errcode.New(http.StatusNotImplemented, "not_implemented", "Go to HTML conversion is not implemented yet").WithField("direction")
+	ErrInvalidDirection = errcode.New(http.StatusBadRequest, "invalid_direction", "Invalid conversion direction").WithField("direction")
+)
+
+// ErrorInfo returns the code, field and details a result reports for err.
+// Errors without a code of their own are reported as conversion_failed.
+func ErrorInfo(err error) errcode.Info {
+	info := errcode.Of(err)
+	if info.Code == "" {
+		info.Code, info.Status = "conversion_failed", http.StatusInternalServerError
+	}
+	return info
+}
+
+// warmUp initializes html2go before the first conversion
+var warmUp sync.Once
+
+// Request represents the JSON request body for conversion
+type Request struct {
+	HTML           string    `json:"html" required:"true" doc:"HTML document or fragment to convert"`
+	PackagePrefix  string    `json:"packagePrefix" doc:"Qualifier of htmlgo identifiers; empty for a dot import"`
+	VuetifyPrefix  string    `json:"vuetifyPrefix" doc:"Qualifier of Vuetify components; empty for a dot import"`
+	VuetifyXPrefix string    `json:"vuetifyXPrefix" doc:"Qualifier of VuetifyX components; empty for a dot import"`
+	Direction      Direction `json:"direction" default:"html2go" doc:"Conversion direction"`
+	ChildrenMode   bool      `json:"childrenMode" doc:"Generate Children(...) calls instead of nesting elements as arguments"`
+	Verify         bool      `json:"verify,omitempty" doc:"Render the generated code back to HTML and report how it differs from the input"`
+}
+
+// Response represents the JSON response for conversion
+type Response struct {
+	Code  string `json:"code,omitempty" doc:"Generated Go code"`
+	Hash  string `json:"hash,omitempty" doc:"Content address of a cached result, to fetch it again from /api/results/{hash}"`
+	HTML  string `json:"html,omitempty" doc:"Generated HTML, for go2html conversions"`
+	Error string `json:"error,omitempty" doc:"Error message of a failed conversion"`
+	// Verification is only set for requests with Verify
+	Verification *Verification `json:"verification,omitempty" doc:"Round trip of the generated code, for requests with verify"`
+}
+
+// Convert validates req and converts it according to its direction
+func Convert(req Request) (Response, error) {
+	// Validate request
+	if req.HTML == "" {
+		return Response{}, ErrHTMLRequired
+	}
+
+	// Process based on direction
+	switch req.Direction {
+	case DirectionHTMLToGo:
+		if err := CurrentLimits().CheckStructure(req.HTML); err != nil {
+			return Response{}, err
+		}
+		code, err := convertHTMLToGo(req)
+		if err != nil {
+			return Response{}, err
+		}
+		resp := Response{Code: code}
+		if req.Verify {
+			resp.Verification = Verify(req, code)
+		}
+		return resp, nil
+	case DirectionGoToHTML:
+		// Not implemented yet - might be added in a future update
+		return Response{}, ErrNotImplemented
+	default:
+		return Response{}, ErrInvalidDirection
+	}
+}
+
+// convertHTMLToGo runs HTMLToGo on req, reporting a panic of html2go as a
+// *PanicError
+func convertHTMLToGo(req Request) (code string, err error) {
+	defer recoverPanic(req, &err)
+	code, err = HTMLToGo(req.HTML, req.PackagePrefix, req.VuetifyPrefix, req.VuetifyXPrefix, req.ChildrenMode)
+	if err != nil {
+		return "", fmt.Errorf("HTML to Go conversion error: %w", err)
+	}
+	return code, nil
+}
+
+// ConvertContext runs Convert but returns ctx.Err() as soon as ctx is done,
+// and a *LimitError wrapping ErrTimeout once the conversion deadline of the
+// current Limits passes. At most Limits.MaxConcurrent conversions run at
+// once, identical concurrent requests share one conversion, and results are
+// cached as described at ConvertCached. html2go cannot be interrupted, so an
+// abandoned conversion still finishes in the background and its result is
+// discarded.
+func ConvertContext(ctx context.Context, req Request) (Response, error) {
+	resp, _, err := ConvertCached(ctx, req)
+	return resp, err
+}
+
+// ConvertCached runs ConvertContext and reports whether the response came
+// from the cache set by UseCache. Successful html2go results are cached
+// under their RequestKey, which is returned in Response.Hash.
+func ConvertCached(ctx context.Context, req Request) (Response, CacheStatus, error) {
+	return convertCached(ctx, req, convertContext)
+}
+
+// ConvertJob converts req for an asynchronous job. Jobs exist for documents
+// too large or slow for a request, so only the depth and node limits and
+// Limits.JobTimeout apply: the job workers bound the conversions running at
+// once instead of the MaxConcurrent slots. Results are cached and identical
+// concurrent conversions shared as with ConvertContext.
+func ConvertJob(ctx context.Context, req Request) (Response, error) {
+	resp, _, err := convertCached(ctx, req, convertJob)
+	return resp, err
+}
+
+// convertCached runs convert on req through the cache set by UseCache
+func convertCached(ctx context.Context, req Request, convert func(context.Context, Request) (Response, error)) (Response, CacheStatus, error) {
+	cache := currentCache()
+	if cache == nil || req.Direction != DirectionHTMLToGo {
+		resp, err := convert(ctx, req)
+		return resp, CacheBypass, err
+	}
+
+	key := RequestKey(req)
+	if resp, ok := cache.Get(key); ok {
+		return resp, CacheHit, nil
+	}
+	resp, err := convert(ctx, req)
+	if err != nil {
+		return Response{}, CacheMiss, err
+	}
+	resp.Hash = key
+	cache.Put(key, resp)
+	return resp, CacheMiss, nil
+}
+
+func convertContext(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	ctx, cancel, timeoutErr := withTimeout(ctx, CurrentLimits().Timeout)
+	defer cancel()
+
+	resp, err := runShared(ctx, req)
+	if ctx.Err() != nil && err == ctx.Err() {
+		return Response{}, timeoutErr(err)
+	}
+	return resp, err
+}
+
+func convertJob(ctx context.Context, req Request) (Response, error) {
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+	ctx, cancel, timeoutErr := withTimeout(ctx, CurrentLimits().JobTimeout)
+	defer cancel()
+
+	resp, err := conversions.do(ctx, RequestKey(req), func(context.Context) (Response, error) {
+		stats.started.Add(1)
+		return Convert(req)
+	})
+	if ctx.Err() != nil && err == ctx.Err() {
+		return Response{}, timeoutErr(err)
+	}
+	return resp, err
+}
+
+// HTMLToGo converts an HTML document into htmlgo Go code. The package
+// clause and the h.Body wrapper emitted by html2go are stripped, leaving the
+// expression for the body content.
+func HTMLToGo(htmlContent, packagePrefix, vuetifyPrefix, vuetifyXPrefix string, childrenMode bool) (string, error) {
+	// html2go lazily loads its component table on first use without
+	// synchronization, so load it once before conversions run concurrently
+	warmUp.Do(func() {
+		parse.GenerateHTMLGo("", "", "", false, strings.NewReader("<div></div>"))
+	})
+
+	// The function takes a reader, so we need to convert our string to a reader
+	reader := strings.NewReader(htmlContent)
+
+	// Using the Vuetify branch API
+	// Generate HTML Go code with support for Vuetify components
+	goCode := parse.GenerateHTMLGo(packagePrefix, vuetifyPrefix, vuetifyXPrefix, childrenMode, reader)
+
+	// Process the generated code to extract important parts
+	goCode = stripWrappers(goCode)
+
+	// Always apply the RemoveBodyWrapper for all cases
+	goCode = RemoveBodyWrapper(goCode)
+
+	return goCode, nil
+}
+
+// stripWrappers removes the package declaration and h.Body wrapper, and trims whitespace
+func stripWrappers(code string) string {
+	// 移除包声明
+	if strings.Contains(code, "package hello") {
+		// 找到包声明之后的第一个非空行
+		lines := strings.Split(code, "\n")
+		startLine := 0
+		for i, line := range lines {
+			if strings.Contains(line, "package hello") {
+				startLine = i + 1
+				break
+			}
+		}
+
+		// 跳过空行
+		for startLine < len(lines) && strings.TrimSpace(lines[startLine]) == "" {
+			startLine++
+		}
+
+		if startLine < len(lines) {
+			code = strings.Join(lines[startLine:], "\n")
+		}
+	}
+
+	// 移除 var n = 声明
+	if strings.Contains(code, "var n =") {
+		// 移除var n =行
+		lines := strings.Split(code, "\n")
+		startLine := 0
+		for i, line := range lines {
+			if strings.Contains(line, "var n =") {
+				startLine = i + 1
+				// 如果这行不包含h.Body，可能h.Body在下一行
+				if !strings.Contains(line, "h.Body(") {
+					for j := startLine; j < len(lines); j++ {
+						if strings.Contains(lines[j], "h.Body(") {
+							startLine = j + 1
+							break
+						}
+					}
+				}
+				break
+			}
+		}
+
+		if startLine > 0 && startLine < len(lines) {
+			// 拼接剩余内容
+			code = strings.Join(lines[startLine-1:], "\n")
+		}
+	}
+
+	// 尝试定位并提取h.Body的内容
+	bodyStart := strings.Index(code, "h.Body(")
+	if bodyStart >= 0 {
+		// 找到第一个左括号后的内容
+		openingIndex := bodyStart + 7
+		// 找到匹配的最后一个闭合括号
+		depth := 1
+		closingIndex := -1
+
+		for i := openingIndex; i < len(code); i++ {
+			if code[i] == '(' {
+				depth++
+			} else if code[i] == ')' {
+				depth--
+				if depth == 0 {
+					closingIndex = i
+					break
+				}
+			}
+		}
+
+		if closingIndex > openingIndex {
+			// 提取 h.Body(...) 内部的内容
+			innerCode := code[openingIndex:closingIndex]
+			code = innerCode
+		}
+	}
+
+	// 移除最后可能存在的闭合括号
+	code = removeTrailingParentheses(code)
+
+	// 移除末尾的逗号
+	code = removeTrailingCommas(code)
+
+	// 清理代码：修剪前后的空白
+	return strings.TrimSpace(code)
+}
+
+// removeTrailingParentheses 移除字符串末尾多余的闭合括号
+func removeTrailingParentheses(s string) string {
+	s = strings.TrimSpace(s)
+	// 计算开括号和闭括号数量
+	openCount := strings.Count(s, "(")
+	closeCount := strings.Count(s, ")")
+
+	// 如果闭括号比开括号多，移除末尾多余的闭括号
+	if closeCount > openCount {
+		// 从末尾开始寻找多余的闭括号
+		excess := closeCount - openCount
+		for i := 0; i < excess; i++ {
+			if strings.HasSuffix(s, ")") {
+				s = strings.TrimSuffix(s, ")")
+				s = strings.TrimSpace(s)
+			}
+		}
+	}
+	return s
+}
+
+// removeTrailingCommas 移除代码末尾的逗号
+func removeTrailingCommas(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.HasSuffix(s, ",") {
+		return strings.TrimSuffix(s, ",")
+	}
+
+	// 逐行检查，移除最后一行非空行末尾的逗号
+	lines := strings.Split(s, "\n")
+	lastNonEmptyLine := -1
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			lastNonEmptyLine = i
+			break
+		}
+	}
+
+	if lastNonEmptyLine >= 0 && strings.HasSuffix(strings.TrimSpace(lines[lastNonEmptyLine]), ",") {
+		lines[lastNonEmptyLine] = strings.TrimSuffix(strings.TrimSpace(lines[lastNonEmptyLine]), ",")
+		s = strings.Join(lines, "\n")
+	}
+
+	return s
+}
+
+// RemoveBodyWrapper removes "var n = Body(" or "var n = packagePrefix.Body(" from the beginning and ")" from the end of the code
+func RemoveBodyWrapper(code string) string {
+	// First trim any whitespace
+	code = strings.TrimSpace(code)
+
+	// Check if the code starts with "var n = Body("
+	if strings.HasPrefix(code, "var n = Body(") {
+		// Remove the prefix
+		code = code[len("var n = Body("):]
+
+		// Remove the closing parenthesis at the end if exists
+		if strings.HasSuffix(code, ")") {
+			code = code[:len(code)-1]
+		}
+
+		// Trim any whitespace again
+		code = strings.TrimSpace(code)
+	} else {
+		// Check for "var n = <packagePrefix>.Body("
+		index := strings.Index(code, "var n = ")
+		if index == 0 {
+			bodyIndex := strings.Index(code, ".Body(")
+			if bodyIndex > 0 {
+				// Get the prefix length (var n = <packagePrefix>.Body()
+				prefixLength := bodyIndex + 6 // ".Body(" is 6 characters
+
+				// Remove the prefix
+				code = code[prefixLength:]
+
+				// Remove the closing parenthesis at the end if exists
+				if strings.HasSuffix(code, ")") {
+					code = code[:len(code)-1]
+				}
+
+				// Trim any whitespace again
+				code = strings.TrimSpace(code)
+			}
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,175 @@
+package converter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RequestKey hashes the ConverterVersion and the fields of req that affect
+// the conversion. Requests with the same key produce the same response.
+func RequestKey(req Request) string {
+	normalized, _ := json.Marshal(Request{
+		HTML:           req.HTML,
+		PackagePrefix:  req.PackagePrefix,
+		VuetifyPrefix:  req.VuetifyPrefix,
+		VuetifyXPrefix: req.VuetifyXPrefix,
+		Direction:      req.Direction,
+		ChildrenMode:   req.ChildrenMode,
+		Verify:         req.Verify,
+	})
+	sum := sha256.Sum256(append([]byte(ConverterVersion()+"\n"), normalized...))
+	return hex.EncodeToString(sum[:])
+}
+
+// semaphore bounds the conversions running at once. A nil semaphore does
+// not bound them.
+type semaphore chan struct{}
+
+// acquire takes a slot, waiting at most timeout (forever if zero). It
+// returns a *LimitError wrapping ErrQueueTimeout when the wait times out.
+func (s semaphore) acquire(ctx context.Context, timeout time.Duration) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	default:
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-expired:
+		return &LimitError{Err: ErrQueueTimeout, Limit: int64(timeout)}
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+// flight is a conversion shared by every concurrent caller with the same
+// request. It is cancelled when its last caller gives up.
+type flight struct {
+	done    chan struct{}
+	resp    Response
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// flightGroup deduplicates concurrent identical conversions
+type flightGroup struct {
+	mu      sync.Mutex
+	flights map[string]*flight
+}
+
+// do returns the result of fn for key, starting fn only if no call for key
+// is in flight. fn runs in its own goroutine with a context that is done
+// once every caller waiting for it has returned early; ctx bounds the wait
+// of this caller only.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (Response, error)) (Response, error) {
+	g.mu.Lock()
+	f, ok := g.flights[key]
+	if ok {
+		f.waiters++
+		stats.shared.Add(1)
+	} else {
+		fctx, cancel := context.WithCancel(context.Background())
+		f = &flight{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		if g.flights == nil {
+			g.flights = make(map[string]*flight)
+		}
+		g.flights[key] = f
+		go func() {
+			f.resp, f.err = fn(fctx)
+			g.mu.Lock()
+			g.forget(key, f)
+			g.mu.Unlock()
+			cancel()
+			close(f.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.resp, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		if f.waiters--; f.waiters == 0 {
+			// Nobody wants the result any more; later callers start afresh
+			g.forget(key, f)
+			f.cancel()
+		}
+		g.mu.Unlock()
+		return Response{}, ctx.Err()
+	}
+}
+
+// forget removes f unless a newer flight replaced it. g.mu must be held.
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.flights[key] == f {
+		delete(g.flights, key)
+	}
+}
+
+var conversions flightGroup
+
+// Stats counts the conversions run since the process started
+type Stats struct {
+	// Started counts the conversions that ran
+	Started int64
+	// Shared counts the requests answered by a conversion already in flight
+	Shared int64
+	// QueueTimeouts counts the conversions rejected after waiting for a slot
+	QueueTimeouts int64
+}
+
+var stats struct {
+	started, shared, queueTimeouts atomic.Int64
+}
+
+// CurrentStats returns the conversion counters
+func CurrentStats() Stats {
+	return Stats{
+		Started:       stats.started.Load(),
+		Shared:        stats.shared.Load(),
+		QueueTimeouts: stats.queueTimeouts.Load(),
+	}
+}
+
+// runShared converts req, sharing the conversion with identical concurrent
+// requests and waiting for a free slot of the conversion semaphore
+func runShared(ctx context.Context, req Request) (Response, error) {
+	limits, sem := currentLimitsAndSemaphore()
+	return conversions.do(ctx, RequestKey(req), func(ctx context.Context) (Response, error) {
+		if err := sem.acquire(ctx, limits.QueueTimeout); err != nil {
+			if errors.Is(err, ErrQueueTimeout) {
+				stats.queueTimeouts.Add(1)
+			}
+			return Response{}, err
+		}
+		// Convert cannot be interrupted, so the slot is held until it returns
+		defer sem.release()
+		stats.started.Add(1)
+		return Convert(req)
+	})
+}
@@ -0,0 +1,126 @@
+package converter
+
+import "github.com/theplant/htmlgo"
+
+// The constructors of htmlgo by signature, which the renderer of Verify
+// calls by name. They mirror github.com/theplant/htmlgo/elements.go.
+var (
+	htmlgoParents = map[string]func(...htmlgo.HTMLComponent) *htmlgo.HTMLTagBuilder{
+		"A":          htmlgo.A,
+		"Address":    htmlgo.Address,
+		"Article":    htmlgo.Article,
+		"Aside":      htmlgo.Aside,
+		"Audio":      htmlgo.Audio,
+		"Blockquote": htmlgo.Blockquote,
+		"Body":       htmlgo.Body,
+		"Canvas":     htmlgo.Canvas,
+		"Cite":       htmlgo.Cite,
+		"Colgroup":   htmlgo.Colgroup,
+		"Data":       htmlgo.Data,
+		"Datalist":   htmlgo.Datalist,
+		"Dd":         htmlgo.Dd,
+		"Details":    htmlgo.Details,
+		"Dialog":     htmlgo.Dialog,
+		"Div":        htmlgo.Div,
+		"Dl":         htmlgo.Dl,
+		"Dt":         htmlgo.Dt,
+		"Fieldset":   htmlgo.Fieldset,
+		"Figure":     htmlgo.Figure,
+		"Footer":     htmlgo.Footer,
+		"Form":       htmlgo.Form,
+		"Head":       htmlgo.Head,
+		"Header":     htmlgo.Header,
+		"Hgroup":     htmlgo.Hgroup,
+		"Iframe":     htmlgo.Iframe,
+		"Ins":        htmlgo.Ins,
+		"Li":         htmlgo.Li,
+		"Main":       htmlgo.Main,
+		"Map":        htmlgo.Map,
+		"Menu":       htmlgo.Menu,
+		"Meter":      htmlgo.Meter,
+		"Nav":        htmlgo.Nav,
+		"Noscript":   htmlgo.Noscript,
+		"Ol":         htmlgo.Ol,
+		"Optgroup":   htmlgo.Optgroup,
+		"Output":     htmlgo.Output,
+		"P":          htmlgo.P,
+		"Picture":    htmlgo.Picture,
+		"Progress":   htmlgo.Progress,
+		"Ruby":       htmlgo.Ruby,
+		"Samp":       htmlgo.Samp,
+		"Section":    htmlgo.Section,
+		"Select":     htmlgo.Select,
+		"Slot":       htmlgo.Slot,
+		"Summary":    htmlgo.Summary,
+		"Table":      htmlgo.Table,
+		"Tbody":      htmlgo.Tbody,
+		"Td":         htmlgo.Td,
+		"Template":   htmlgo.Template,
+		"Tfoot":      htmlgo.Tfoot,
+		"Thead":      htmlgo.Thead,
+		"Tr":         htmlgo.Tr,
+		"Ul":         htmlgo.Ul,
+		"Video":      htmlgo.Video,
+	}
+	// htmlgoStrings take text content, or an attribute such as the src of Img
+	htmlgoStrings = map[string]func(string) *htmlgo.HTMLTagBuilder{
+		"Abbr":       htmlgo.Abbr,
+		"B":          htmlgo.B,
+		"Bdi":        htmlgo.Bdi,
+		"Bdo":        htmlgo.Bdo,
+		"Button":     htmlgo.Button,
+		"Caption":    htmlgo.Caption,
+		"Code":       htmlgo.Code,
+		"Del":        htmlgo.Del,
+		"Dfn":        htmlgo.Dfn,
+		"Em":         htmlgo.Em,
+		"Figcaption": htmlgo.Figcaption,
+		"H1":         htmlgo.H1,
+		"H2":         htmlgo.H2,
+		"H3":         htmlgo.H3,
+		"H4":         htmlgo.H4,
+		"H5":         htmlgo.H5,
+		"H6":         htmlgo.H6,
+		"I":          htmlgo.I,
+		"Img":        htmlgo.Img,
+		"Input":      htmlgo.Input,
+		"Kbd":        htmlgo.Kbd,
+		"Label":      htmlgo.Label,
+		"Legend":     htmlgo.Legend,
+		"Link":       htmlgo.Link,
+		"Mark":       htmlgo.Mark,
+		"Object":     htmlgo.Object,
+		"Option":     htmlgo.Option,
+		"Param":      htmlgo.Param,
+		"Pre":        htmlgo.Pre,
+		"Q":          htmlgo.Q,
+		"Rp":         htmlgo.Rp,
+		"Rt":         htmlgo.Rt,
+		"S":          htmlgo.S,
+		"Script":     htmlgo.Script,
+		"Small":      htmlgo.Small,
+		"Source":     htmlgo.Source,
+		"Span":       htmlgo.Span,
+		"Strong":     htmlgo.Strong,
+		"Style":      htmlgo.Style,
+		"Sub":        htmlgo.Sub,
+		"Sup":        htmlgo.Sup,
+		"Textarea":   htmlgo.Textarea,
+		"Th":         htmlgo.Th,
+		"Time":       htmlgo.Time,
+		"Title":      htmlgo.Title,
+		"Track":      htmlgo.Track,
+		"U":          htmlgo.U,
+		"Var":        htmlgo.Var,
+	}
+	htmlgoVoids = map[string]func() *htmlgo.HTMLTagBuilder{
+		"Area":  htmlgo.Area,
+		"Base":  htmlgo.Base,
+		"Br":    htmlgo.Br,
+		"Col":   htmlgo.Col,
+		"Embed": htmlgo.Embed,
+		"Hr":    htmlgo.Hr,
+		"Meta":  htmlgo.Meta,
+		"Wbr":   htmlgo.Wbr,
+	}
+)
@@ -0,0 +1,210 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"html2go-converter/errcode"
+)
+
+// Limits bound the resources a single conversion may use and the number of
+// conversions running at once. A zero field disables that limit.
+type Limits struct {
+	// MaxBodyBytes caps the size of a request body
+	MaxBodyBytes int64
+	// MaxDepth caps the nesting depth of the HTML elements
+	MaxDepth int
+	// MaxNodes caps the number of elements, text nodes and comments
+	MaxNodes int
+	// Timeout is the deadline of one conversion
+	Timeout time.Duration
+	// MaxConcurrent caps the conversions running at once; others queue
+	MaxConcurrent int
+	// QueueTimeout caps the time a conversion waits in the queue
+	QueueTimeout time.Duration
+	// JobMaxBodyBytes caps the size of an asynchronous job submission,
+	// which is meant for documents too large for MaxBodyBytes
+	JobMaxBodyBytes int64
+	// JobTimeout is the deadline of one conversion of an asynchronous job,
+	// which does not wait for the MaxConcurrent slots
+	JobTimeout time.Duration
+}
+
+// DefaultLimits are the limits used unless UseLimits is called
+var DefaultLimits = Limits{
+	MaxBodyBytes:    2 << 20,
+	MaxDepth:        256,
+	MaxNodes:        50000,
+	Timeout:         5 * time.Second,
+	MaxConcurrent:   2 * runtime.GOMAXPROCS(0),
+	QueueTimeout:    2 * time.Second,
+	JobMaxBodyBytes: 32 << 20,
+	JobTimeout:      5 * time.Minute,
+}
+
+// Errors wrapped by a *LimitError
+var (
+	ErrBodyTooLarge = errcode.New(http.StatusRequestEntityTooLarge, "body_too_large", "Request body is too large")
+	ErrTooDeep      = errcode.New(http.StatusUnprocessableEntity, "html_too_deep", "HTML is nested too deeply").WithField("html")
+	ErrTooManyNodes = errcode.New(http.StatusUnprocessableEntity, "html_too_many_nodes", "HTML has too many nodes").WithField("html")
+	ErrTimeout      = errcode.New(http.StatusServiceUnavailable, "conversion_timeout", "Conversion took too long")
+	ErrQueueTimeout = errcode.New(http.StatusServiceUnavailable, "server_busy", "Too many conversions are running; try again later")
+)
+
+// LimitError reports a request that exceeds one of the Limits. Err is one
+// of ErrBodyTooLarge, ErrTooDeep, ErrTooManyNodes, ErrTimeout and
+// ErrQueueTimeout.
+type LimitError struct {
+	Err   error
+	Limit int64
+}
+
+func (e *LimitError) Error() string {
+	if e.Err == ErrTimeout || e.Err == ErrQueueTimeout {
+		return fmt.Sprintf("%v (limit %v)", e.Err, time.Duration(e.Limit))
+	}
+	return fmt.Sprintf("%v (limit %d)", e.Err, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// Details describes the exceeded limit for error responses
+func (e *LimitError) Details() map[string]any {
+	switch e.Err {
+	case ErrBodyTooLarge:
+		return map[string]any{"maxBytes": e.Limit}
+	case ErrTooDeep:
+		return map[string]any{"maxDepth": e.Limit}
+	case ErrTooManyNodes:
+		return map[string]any{"maxNodes": e.Limit}
+	case ErrTimeout:
+		return map[string]any{"timeoutMs": time.Duration(e.Limit).Milliseconds()}
+	case ErrQueueTimeout:
+		return map[string]any{"queueTimeoutMs": time.Duration(e.Limit).Milliseconds()}
+	}
+	return nil
+}
+
+var (
+	limitsMu sync.RWMutex
+	limits   = DefaultLimits
+	slots    = newSemaphore(DefaultLimits.MaxConcurrent)
+)
+
+// UseLimits replaces the limits applied to every conversion. Conversions
+// already holding a slot keep it until they finish.
+func UseLimits(l Limits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	if l.MaxConcurrent != limits.MaxConcurrent {
+		slots = newSemaphore(l.MaxConcurrent)
+	}
+	limits = l
+}
+
+// CurrentLimits returns the limits applied to every conversion
+func CurrentLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits
+}
+
+func currentLimitsAndSemaphore() (Limits, semaphore) {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return limits, slots
+}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+// CheckStructure returns a *LimitError if document nests elements deeper
+// than l.MaxDepth or has more than l.MaxNodes nodes. It tokenizes the
+// document without building a tree and stops at the first exceeded limit.
+func (l Limits) CheckStructure(document string) error {
+	if l.MaxDepth <= 0 && l.MaxNodes <= 0 {
+		return nil
+	}
+
+	z := html.NewTokenizer(strings.NewReader(document))
+	var open []string
+	nodes := 0
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return nil
+			}
+			return z.Err()
+		case html.TextToken:
+			if len(strings.TrimSpace(string(z.Text()))) == 0 {
+				continue
+			}
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			if !voidElements[string(name)] {
+				open = append(open, string(name))
+			}
+		case html.EndTagToken:
+			// Close the innermost matching element, as a parser would
+			name, _ := z.TagName()
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == string(name) {
+					open = open[:i]
+					break
+				}
+			}
+			continue
+		case html.DoctypeToken:
+			continue
+		}
+
+		nodes++
+		if l.MaxNodes > 0 && nodes > l.MaxNodes {
+			return &LimitError{Err: ErrTooManyNodes, Limit: int64(l.MaxNodes)}
+		}
+		if l.MaxDepth > 0 && len(open) > l.MaxDepth {
+			return &LimitError{Err: ErrTooDeep, Limit: int64(l.MaxDepth)}
+		}
+	}
+}
+
+// voidElements never have content, so they do not nest
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// withTimeout returns a context bounded by a conversion deadline, and a
+// function that maps the expiry of that deadline to a *LimitError
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc, func(error) error) {
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, func(err error) error { return err }
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, func(err error) error {
+		if errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil {
+			return &LimitError{Err: ErrTimeout, Limit: int64(timeout)}
+		}
+		return err
+	}
+}
@@ -0,0 +1,148 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"html2go-converter/errcode"
+)
+
+// ErrConverterPanic is wrapped by the *PanicError returned when html2go
+// panics on an input
+var ErrConverterPanic = errcode.New(http.StatusInternalServerError, "converter_crashed", "The converter crashed on this input").WithField("html")
+
+// PanicError reports a panic of html2go. ReproductionID is derived from the
+// request, so the same input always yields the same ID and maps to the same
+// quarantine entry.
+type PanicError struct {
+	ReproductionID string
+	Value          any
+	Stack          []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v (reproduction ID %s)", ErrConverterPanic, e.ReproductionID)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrConverterPanic
+}
+
+// Details identifies the quarantined input for error responses
+func (e *PanicError) Details() map[string]any {
+	return map[string]any{"reproductionId": e.ReproductionID}
+}
+
+// ReproductionID is a short form of the RequestKey of req
+func ReproductionID(req Request) string {
+	return RequestKey(req)[:16]
+}
+
+// recoverPanic turns a panic of html2go into a *PanicError stored in *err,
+// quarantining the request. It must be deferred.
+func recoverPanic(req Request, err *error) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	panicErr := &PanicError{ReproductionID: ReproductionID(req), Value: p, Stack: debug.Stack()}
+	*err = panicErr
+	log.Printf("html2go panicked on input %s: %v\n%s", panicErr.ReproductionID, p, panicErr.Stack)
+
+	if q := currentQuarantine(); q != nil {
+		if qErr := q.Add(req, panicErr); qErr != nil {
+			log.Printf("Failed to quarantine input %s: %v", panicErr.ReproductionID, qErr)
+		}
+	}
+}
+
+// Quarantine keeps the inputs that crashed the converter in a directory,
+// one JSON file per reproduction ID, so they can be reported upstream. The
+// oldest entries are removed once the directory exceeds MaxBytes.
+type Quarantine struct {
+	Dir      string
+	MaxBytes int64
+
+	mu sync.Mutex
+}
+
+// QuarantineEntry is the content of a quarantine file
+type QuarantineEntry struct {
+	ReproductionID string    `json:"reproductionId"`
+	Panic          string    `json:"panic"`
+	Stack          string    `json:"stack"`
+	Request        Request   `json:"request"`
+	Time           time.Time `json:"time"`
+}
+
+// DefaultQuarantineBytes caps the quarantine directory unless configured
+const DefaultQuarantineBytes = 10 << 20
+
+// NewQuarantine returns a quarantine in dir, creating the directory
+func NewQuarantine(dir string, maxBytes int64) (*Quarantine, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultQuarantineBytes
+	}
+	return &Quarantine{Dir: dir, MaxBytes: maxBytes}, nil
+}
+
+// Add stores the request that caused p. An input already quarantined is
+// kept as is, and an entry larger than MaxBytes on its own is dropped.
+func (q *Quarantine) Add(req Request, p *PanicError) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	path := filepath.Join(q.Dir, p.ReproductionID+".json")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(QuarantineEntry{
+		ReproductionID: p.ReproductionID,
+		Panic:          fmt.Sprint(p.Value),
+		Stack:          string(p.Stack),
+		Request:        req,
+		Time:           time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > q.MaxBytes {
+		return fmt.Errorf("entry of %d bytes exceeds the quarantine size", len(data))
+	}
+
+	if err := writeFileAtomic(q.Dir, path, data); err != nil {
+		return err
+	}
+	_, err = trimDir(q.Dir, q.MaxBytes)
+	return err
+}
+
+var (
+	quarantineMu sync.RWMutex
+	quarantine   *Quarantine
+)
+
+// UseQuarantine stores the inputs that crash the converter in q; nil
+// disables the quarantine
+func UseQuarantine(q *Quarantine) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+	quarantine = q
+}
+
+func currentQuarantine() *Quarantine {
+	quarantineMu.RLock()
+	defer quarantineMu.RUnlock()
+	return quarantine
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"html2go-converter/errcode"
+)
+
+// Codes of FieldError, stable for clients to match on
+const (
+	FieldRequired     = "required"
+	FieldInvalidValue = "invalid_value"
+	FieldInvalidType  = "invalid_type"
+	FieldUnknown      = "unknown_field"
+	FieldTooFew       = "too_few_items"
+	FieldTooMany      = "too_many_items"
+)
+
+// FieldError reports a problem with one field of a request
+type FieldError = errcode.FieldError
+
+// ValidationError lists every invalid field of a request.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return "Invalid request: " + strings.Join(parts, "; ")
+}
+
+// Code returns the API error code of a failed validation
+func (e *ValidationError) Code() string {
+	return "validation_failed"
+}
+
+// Status returns the HTTP status of a failed validation
+func (e *ValidationError) Status() int {
+	return http.StatusBadRequest
+}
+
+// FieldErrors returns the invalid fields
+func (e *ValidationError) FieldErrors() []FieldError {
+	return e.Fields
+}
+
+// Field returns the first invalid field
+func (e *ValidationError) Field() string {
+	if len(e.Fields) == 0 {
+		return ""
+	}
+	return e.Fields[0].Field
+}
+
+// Add records an invalid field
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Err returns e if any field is invalid, and nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Validate checks every field of the request and reports all invalid ones
+func (r Request) Validate() error {
+	var errs ValidationError
+	if r.HTML == "" {
+		errs.Add("html", FieldRequired, "is required")
+	}
+	validateDirection(&errs, "direction", r.Direction)
+	return errs.Err()
+}
+
+// Validate checks the shape of a batch and the options of its items. Items
+// with empty HTML are not rejected; they fail individually when converted.
+func (b BatchRequest) Validate() error {
+	var errs ValidationError
+	switch {
+	case len(b.Items) == 0:
+		errs.Add("items", FieldTooFew, "must contain at least one item")
+	case len(b.Items) > MaxBatchItems:
+		errs.Add("items", FieldTooMany, fmt.Sprintf("must contain at most %d items", MaxBatchItems))
+	}
+	validateDirection(&errs, "defaults.direction", b.Defaults.Direction)
+	for i, item := range b.Items {
+		if item.Direction != nil {
+			validateDirection(&errs, fmt.Sprintf("items[%d].direction", i), *item.Direction)
+		}
+	}
+	return errs.Err()
+}
+
+func validateDirection(errs *ValidationError, field string, d Direction) {
+	if !d.Valid() {
+		errs.Add(field, FieldInvalidValue, "must be one of "+strings.Join(d.EnumValues(), ", "))
+	}
+}
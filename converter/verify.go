@@ -0,0 +1,368 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/theplant/htmlgo"
+	"github.com/zhangshanwen/html2go/parse"
+
+	"html2go-converter/diff"
+)
+
+// Verification is the outcome of rendering generated code back to HTML and
+// comparing it with the input
+type Verification struct {
+	Equal      bool          `json:"equal" doc:"Whether the rendered HTML matches the input structurally"`
+	HTML       string        `json:"html,omitempty" doc:"HTML rendered from the generated code"`
+	Mismatches []diff.Change `json:"mismatches" doc:"Differences from the input to the rendered HTML, located by paths such as div[0]/ul[1]/li[3]@class"`
+	Error      string        `json:"error,omitempty" doc:"Why the generated code could not be rendered"`
+}
+
+// Verify renders code, generated from req, back to HTML with RenderCode and
+// compares it with the input of req. Both documents are normalized by
+// diff.Parse, so only elements, attributes, text and their order count.
+func Verify(req Request, code string) *Verification {
+	v := &Verification{Mismatches: []diff.Change{}}
+	rendered, err := RenderCode(code, req)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+	v.HTML = rendered
+
+	changes, err := diff.HTML(req.HTML, rendered)
+	if err != nil {
+		v.Error = err.Error()
+		return v
+	}
+	if changes != nil {
+		v.Mismatches = changes
+	}
+	v.Equal = len(changes) == 0
+	return v
+}
+
+// ErrUnsupportedCode is returned by RenderCode for code it cannot evaluate
+var ErrUnsupportedCode = errors.New("Generated code cannot be rendered")
+
+// bodyArguments returns the components of code generated by HTMLToGo as a
+// list of arguments without a trailing comma. HTMLToGo leaves the arguments
+// of the body: the last one may lack its comma, and in children mode they
+// follow what remains of Body().
+func bodyArguments(code string) string {
+	code = strings.TrimSpace(code)
+	code = strings.TrimSpace(strings.TrimPrefix(code, ").Children("))
+	return strings.TrimSuffix(code, ",")
+}
+
+// RenderCode renders the HTML that code, as generated by HTMLToGo with the
+// prefixes of req, produces at runtime. htmlgo calls are evaluated with
+// htmlgo itself. Vuetify and VuetifyX are not dependencies of this module,
+// so their components render as the elements and attributes that html2go's
+// component table maps them from.
+func RenderCode(code string, req Request) (html string, err error) {
+	expr, err := parser.ParseExpr("[]any{\n" + bodyArguments(code) + ",\n}")
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnsupportedCode, err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v", ErrUnsupportedCode, p)
+		}
+	}()
+
+	r := renderer{req: req}
+	var root htmlgo.HTMLComponents
+	for _, elt := range expr.(*ast.CompositeLit).Elts {
+		c, err := r.component(elt)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrUnsupportedCode, err)
+		}
+		root = append(root, c)
+	}
+	b, err := root.MarshalHTML(context.Background())
+	return string(b), err
+}
+
+// component is a Vuetify or VuetifyX component of html2go's table
+type component struct {
+	tag    string
+	accept string
+	// attrs maps methods to their attribute and the type they accept
+	attrs map[string]parse.AttributeDefinition
+}
+
+var (
+	// components maps the package type, "vuetify" or "vuetifyx", and the
+	// Go name of a component to its definition
+	components     map[string]map[string]component
+	componentsErr  error
+	componentsOnce sync.Once
+)
+
+func loadComponents() (map[string]map[string]component, error) {
+	componentsOnce.Do(func() {
+		var defs map[string]parse.ComponentDefinition
+		if defs, componentsErr = parse.ParseComponentData(); componentsErr != nil {
+			return
+		}
+		components = make(map[string]map[string]component)
+		for tag, def := range defs {
+			c := component{tag: tag, accept: def.Accept, attrs: make(map[string]parse.AttributeDefinition)}
+			for name, attr := range def.Attrs {
+				c.attrs[attr.Go] = parse.AttributeDefinition{Go: name, Accept: attr.Accept}
+			}
+			if components[def.Type] == nil {
+				components[def.Type] = make(map[string]component)
+			}
+			components[def.Type][def.Go] = c
+		}
+	})
+	return components, componentsErr
+}
+
+// renderer evaluates generated code with the package prefixes of req
+type renderer struct {
+	req Request
+}
+
+// element is an element under construction: an htmlgo builder, and the
+// definition of its component for Vuetify and VuetifyX components
+type element struct {
+	tag       *htmlgo.HTMLTagBuilder
+	component *component
+}
+
+// component evaluates an expression producing an HTML component
+func (r renderer) component(expr ast.Expr) (htmlgo.HTMLComponent, error) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, fmt.Errorf("unsupported expression %T", expr)
+	}
+	// Text is the only constructor whose result is not a builder
+	if qualifier, name := calledFunc(call); name == "Text" && qualifier == r.req.PackagePrefix {
+		text, err := stringArg(name, call.Args)
+		if err != nil {
+			return nil, err
+		}
+		return htmlgo.Text(text), nil
+	}
+	e, err := r.element(call)
+	if err != nil {
+		return nil, err
+	}
+	return e.tag, nil
+}
+
+// element evaluates a constructor call and the methods chained to it
+func (r renderer) element(call *ast.CallExpr) (*element, error) {
+	// A method call: the receiver is the chain so far
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		if inner, ok := sel.X.(*ast.CallExpr); ok {
+			e, err := r.element(inner)
+			if err != nil {
+				return nil, err
+			}
+			return e, r.method(e, sel.Sel.Name, call.Args)
+		}
+	}
+
+	qualifier, name := calledFunc(call)
+	if name == "" {
+		return nil, fmt.Errorf("unsupported call of %T", call.Fun)
+	}
+	qualified := name
+	if qualifier != "" {
+		qualified = qualifier + "." + name
+	}
+
+	// Prefixes may be equal, e.g. all empty with dot imports
+	if qualifier == r.req.PackagePrefix {
+		if e, ok, err := r.htmlgoElement(name, call.Args); ok {
+			return e, err
+		}
+	}
+	for _, pkg := range []struct{ prefix, kind string }{
+		{r.req.VuetifyPrefix, "vuetify"},
+		{r.req.VuetifyXPrefix, "vuetifyx"},
+	} {
+		if qualifier != pkg.prefix {
+			continue
+		}
+		all, err := loadComponents()
+		if err != nil {
+			return nil, err
+		}
+		if c, ok := all[pkg.kind][name]; ok {
+			return r.componentElement(qualified, &c, call.Args)
+		}
+	}
+	return nil, fmt.Errorf("unknown function %s", qualified)
+}
+
+// htmlgoElement calls the htmlgo constructor name, reporting whether it
+// exists
+func (r renderer) htmlgoElement(name string, args []ast.Expr) (*element, bool, error) {
+	if name == "Tag" {
+		tag, err := stringArg(name, args)
+		return &element{tag: htmlgo.Tag(tag)}, true, err
+	}
+	if f, ok := htmlgoStrings[name]; ok {
+		s, err := stringArg(name, args)
+		return &element{tag: f(s)}, true, err
+	}
+	if f, ok := htmlgoVoids[name]; ok {
+		if len(args) > 0 {
+			return nil, true, fmt.Errorf("%s takes no arguments", name)
+		}
+		return &element{tag: f()}, true, nil
+	}
+	if f, ok := htmlgoParents[name]; ok {
+		children, err := r.children(args)
+		return &element{tag: f(children...)}, true, err
+	}
+	return nil, false, nil
+}
+
+// componentElement builds the element of a Vuetify or VuetifyX component
+func (r renderer) componentElement(name string, c *component, args []ast.Expr) (*element, error) {
+	if c.accept == "none" && len(args) > 0 {
+		return nil, fmt.Errorf("%s takes no children", name)
+	}
+	children, err := r.children(args)
+	if err != nil {
+		return nil, err
+	}
+	return &element{tag: htmlgo.Tag(c.tag).Children(children...), component: c}, nil
+}
+
+// children evaluates arguments that are components or text
+func (r renderer) children(args []ast.Expr) ([]htmlgo.HTMLComponent, error) {
+	children := make([]htmlgo.HTMLComponent, 0, len(args))
+	for _, arg := range args {
+		if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			text, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, htmlgo.Text(text))
+			continue
+		}
+		c, err := r.component(arg)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, c)
+	}
+	return children, nil
+}
+
+// method applies the method name to e
+func (r renderer) method(e *element, name string, args []ast.Expr) error {
+	// Component methods set the attribute of the table; the builders of
+	// every component also have the generic methods of htmlgo
+	if e.component != nil {
+		if attr, ok := e.component.attrs[name]; ok {
+			if len(args) != 1 {
+				return fmt.Errorf("%s takes one argument", name)
+			}
+			value, err := r.value(args[0])
+			if err != nil {
+				return err
+			}
+			e.tag.Attr(attr.Go, value)
+			return nil
+		}
+		switch name {
+		case "Attr", "Children", "Class", "Style":
+		default:
+			return fmt.Errorf("unknown method %s of <%s>", name, e.component.tag)
+		}
+	}
+
+	m := reflect.ValueOf(e.tag).MethodByName(name)
+	if !m.IsValid() {
+		return fmt.Errorf("unknown method %s", name)
+	}
+	t := m.Type()
+	if !t.IsVariadic() && len(args) != t.NumIn() || t.IsVariadic() && len(args) < t.NumIn()-1 {
+		return fmt.Errorf("%s takes %d arguments, not %d", name, t.NumIn(), len(args))
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		param := t.In(min(i, t.NumIn()-1))
+		if t.IsVariadic() && i >= t.NumIn()-1 {
+			param = param.Elem()
+		}
+		value, err := r.value(arg)
+		if err != nil {
+			return err
+		}
+		v := reflect.ValueOf(value)
+		if !v.Type().AssignableTo(param) {
+			if !v.Type().ConvertibleTo(param) || v.Kind() != param.Kind() {
+				return fmt.Errorf("argument %d of %s is %s, not %s", i+1, name, v.Type(), param)
+			}
+			v = v.Convert(param)
+		}
+		in[i] = v
+	}
+	m.Call(in)
+	return nil
+}
+
+// value evaluates a literal or component argument
+func (r renderer) value(expr ast.Expr) (any, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			return strconv.Unquote(e.Value)
+		case token.INT:
+			return strconv.Atoi(e.Value)
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	case *ast.CallExpr:
+		return r.component(e)
+	}
+	return nil, fmt.Errorf("unsupported argument %T", expr)
+}
+
+// calledFunc returns the package qualifier and name of a function call, or
+// an empty name for a call of anything else
+func calledFunc(call *ast.CallExpr) (qualifier, name string) {
+	switch f := call.Fun.(type) {
+	case *ast.Ident:
+		return "", f.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := f.X.(*ast.Ident); ok {
+			return pkg.Name, f.Sel.Name
+		}
+	}
+	return "", ""
+}
+
+// stringArg returns the single string literal argument of the call name
+func stringArg(name string, args []ast.Expr) (string, error) {
+	if len(args) == 1 {
+		if lit, ok := args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			return strconv.Unquote(lit.Value)
+		}
+	}
+	return "", fmt.Errorf("%s takes one string literal", name)
+}
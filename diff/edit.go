@@ -0,0 +1,140 @@
+// Package diff compares conversion inputs and outputs: HTML structurally,
+// node by node, and generated code line by line.
+package diff
+
+// Op is the kind of a difference
+type Op string
+
+// Ops of lines and changes
+const (
+	OpEqual   Op = "equal"
+	OpAdded   Op = "added"
+	OpRemoved Op = "removed"
+	OpChanged Op = "changed"
+)
+
+// edit is one step of an edit script: an equal pair (i, j), the removal of
+// old element i, or the addition of new element j
+type edit struct {
+	op   Op
+	i, j int
+}
+
+// maxEditCost bounds the work and memory of script, which grow with the
+// square of the number of edits. Sequences differing by more edits are
+// treated as entirely replaced between their common prefix and suffix.
+const maxEditCost = 1024
+
+// script returns a shortest edit script turning an old sequence of n
+// elements into a new one of m elements, using the algorithm of Myers'
+// "An O(ND) Difference Algorithm and Its Variations"
+func script(n, m int, equal func(i, j int) bool) []edit {
+	// Trim the common prefix and suffix, which are cheap to find
+	prefix := 0
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+
+	var edits []edit
+	for k := 0; k < prefix; k++ {
+		edits = append(edits, edit{OpEqual, k, k})
+	}
+	edits = append(edits, middle(prefix, n-suffix, prefix, m-suffix, equal)...)
+	for k := suffix; k > 0; k-- {
+		edits = append(edits, edit{OpEqual, n - k, m - k})
+	}
+	return edits
+}
+
+// middle returns the edit script of old[lo1:hi1] and new[lo2:hi2]
+func middle(lo1, hi1, lo2, hi2 int, equal func(i, j int) bool) []edit {
+	n, m := hi1-lo1, hi2-lo2
+	eq := func(x, y int) bool { return equal(lo1+x, lo2+y) }
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds v[-d..d] before step d
+	var trace [][]int
+	for d := 0; d <= max; d++ {
+		if d > maxEditCost {
+			return replaceAll(lo1, hi1, lo2, hi2)
+		}
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(x, y) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m, lo1, lo2)
+			}
+		}
+	}
+	return nil
+}
+
+// backtrack walks trace back from (n, m) and returns the edits in order
+func backtrack(trace [][]int, n, m, lo1, lo2 int) []edit {
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{OpEqual, lo1 + x, lo2 + y})
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{OpAdded, -1, lo2 + y})
+		} else {
+			x--
+			edits = append(edits, edit{OpRemoved, lo1 + x, -1})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		edits = append(edits, edit{OpEqual, lo1 + x, lo2 + y})
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// replaceAll removes old[lo1:hi1] and adds new[lo2:hi2]
+func replaceAll(lo1, hi1, lo2, hi2 int) []edit {
+	var edits []edit
+	for i := lo1; i < hi1; i++ {
+		edits = append(edits, edit{OpRemoved, i, -1})
+	}
+	for j := lo2; j < hi2; j++ {
+		edits = append(edits, edit{OpAdded, -1, j})
+	}
+	return edits
+}
@@ -0,0 +1,207 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Change is a difference between two HTML documents. Path locates the node
+// as a chain of tag[index] steps, the index counting the element and text
+// children of the parent, e.g. "div[0]/ul[1]/li[3]"; text nodes are
+// "#text[i]" and attributes add "@name", e.g. "div[0]/ul[1]/li[3]@class".
+// Removed nodes are located in the old document, others in the new one.
+type Change struct {
+	Op   Op     `json:"op"`
+	Path string `json:"path"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Op {
+	case OpAdded:
+		return fmt.Sprintf("%s added %q", c.Path, c.New)
+	case OpRemoved:
+		return fmt.Sprintf("%s removed %q", c.Path, c.Old)
+	}
+	return fmt.Sprintf("%s changed from %q to %q", c.Path, c.Old, c.New)
+}
+
+// Node is an element or a text node of a parsed HTML fragment. Comments and
+// whitespace-only text are dropped, and text is trimmed, so that formatting
+// does not count as a difference.
+type Node struct {
+	// Tag is the element name, or "#text"
+	Tag      string
+	Attrs    map[string]string
+	Text     string
+	Children []*Node
+}
+
+// Parse parses document as an HTML fragment in the body of a page and
+// returns a root node holding its top-level nodes
+func Parse(document string) (*Node, error) {
+	context := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(document), context)
+	if err != nil {
+		return nil, err
+	}
+	root := &Node{}
+	for _, n := range nodes {
+		root.Children = appendNode(root.Children, n)
+	}
+	return root, nil
+}
+
+// appendNode appends the Node of n to nodes, unless n is insignificant
+func appendNode(nodes []*Node, n *html.Node) []*Node {
+	switch n.Type {
+	case html.TextNode:
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			return nodes
+		}
+		return append(nodes, &Node{Tag: "#text", Text: text})
+	case html.ElementNode:
+		node := &Node{Tag: n.Data, Attrs: make(map[string]string, len(n.Attr))}
+		for _, a := range n.Attr {
+			name := a.Key
+			if a.Namespace != "" {
+				name = a.Namespace + ":" + a.Key
+			}
+			node.Attrs[name] = a.Val
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			node.Children = appendNode(node.Children, c)
+		}
+		return append(nodes, node)
+	}
+	return nodes
+}
+
+// HTML compares two HTML fragments structurally and returns their
+// differences in document order
+func HTML(old, new string) ([]Change, error) {
+	a, err := Parse(old)
+	if err != nil {
+		return nil, err
+	}
+	b, err := Parse(new)
+	if err != nil {
+		return nil, err
+	}
+	return Trees(a, b), nil
+}
+
+// Trees returns the differences between two parsed fragments
+func Trees(old, new *Node) []Change {
+	var changes []Change
+	compareChildren(&changes, "", old, new)
+	return changes
+}
+
+// compareNodes appends the differences of two nodes with the same tag
+func compareNodes(changes *[]Change, path string, a, b *Node) {
+	if a.Tag == "#text" {
+		if a.Text != b.Text {
+			*changes = append(*changes, Change{Op: OpChanged, Path: path, Old: a.Text, New: b.Text})
+		}
+		return
+	}
+
+	names := make([]string, 0, len(a.Attrs)+len(b.Attrs))
+	for name := range a.Attrs {
+		names = append(names, name)
+	}
+	for name := range b.Attrs {
+		if _, ok := a.Attrs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldVal, inOld := a.Attrs[name]
+		newVal, inNew := b.Attrs[name]
+		attrPath := path + "@" + name
+		switch {
+		case !inNew:
+			*changes = append(*changes, Change{Op: OpRemoved, Path: attrPath, Old: oldVal})
+		case !inOld:
+			*changes = append(*changes, Change{Op: OpAdded, Path: attrPath, New: newVal})
+		case oldVal != newVal:
+			*changes = append(*changes, Change{Op: OpChanged, Path: attrPath, Old: oldVal, New: newVal})
+		}
+	}
+
+	compareChildren(changes, path, a, b)
+}
+
+// compareChildren aligns the children of a and b by tag and compares them
+func compareChildren(changes *[]Change, path string, a, b *Node) {
+	edits := script(len(a.Children), len(b.Children), func(i, j int) bool {
+		return a.Children[i].Tag == b.Children[j].Tag
+	})
+	for _, e := range edits {
+		switch e.op {
+		case OpEqual:
+			compareNodes(changes, childPath(path, b.Children[e.j], e.j), a.Children[e.i], b.Children[e.j])
+		case OpRemoved:
+			n := a.Children[e.i]
+			*changes = append(*changes, Change{Op: OpRemoved, Path: childPath(path, n, e.i), Old: n.String()})
+		case OpAdded:
+			n := b.Children[e.j]
+			*changes = append(*changes, Change{Op: OpAdded, Path: childPath(path, n, e.j), New: n.String()})
+		}
+	}
+}
+
+func childPath(parent string, n *Node, index int) string {
+	step := fmt.Sprintf("%s[%d]", n.Tag, index)
+	if parent == "" {
+		return step
+	}
+	return parent + "/" + step
+}
+
+// String renders n as compact HTML
+func (n *Node) String() string {
+	var b strings.Builder
+	n.render(&b)
+	return b.String()
+}
+
+func (n *Node) render(b *strings.Builder) {
+	if n.Tag == "#text" {
+		b.WriteString(html.EscapeString(n.Text))
+		return
+	}
+	if n.Tag != "" {
+		b.WriteString("<" + n.Tag)
+		names := make([]string, 0, len(n.Attrs))
+		for name := range n.Attrs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(b, ` %s="%s"`, name, html.EscapeString(n.Attrs[name]))
+		}
+		b.WriteString(">")
+	}
+	for _, c := range n.Children {
+		c.render(b)
+	}
+	if n.Tag != "" && !voidElements[n.Tag] {
+		b.WriteString("</" + n.Tag + ">")
+	}
+}
+
+// voidElements never have content or an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Line is a line of a line-based diff. OldLine and NewLine are 1-based
+// line numbers, zero for a line missing on that side.
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// Lines compares old and new line by line
+func Lines(old, new string) []Line {
+	a, b := splitLines(old), splitLines(new)
+	edits := script(len(a), len(b), func(i, j int) bool { return a[i] == b[j] })
+	lines := make([]Line, 0, len(edits))
+	for _, e := range edits {
+		switch e.op {
+		case OpEqual:
+			lines = append(lines, Line{Op: OpEqual, Text: a[e.i], OldLine: e.i + 1, NewLine: e.j + 1})
+		case OpRemoved:
+			lines = append(lines, Line{Op: OpRemoved, Text: a[e.i], OldLine: e.i + 1})
+		case OpAdded:
+			lines = append(lines, Line{Op: OpAdded, Text: b[e.j], NewLine: e.j + 1})
+		}
+	}
+	return lines
+}
+
+// Count returns the number of added and removed lines
+func Count(lines []Line) (added, removed int) {
+	for _, l := range lines {
+		switch l.Op {
+		case OpAdded:
+			added++
+		case OpRemoved:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Unified formats lines as a unified diff between the files oldName and
+// newName, with context unchanged lines around each hunk. It returns the
+// empty string when nothing changed.
+func Unified(oldName, newName string, lines []Line, context int) string {
+	var b strings.Builder
+	for start := 0; start < len(lines); {
+		// Find the next change and the end of its hunk
+		first := start
+		for first < len(lines) && lines[first].Op == OpEqual {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		lo := max(first-context, start)
+		hi := first
+		for i := first; i < len(lines) && i-hi <= 2*context; i++ {
+			if lines[i].Op != OpEqual {
+				hi = i + 1
+			}
+		}
+		hi = min(hi+context, len(lines))
+
+		if b.Len() == 0 {
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&b, lines, lo, hi)
+		start = hi
+	}
+	return b.String()
+}
+
+// writeHunk writes the hunk lines[lo:hi] with its header
+func writeHunk(b *strings.Builder, lines []Line, lo, hi int) {
+	// Lines before the hunk give its start, even when one side is empty
+	oldStart, newStart := 0, 0
+	for _, l := range lines[:lo] {
+		if l.OldLine > 0 {
+			oldStart = l.OldLine
+		}
+		if l.NewLine > 0 {
+			newStart = l.NewLine
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, l := range lines[lo:hi] {
+		if l.OldLine > 0 {
+			oldCount++
+		}
+		if l.NewLine > 0 {
+			newCount++
+		}
+	}
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, l := range lines[lo:hi] {
+		switch l.Op {
+		case OpEqual:
+			b.WriteString(" ")
+		case OpRemoved:
+			b.WriteString("-")
+		case OpAdded:
+			b.WriteString("+")
+		}
+		b.WriteString(l.Text)
+		b.WriteString("\n")
+	}
+}
+
+// hunkRange formats the range of a hunk header
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines splits s into lines without their terminators
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
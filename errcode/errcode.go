@@ -0,0 +1,123 @@
+// Package errcode lets the domain packages give their errors the status and
+// stable code of an API error response without depending on the HTTP layer.
+// The apierror package finds them in an error chain with errors.As through
+// the interfaces below, so it depends on none of the packages whose errors
+// it reports.
+package errcode
+
+import "errors"
+
+// Coded is implemented by errors that map to an API error: Code is one of
+// the codes listed by apierror.Titles and Status the HTTP status
+type Coded interface {
+	error
+	Code() string
+	Status() int
+}
+
+// Fielded is implemented by errors about one field of a request, which
+// Field returns as a JSON path
+type Fielded interface {
+	Field() string
+}
+
+// Detailed is implemented by errors with code-specific data, such as the
+// limit that was exceeded
+type Detailed interface {
+	Details() map[string]any
+}
+
+// Invalid is implemented by errors listing every invalid field of a request
+type Invalid interface {
+	FieldErrors() []FieldError
+}
+
+// FieldError reports a problem with one field of a request. Field is the
+// JSON path of the field, e.g. "items[2].direction".
+type FieldError struct {
+	Field   string `json:"field" doc:"JSON path of the invalid field"`
+	Code    string `json:"code" doc:"Stable code of the problem: required, invalid_value, invalid_type, unknown_field, too_few_items or too_many_items"`
+	Message string `json:"message" doc:"What is wrong with the field"`
+}
+
+// Info describes a coded error, for results that report a failure in their
+// body rather than as an error response
+type Info struct {
+	Code    string
+	Status  int
+	Field   string
+	Details map[string]any
+}
+
+// Of returns the code, status, field and details found in the chain of err
+// with errors.As. They are empty if err has no code.
+func Of(err error) Info {
+	var info Info
+	var coded Coded
+	if !errors.As(err, &coded) {
+		return info
+	}
+	info.Code, info.Status = coded.Code(), coded.Status()
+	var fielded Fielded
+	if errors.As(err, &fielded) {
+		info.Field = fielded.Field()
+	}
+	var detailed Detailed
+	if errors.As(err, &detailed) {
+		info.Details = detailed.Details()
+	}
+	return info
+}
+
+// Error is a sentinel error with the status and code of its API error.
+// Compare with errors.Is as with any sentinel error.
+type Error struct {
+	status  int
+	code    string
+	message string
+	field   string
+	details map[string]any
+}
+
+// New returns an error with the given status, code and message
+func New(status int, code, message string) *Error {
+	return &Error{status: status, code: code, message: message}
+}
+
+// WithField sets the field the error is about and returns e. It is meant
+// for the declaration of e.
+func (e *Error) WithField(field string) *Error {
+	e.field = field
+	return e
+}
+
+// WithDetails sets the code-specific data of e and returns e. It is meant
+// for the declaration of e.
+func (e *Error) WithDetails(details map[string]any) *Error {
+	e.details = details
+	return e
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Code returns the API error code of e
+func (e *Error) Code() string {
+	return e.code
+}
+
+// Status returns the HTTP status of e
+func (e *Error) Status() int {
+	return e.status
+}
+
+// Field returns the JSON path of the field e is about, or ""
+func (e *Error) Field() string {
+	return e.field
+}
+
+// Details returns the code-specific data of e, or nil
+func (e *Error) Details() map[string]any {
+	return e.details
+}
@@ -0,0 +1,304 @@
+// Package examples is the registry of the example gallery. Each example is
+// an HTML file whose leading comment annotates it, one "key: value" per line:
+//
+//	<!--
+//	title: VuetifyX Dialog
+//	description: VuetifyX对话框组件示例
+//	category: vuetifyx
+//	tags: dialog, form
+//	vuetifyPrefix: v
+//	vuetifyXPrefix: vx
+//	childrenMode: false
+//	-->
+//	<vx-dialog title="确认">...</vx-dialog>
+//
+// title and category are required. Options left out take the defaults of
+// the web UI. The ID of an example is its file name without the extension
+// and without a leading "NN-" that orders the gallery. Every example is
+// validated and converted when the registry is loaded, so a broken example
+// stops the server from starting instead of failing in the browser.
+package examples
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"html2go-converter/converter"
+	"html2go-converter/errcode"
+)
+
+// gallery holds the examples built into the binary
+//
+//go:embed gallery/*.html
+var gallery embed.FS
+
+// Embedded returns the examples built into the binary
+func Embedded() fs.FS {
+	sub, _ := fs.Sub(gallery, "gallery")
+	return sub
+}
+
+// ErrNotFound is returned for an unknown example ID
+var ErrNotFound = errcode.New(http.StatusNotFound, "example_not_found", "Example not found")
+
+// DefaultOptions are the options of examples that do not set them, the
+// same as the defaults of the web UI
+var DefaultOptions = converter.Request{
+	PackagePrefix:  "h",
+	VuetifyPrefix:  "v",
+	VuetifyXPrefix: "vx",
+	Direction:      converter.DirectionHTMLToGo,
+}
+
+// Summary describes an example in the gallery listing
+type Summary struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Category    string   `json:"category"`
+	Tags        []string `json:"tags"`
+}
+
+// Options are the recommended conversion options of an example
+type Options struct {
+	PackagePrefix  string `json:"packagePrefix"`
+	VuetifyPrefix  string `json:"vuetifyPrefix"`
+	VuetifyXPrefix string `json:"vuetifyXPrefix"`
+	ChildrenMode   bool   `json:"childrenMode"`
+}
+
+// Example is an example with its HTML and the code it converts to
+type Example struct {
+	Summary
+	Options Options `json:"options"`
+	HTML    string  `json:"html"`
+	Code    string  `json:"code"`
+}
+
+// Request returns the conversion request of the example
+func (e Example) Request() converter.Request {
+	return converter.Request{
+		HTML:           e.HTML,
+		PackagePrefix:  e.Options.PackagePrefix,
+		VuetifyPrefix:  e.Options.VuetifyPrefix,
+		VuetifyXPrefix: e.Options.VuetifyXPrefix,
+		Direction:      converter.DirectionHTMLToGo,
+		ChildrenMode:   e.Options.ChildrenMode,
+	}
+}
+
+// Filter selects examples. Empty fields match every example.
+type Filter struct {
+	Category string
+	Tag      string
+	// Query matches the title, description and tags, ignoring case
+	Query string
+}
+
+func (f Filter) matches(e *Example) bool {
+	if f.Category != "" && !strings.EqualFold(f.Category, e.Category) {
+		return false
+	}
+	if f.Tag != "" && !slices.ContainsFunc(e.Tags, func(t string) bool { return strings.EqualFold(t, f.Tag) }) {
+		return false
+	}
+	if f.Query != "" {
+		text := strings.ToLower(e.Title + "\n" + e.Description + "\n" + strings.Join(e.Tags, "\n"))
+		return strings.Contains(text, strings.ToLower(f.Query))
+	}
+	return true
+}
+
+// Registry holds the loaded examples in gallery order
+type Registry struct {
+	examples []*Example
+	byID     map[string]*Example
+}
+
+// List returns the summaries of the examples matching f
+func (r *Registry) List(f Filter) []Summary {
+	summaries := []Summary{}
+	for _, e := range r.examples {
+		if f.matches(e) {
+			summaries = append(summaries, e.Summary)
+		}
+	}
+	return summaries
+}
+
+// Get returns the example with the given ID, or ErrNotFound
+func (r *Registry) Get(id string) (Example, error) {
+	e, ok := r.byID[id]
+	if !ok {
+		return Example{}, ErrNotFound
+	}
+	return *e, nil
+}
+
+// Categories returns the categories of the examples, sorted
+func (r *Registry) Categories() []string {
+	return r.collect(func(e *Example) []string { return []string{e.Category} })
+}
+
+// Tags returns the tags of the examples, sorted
+func (r *Registry) Tags() []string {
+	return r.collect(func(e *Example) []string { return e.Tags })
+}
+
+func (r *Registry) collect(values func(*Example) []string) []string {
+	seen := map[string]bool{}
+	all := []string{}
+	for _, e := range r.examples {
+		for _, v := range values(e) {
+			if !seen[v] {
+				seen[v] = true
+				all = append(all, v)
+			}
+		}
+	}
+	sort.Strings(all)
+	return all
+}
+
+// orderPrefix is the optional "NN-" of file names
+var orderPrefix = regexp.MustCompile(`^[0-9]+-`)
+
+// validID restricts IDs to what is safe in a URL path
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Load reads, validates and converts every .html file of fsys. It reports
+// every invalid example, not just the first.
+func Load(fsys fs.FS) (*Registry, error) {
+	names, err := fs.Glob(fsys, "*.html")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	r := &Registry{byID: make(map[string]*Example)}
+	var errs []error
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		e, err := parse(name, string(data))
+		if err == nil {
+			err = convert(e)
+		}
+		if err == nil && r.byID[e.ID] != nil {
+			err = fmt.Errorf("duplicate ID %q", e.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("example %s: %w", name, err))
+			continue
+		}
+		r.examples = append(r.examples, e)
+		r.byID[e.ID] = e
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parse reads the annotations and HTML of the example file name
+func parse(name, content string) (*Example, error) {
+	id := orderPrefix.ReplaceAllString(strings.TrimSuffix(path.Base(name), ".html"), "")
+	if !validID.MatchString(id) {
+		return nil, fmt.Errorf("ID %q must be lowercase letters, digits and dashes", id)
+	}
+
+	content = strings.TrimLeft(content, " \t\r\n")
+	header, ok := strings.CutPrefix(content, "<!--")
+	if !ok {
+		return nil, errors.New("missing annotation comment")
+	}
+	header, body, ok := strings.Cut(header, "-->")
+	if !ok {
+		return nil, errors.New("unterminated annotation comment")
+	}
+
+	e := &Example{
+		Summary: Summary{ID: id, Tags: []string{}},
+		Options: Options{
+			PackagePrefix:  DefaultOptions.PackagePrefix,
+			VuetifyPrefix:  DefaultOptions.VuetifyPrefix,
+			VuetifyXPrefix: DefaultOptions.VuetifyXPrefix,
+			ChildrenMode:   DefaultOptions.ChildrenMode,
+		},
+		HTML: strings.TrimSpace(body) + "\n",
+	}
+	for i, line := range strings.Split(header, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("annotation line %d: want \"key: value\"", i+1)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "title":
+			e.Title = value
+		case "description":
+			e.Description = value
+		case "category":
+			e.Category = value
+		case "tags":
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					e.Tags = append(e.Tags, tag)
+				}
+			}
+		case "packagePrefix":
+			e.Options.PackagePrefix = value
+		case "vuetifyPrefix":
+			e.Options.VuetifyPrefix = value
+		case "vuetifyXPrefix":
+			e.Options.VuetifyXPrefix = value
+		case "childrenMode":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("childrenMode %q is not a boolean", value)
+			}
+			e.Options.ChildrenMode = b
+		default:
+			return nil, fmt.Errorf("unknown annotation %q", strings.TrimSpace(key))
+		}
+	}
+
+	switch {
+	case e.Title == "":
+		return nil, errors.New("title is required")
+	case e.Category == "":
+		return nil, errors.New("category is required")
+	case strings.TrimSpace(body) == "":
+		return nil, errors.New("HTML is empty")
+	}
+	return e, nil
+}
+
+// convert fills in the code of e
+func convert(e *Example) error {
+	resp, err := converter.Convert(e.Request())
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(resp.Code) == "" {
+		return errors.New("converts to no code")
+	}
+	e.Code = resp.Code
+	return nil
+}
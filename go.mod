@@ -2,10 +2,13 @@ module html2go-converter
 
 go 1.22.5
 
-require github.com/zhangshanwen/html2go v0.0.0-20250327041724-2dd21bb1077b
-
 require (
-	github.com/iancoleman/strcase v0.3.0 // indirect
-	github.com/theplant/htmlgo v1.0.3 // indirect
-	golang.org/x/net v0.35.0 // indirect
+	github.com/andybalholm/brotli v1.2.6
+	github.com/iancoleman/strcase v0.3.0
+	github.com/theplant/htmlgo v1.0.3
+	github.com/zhangshanwen/html2go v0.0.0-20250327041724-2dd21bb1077b
+	go.etcd.io/bbolt v1.3.11
+	golang.org/x/net v0.35.0
 )
+
+require golang.org/x/sys v0.30.0 // indirect
@@ -0,0 +1,213 @@
+// Package history keeps the revisions of conversions. Every save of an
+// input and its options appends an immutable revision to a history; the
+// last revision is the head. Restoring an older revision appends a copy of
+// it, so no revision is ever changed or lost.
+package history
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/diff"
+	"html2go-converter/errcode"
+)
+
+// Errors returned for history operations
+var (
+	ErrNotFound         = errcode.New(http.StatusNotFound, "history_not_found", "History not found")
+	ErrRevisionNotFound = errcode.New(http.StatusNotFound, "revision_not_found", "Revision not found")
+	ErrExists           = errors.New("History ID already exists")
+	ErrHistoryFull      = errcode.New(http.StatusConflict, "history_full", "History has too many revisions")
+)
+
+// Policy bounds what a store keeps. Zero values disable a bound.
+type Policy struct {
+	// TTL is how long a history is kept after its last revision
+	TTL time.Duration
+	// MaxHistories caps the number of histories; Sweep removes the least
+	// recently saved ones beyond it
+	MaxHistories int
+	// MaxRevisions caps the revisions of one history; saving more fails
+	// with ErrHistoryFull
+	MaxRevisions int
+}
+
+// DefaultPolicy keeps histories for 30 days after their last save
+var DefaultPolicy = Policy{TTL: 30 * 24 * time.Hour, MaxHistories: 10000, MaxRevisions: 500}
+
+// Expired reports whether a history whose head was saved at saved has
+// expired at now
+func (p Policy) Expired(saved, now time.Time) bool {
+	return p.TTL > 0 && !now.Before(saved.Add(p.TTL))
+}
+
+// Revision is an immutable save of a conversion. Numbers start at 1.
+type Revision struct {
+	Number int `json:"number"`
+	converter.Request
+	Code      string    `json:"code"`
+	CreatedAt time.Time `json:"createdAt"`
+	// RestoredFrom is the number of the revision this one restores
+	RestoredFrom int `json:"restoredFrom,omitempty"`
+}
+
+// sameContent reports whether two revisions save the same conversion
+func sameContent(a, b Revision) bool {
+	return converter.RequestKey(a.Request) == converter.RequestKey(b.Request) && a.Code == b.Code
+}
+
+// NewID returns a random history ID
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidID reports whether id has the form of a history ID
+func ValidID(id string) bool {
+	if len(id) != 16 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// Start creates a history under a new random ID with a first revision of
+// req and its code, and returns the ID
+func Start(store Store, req converter.Request, code string, now time.Time) (string, Revision, error) {
+	rev := Revision{Request: req, Code: code, CreatedAt: now}
+	// IDs are random; retry the rare collision with another history
+	var id string
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		id = NewID()
+		if rev, err = store.Create(id, rev); !errors.Is(err, ErrExists) {
+			break
+		}
+	}
+	return id, rev, err
+}
+
+// Save appends a revision of req and its code to history id, or returns
+// ErrNotFound, or ErrHistoryFull if the history has policy.MaxRevisions.
+// Saving the content of the head again returns the head and false instead
+// of a new revision.
+func Save(store Store, policy Policy, id string, req converter.Request, code string, now time.Time) (Revision, bool, error) {
+	rev := Revision{Request: req, Code: code, CreatedAt: now}
+	return appendRevision(store, policy, id, func(head Revision) (Revision, bool) {
+		return rev, !sameContent(head, rev)
+	})
+}
+
+// Restore appends a copy of revision number of history id, making its
+// content the head again, within the bounds of policy
+func Restore(store Store, policy Policy, id string, number int, now time.Time) (Revision, error) {
+	old, err := store.Revision(id, number)
+	if err != nil {
+		return Revision{}, err
+	}
+	rev, _, err := appendRevision(store, policy, id, func(Revision) (Revision, bool) {
+		return Revision{
+			Request:      old.Request,
+			Code:         old.Code,
+			CreatedAt:    now,
+			RestoredFrom: old.Number,
+		}, true
+	})
+	return rev, err
+}
+
+// appendRevision appends the revision returned by next unless the history
+// already has policy.MaxRevisions
+func appendRevision(store Store, policy Policy, id string, next func(head Revision) (Revision, bool)) (Revision, bool, error) {
+	full := false
+	rev, appended, err := store.Append(id, func(head Revision) (Revision, bool) {
+		rev, ok := next(head)
+		if ok && policy.MaxRevisions > 0 && head.Number >= policy.MaxRevisions {
+			full = true
+			return head, false
+		}
+		return rev, ok
+	})
+	if err == nil && full {
+		return Revision{}, false, ErrHistoryFull
+	}
+	return rev, appended, err
+}
+
+// Diff compares two revisions
+type Diff struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Options lists the changed conversion options, the path being the
+	// option name
+	Options []diff.Change `json:"options"`
+	// HTML lists the structural changes of the input
+	HTML []diff.Change `json:"html"`
+	// Go is the line-based diff of the generated code
+	Go GoDiff `json:"go"`
+}
+
+// GoDiff is a line-based diff of generated code
+type GoDiff struct {
+	Added   int         `json:"added"`
+	Removed int         `json:"removed"`
+	Unified string      `json:"unified"`
+	Lines   []diff.Line `json:"lines"`
+}
+
+// Compare returns the differences from revision a to revision b
+func Compare(a, b Revision) (Diff, error) {
+	htmlChanges, err := diff.HTML(a.HTML, b.HTML)
+	if err != nil {
+		return Diff{}, err
+	}
+	lines := diff.Lines(a.Code, b.Code)
+	added, removed := diff.Count(lines)
+	d := Diff{
+		From:    a.Number,
+		To:      b.Number,
+		Options: compareOptions(a.Request, b.Request),
+		HTML:    htmlChanges,
+		Go: GoDiff{
+			Added:   added,
+			Removed: removed,
+			Unified: diff.Unified(revisionName(a), revisionName(b), lines, 3),
+			Lines:   lines,
+		},
+	}
+	if d.Options == nil {
+		d.Options = []diff.Change{}
+	}
+	if d.HTML == nil {
+		d.HTML = []diff.Change{}
+	}
+	return d, nil
+}
+
+func revisionName(r Revision) string {
+	return fmt.Sprintf("revision %d", r.Number)
+}
+
+// compareOptions lists the options that differ between a and b
+func compareOptions(a, b converter.Request) []diff.Change {
+	var changes []diff.Change
+	option := func(name, old, new string) {
+		if old != new {
+			changes = append(changes, diff.Change{Op: diff.OpChanged, Path: name, Old: old, New: new})
+		}
+	}
+	option("packagePrefix", a.PackagePrefix, b.PackagePrefix)
+	option("vuetifyPrefix", a.VuetifyPrefix, b.VuetifyPrefix)
+	option("vuetifyXPrefix", a.VuetifyXPrefix, b.VuetifyXPrefix)
+	option("direction", string(a.Direction), string(b.Direction))
+	option("childrenMode", strconv.FormatBool(a.ChildrenMode), strconv.FormatBool(b.ChildrenMode))
+	option("verify", strconv.FormatBool(a.Verify), strconv.FormatBool(b.Verify))
+	return changes
+}
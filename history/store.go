@@ -0,0 +1,325 @@
+package history
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store persists histories. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Create starts history id with rev as its first revision, or returns
+	// ErrExists if the ID is taken
+	Create(id string, rev Revision) (Revision, error)
+	// Append passes the head of history id to next and, if next returns
+	// true, numbers the revision it returns after the head and stores it,
+	// all in one transaction. It returns the new revision and true, the head
+	// and false if next declined, or ErrNotFound.
+	Append(id string, next func(head Revision) (Revision, bool)) (Revision, bool, error)
+	// Revisions returns the revisions of history id, oldest first, or
+	// ErrNotFound
+	Revisions(id string) ([]Revision, error)
+	// Revision returns revision number of history id, or ErrNotFound or
+	// ErrRevisionNotFound
+	Revision(id string, number int) (Revision, error)
+	// Sweep removes the histories expired at now by policy, then the least
+	// recently saved ones beyond policy.MaxHistories, and returns their
+	// number
+	Sweep(now time.Time, policy Policy) (int, error)
+}
+
+// saved is the time a history was last saved, for Sweep
+type saved struct {
+	id string
+	at time.Time
+}
+
+// doomed returns the IDs of the histories Sweep removes
+func doomed(histories []saved, now time.Time, policy Policy) []string {
+	var ids []string
+	var kept []saved
+	for _, h := range histories {
+		if policy.Expired(h.at, now) {
+			ids = append(ids, h.id)
+		} else {
+			kept = append(kept, h)
+		}
+	}
+	if policy.MaxHistories > 0 && len(kept) > policy.MaxHistories {
+		sort.Slice(kept, func(i, j int) bool { return kept[i].at.Before(kept[j].at) })
+		for _, h := range kept[:len(kept)-policy.MaxHistories] {
+			ids = append(ids, h.id)
+		}
+	}
+	return ids
+}
+
+// SweepEvery sweeps store by policy every interval until ctx is done
+func SweepEvery(ctx context.Context, store Store, policy Policy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if n, err := store.Sweep(now, policy); err != nil {
+				log.Printf("Failed to sweep histories: %v", err)
+			} else if n > 0 {
+				log.Printf("Removed %d histories", n)
+			}
+		}
+	}
+}
+
+// MemoryStore keeps histories in memory. They are lost when the process
+// exits.
+type MemoryStore struct {
+	mu        sync.Mutex
+	histories map[string][]Revision
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{histories: make(map[string][]Revision)}
+}
+
+// Create implements Store
+func (m *MemoryStore) Create(id string, rev Revision) (Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.histories[id]; ok {
+		return Revision{}, ErrExists
+	}
+	rev.Number = 1
+	m.histories[id] = []Revision{rev}
+	return rev, nil
+}
+
+// Append implements Store
+func (m *MemoryStore) Append(id string, next func(head Revision) (Revision, bool)) (Revision, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	revisions, ok := m.histories[id]
+	if !ok {
+		return Revision{}, false, ErrNotFound
+	}
+	head := revisions[len(revisions)-1]
+	rev, ok := next(head)
+	if !ok {
+		return head, false, nil
+	}
+	rev.Number = head.Number + 1
+	m.histories[id] = append(revisions, rev)
+	return rev, true, nil
+}
+
+// Revisions implements Store
+func (m *MemoryStore) Revisions(id string) ([]Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	revisions, ok := m.histories[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]Revision(nil), revisions...), nil
+}
+
+// Revision implements Store
+func (m *MemoryStore) Revision(id string, number int) (Revision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	revisions, ok := m.histories[id]
+	if !ok {
+		return Revision{}, ErrNotFound
+	}
+	if number < 1 || number > len(revisions) {
+		return Revision{}, ErrRevisionNotFound
+	}
+	return revisions[number-1], nil
+}
+
+// Sweep implements Store
+func (m *MemoryStore) Sweep(now time.Time, policy Policy) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	histories := make([]saved, 0, len(m.histories))
+	for id, revisions := range m.histories {
+		histories = append(histories, saved{id, revisions[len(revisions)-1].CreatedAt})
+	}
+	ids := doomed(histories, now, policy)
+	for _, id := range ids {
+		delete(m.histories, id)
+	}
+	return len(ids), nil
+}
+
+// historyBucket holds a nested bucket per history, whose revisions are
+// keyed by their number in big-endian order
+var historyBucket = []byte("history")
+
+// BoltStore keeps histories in a BoltDB file
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens or creates the BoltDB file at path
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(historyBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Close closes the database file
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
+
+// Create implements Store
+func (b *BoltStore) Create(id string, rev Revision) (Revision, error) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(historyBucket).Bucket([]byte(id)) != nil {
+			return ErrExists
+		}
+		bucket, err := tx.Bucket(historyBucket).CreateBucket([]byte(id))
+		if err != nil {
+			return err
+		}
+		return putNext(bucket, &rev)
+	})
+	return rev, err
+}
+
+// Append implements Store
+func (b *BoltStore) Append(id string, next func(head Revision) (Revision, bool)) (Revision, bool, error) {
+	var rev Revision
+	appended := false
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(id))
+		if bucket == nil {
+			return ErrNotFound
+		}
+		var head Revision
+		if _, data := bucket.Cursor().Last(); data != nil {
+			if err := json.Unmarshal(data, &head); err != nil {
+				return err
+			}
+		}
+		if rev, appended = next(head); !appended {
+			rev = head
+			return nil
+		}
+		return putNext(bucket, &rev)
+	})
+	return rev, appended, err
+}
+
+// putNext numbers rev after the last revision in bucket and stores it
+func putNext(bucket *bolt.Bucket, rev *Revision) error {
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return err
+	}
+	rev.Number = int(seq)
+	data, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(revisionKey(rev.Number), data)
+}
+
+// Revisions implements Store
+func (b *BoltStore) Revisions(id string) ([]Revision, error) {
+	var revisions []Revision
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(id))
+		if bucket == nil {
+			return ErrNotFound
+		}
+		return bucket.ForEach(func(_, v []byte) error {
+			var rev Revision
+			if err := json.Unmarshal(v, &rev); err != nil {
+				return err
+			}
+			revisions = append(revisions, rev)
+			return nil
+		})
+	})
+	return revisions, err
+}
+
+// Revision implements Store
+func (b *BoltStore) Revision(id string, number int) (Revision, error) {
+	var rev Revision
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucket).Bucket([]byte(id))
+		if bucket == nil {
+			return ErrNotFound
+		}
+		if number < 1 {
+			return ErrRevisionNotFound
+		}
+		data := bucket.Get(revisionKey(number))
+		if data == nil {
+			return ErrRevisionNotFound
+		}
+		return json.Unmarshal(data, &rev)
+	})
+	return rev, err
+}
+
+// Sweep implements Store
+func (b *BoltStore) Sweep(now time.Time, policy Policy) (int, error) {
+	var ids []string
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(historyBucket)
+		var histories []saved
+		err := root.ForEachBucket(func(id []byte) error {
+			var head Revision
+			if _, data := root.Bucket(id).Cursor().Last(); data != nil {
+				if err := json.Unmarshal(data, &head); err != nil {
+					return err
+				}
+			}
+			histories = append(histories, saved{string(id), head.CreatedAt})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		ids = doomed(histories, now, policy)
+		for _, id := range ids {
+			if err := root.DeleteBucket([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+func revisionKey(number int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(number))
+	return key
+}
@@ -0,0 +1,123 @@
+// Package jobs runs conversions asynchronously. A job is queued, picked up by
+// a worker and keeps its result until it expires, so documents too large to
+// convert within a single request can be polled for instead. Job state lives
+// in a Store; a DirStore keeps queued work across restarts.
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/errcode"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+// Job states. Queued and running jobs are pending; the others are final.
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Final reports whether a job in this state will not change any more
+func (s Status) Final() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// Input is the work of a job: a single conversion request, or a batch when
+// Items is set, in which case the request fields are the batch defaults.
+type Input struct {
+	converter.Request
+	Items []converter.BatchItem `json:"items,omitempty"`
+}
+
+// IsBatch reports whether the input is a batch of items
+func (in Input) IsBatch() bool {
+	return len(in.Items) > 0
+}
+
+// Batch returns the input as a batch request
+func (in Input) Batch() converter.BatchRequest {
+	return converter.BatchRequest{Defaults: in.Request, Items: in.Items}
+}
+
+// Validate checks the input before it is queued
+func (in Input) Validate() error {
+	if in.IsBatch() {
+		return converter.ValidateBatch(in.Batch())
+	}
+	switch {
+	case in.HTML == "":
+		return converter.ErrHTMLRequired
+	case !in.Direction.Valid():
+		return converter.ErrInvalidDirection
+	}
+	return nil
+}
+
+// Progress counts the converted items of a job. A single conversion counts
+// as one item.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Job is a conversion job as persisted in a Store.
+type Job struct {
+	ID       string   `json:"id"`
+	Status   Status   `json:"status"`
+	Progress Progress `json:"progress"`
+	Input    Input    `json:"input"`
+	// Result is set when a single conversion succeeds
+	Result *converter.Response `json:"result,omitempty"`
+	// BatchResult is set when a batch finishes; individual items may have
+	// failed
+	BatchResult *converter.BatchResponse `json:"batchResult,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+	// ErrorCode, ErrorStatus, ErrorField and ErrorDetails describe the API
+	// error of a failed job, so its result reports the same error as a
+	// synchronous conversion would. They are empty for errors without a code.
+	ErrorCode    string         `json:"errorCode,omitempty"`
+	ErrorStatus  int            `json:"errorStatus,omitempty"`
+	ErrorField   string         `json:"errorField,omitempty"`
+	ErrorDetails map[string]any `json:"errorDetails,omitempty"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	ExpiresAt    time.Time      `json:"expiresAt"`
+}
+
+// Expired reports whether the job has outlived its TTL at now
+func (j Job) Expired(now time.Time) bool {
+	return !j.ExpiresAt.IsZero() && now.After(j.ExpiresAt)
+}
+
+// fail marks the job as failed with err
+func (j *Job) fail(err error) {
+	j.Status = StatusFailed
+	j.Error = err.Error()
+	info := errcode.Of(err)
+	j.ErrorCode, j.ErrorStatus, j.ErrorField, j.ErrorDetails = info.Code, info.Status, info.Field, info.Details
+}
+
+// newID returns a random job ID
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validID reports whether id has the format of newID, so it is safe to use
+// as a file name
+func validID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
@@ -0,0 +1,359 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/errcode"
+)
+
+// Errors returned by Manager
+var (
+	ErrQueueFull   = errcode.New(http.StatusServiceUnavailable, "queue_full", "Job queue is full")
+	ErrJobFinished = errcode.New(http.StatusConflict, "job_finished", "Job has already finished")
+)
+
+// Options configures a Manager
+type Options struct {
+	// Workers is the number of jobs converted at once
+	Workers int
+	// QueueSize is the number of queued jobs accepted before Submit fails
+	// with ErrQueueFull
+	QueueSize int
+	// TTL is how long a job and its result are kept after the job was
+	// submitted or last finished
+	TTL time.Duration
+	// SweepInterval is how often expired jobs are deleted from the store
+	SweepInterval time.Duration
+}
+
+// DefaultOptions are the options used for zero fields of Options
+var DefaultOptions = Options{
+	Workers:       2,
+	QueueSize:     100,
+	TTL:           time.Hour,
+	SweepInterval: time.Minute,
+}
+
+// Manager queues jobs, runs them on a pool of workers and records their state
+// in a Store.
+type Manager struct {
+	store Store
+	opts  Options
+	now   func() time.Time
+
+	// mu serializes every read-modify-write of a job in the store
+	mu      sync.Mutex
+	pending []string
+	running map[string]context.CancelFunc
+	wake    chan struct{}
+}
+
+// NewManager returns a Manager keeping jobs in store. Call Start before
+// submitting jobs.
+func NewManager(store Store, opts Options) *Manager {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultOptions.Workers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultOptions.QueueSize
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultOptions.TTL
+	}
+	if opts.SweepInterval <= 0 {
+		opts.SweepInterval = DefaultOptions.SweepInterval
+	}
+	return &Manager{
+		store:   store,
+		opts:    opts,
+		now:     time.Now,
+		running: make(map[string]context.CancelFunc),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Start requeues the pending jobs found in the store, oldest first, and
+// starts the workers and the expiry sweeper. They stop when ctx is done;
+// jobs interrupted that way stay pending in the store and resume on the
+// next Start.
+func (m *Manager) Start(ctx context.Context) error {
+	jobs, err := m.store.List()
+	if err != nil {
+		return err
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+
+	now := m.now()
+	m.mu.Lock()
+	for _, job := range jobs {
+		switch {
+		case job.Expired(now):
+			m.store.Delete(job.ID)
+		case !job.Status.Final():
+			job.Status = StatusQueued
+			job.UpdatedAt = now
+			if err := m.store.Put(job); err != nil {
+				m.mu.Unlock()
+				return err
+			}
+			m.pending = append(m.pending, job.ID)
+		}
+	}
+	restored := len(m.pending)
+	m.mu.Unlock()
+	if restored > 0 {
+		log.Printf("Resuming %d queued conversion jobs", restored)
+		m.signal()
+	}
+
+	for i := 0; i < m.opts.Workers; i++ {
+		go m.work(ctx)
+	}
+	go m.sweepLoop(ctx)
+	return nil
+}
+
+// Submit validates input and queues a new job for it
+func (m *Manager) Submit(input Input) (Job, error) {
+	if err := input.Validate(); err != nil {
+		return Job{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pending) >= m.opts.QueueSize {
+		return Job{}, ErrQueueFull
+	}
+
+	now := m.now()
+	total := 1
+	if input.IsBatch() {
+		total = len(input.Items)
+	}
+	job := Job{
+		ID:        newID(),
+		Status:    StatusQueued,
+		Progress:  Progress{Total: total},
+		Input:     input,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(m.opts.TTL),
+	}
+	if err := m.store.Put(job); err != nil {
+		return Job{}, err
+	}
+	m.pending = append(m.pending, job.ID)
+	m.signal()
+	return job, nil
+}
+
+// Get returns a job, or ErrNotFound if it is unknown or expired
+func (m *Manager) Get(id string) (Job, error) {
+	job, err := m.store.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Expired(m.now()) {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// Cancel stops a queued or running job. Cancelling a finished job returns
+// the job with ErrJobFinished.
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, err := m.store.Get(id)
+	if err != nil {
+		return Job{}, err
+	}
+	now := m.now()
+	if job.Expired(now) {
+		return Job{}, ErrNotFound
+	}
+	if job.Status.Final() {
+		return job, ErrJobFinished
+	}
+
+	if cancel, ok := m.running[id]; ok {
+		cancel()
+	}
+	for i, pendingID := range m.pending {
+		if pendingID == id {
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			break
+		}
+	}
+
+	job.Status = StatusCancelled
+	job.UpdatedAt = now
+	job.ExpiresAt = now.Add(m.opts.TTL)
+	return job, m.store.Put(job)
+}
+
+// signal wakes an idle worker
+func (m *Manager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for the next queued job ID
+func (m *Manager) next(ctx context.Context) (string, bool) {
+	for {
+		m.mu.Lock()
+		if len(m.pending) > 0 {
+			id := m.pending[0]
+			m.pending = m.pending[1:]
+			if len(m.pending) > 0 {
+				m.signal()
+			}
+			m.mu.Unlock()
+			return id, true
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return "", false
+		case <-m.wake:
+		}
+	}
+}
+
+func (m *Manager) work(ctx context.Context) {
+	for {
+		id, ok := m.next(ctx)
+		if !ok {
+			return
+		}
+		m.run(ctx, id)
+	}
+}
+
+// run converts one job and records its outcome
+func (m *Manager) run(ctx context.Context, id string) {
+	m.mu.Lock()
+	job, err := m.store.Get(id)
+	if err != nil || job.Status != StatusQueued {
+		// Cancelled or expired while queued
+		m.mu.Unlock()
+		return
+	}
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	m.running[id] = cancel
+	job.Status = StatusRunning
+	job.UpdatedAt = m.now()
+	if err := m.store.Put(job); err != nil {
+		log.Printf("Failed to start job %s: %v", id, err)
+	}
+	m.mu.Unlock()
+
+	var result *converter.Response
+	var batchResult *converter.BatchResponse
+	var convErr error
+	if job.Input.IsBatch() {
+		response := converter.BatchResponse{Results: make([]converter.BatchResult, len(job.Input.Items))}
+		converter.RunBatchWith(jobCtx, job.Input.Batch(), converter.DefaultBatchWorkers, converter.ConvertJob, func(r converter.BatchResult) {
+			response.Results[r.Index] = r
+			if r.Error != "" {
+				response.Failed++
+			} else {
+				response.Succeeded++
+			}
+			m.update(id, func(j *Job) { j.Progress.Done++ })
+		})
+		batchResult = &response
+	} else {
+		var resp converter.Response
+		if resp, convErr = converter.ConvertJob(jobCtx, job.Input.Request); convErr == nil {
+			result = &resp
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.running, id)
+	if ctx.Err() != nil {
+		// Shutting down: leave the job pending so it resumes after a restart
+		return
+	}
+	job, err = m.store.Get(id)
+	if err != nil || job.Status != StatusRunning {
+		// Cancelled or expired while running
+		return
+	}
+
+	now := m.now()
+	job.Progress.Done = job.Progress.Total
+	job.Result = result
+	job.BatchResult = batchResult
+	job.Status = StatusSucceeded
+	if convErr != nil {
+		job.fail(convErr)
+	}
+	job.UpdatedAt = now
+	job.ExpiresAt = now.Add(m.opts.TTL)
+	if err := m.store.Put(job); err != nil {
+		log.Printf("Failed to save job %s: %v", id, err)
+	}
+}
+
+// update applies fn to a running job in the store
+func (m *Manager) update(id string, fn func(*Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, err := m.store.Get(id)
+	if err != nil || job.Status != StatusRunning {
+		return
+	}
+	fn(&job)
+	job.UpdatedAt = m.now()
+	if err := m.store.Put(job); err != nil {
+		log.Printf("Failed to update job %s: %v", id, err)
+	}
+}
+
+func (m *Manager) sweepLoop(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sweep()
+		}
+	}
+}
+
+// Sweep deletes the expired jobs that are not running
+func (m *Manager) Sweep() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs, err := m.store.List()
+	if err != nil {
+		log.Printf("Failed to list jobs: %v", err)
+		return
+	}
+	now := m.now()
+	for _, job := range jobs {
+		if _, running := m.running[job.ID]; running || !job.Expired(now) {
+			continue
+		}
+		if err := m.store.Delete(job.ID); err != nil {
+			log.Printf("Failed to delete job %s: %v", job.ID, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"html2go-converter/errcode"
+)
+
+// ErrNotFound is returned for unknown or expired jobs
+var ErrNotFound = errcode.New(http.StatusNotFound, "job_not_found", "Job not found")
+
+// Store persists jobs. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put creates or replaces a job
+	Put(job Job) error
+	// Get returns the job with the given ID, or ErrNotFound
+	Get(id string) (Job, error)
+	// Delete removes a job; deleting an unknown job is not an error
+	Delete(id string) error
+	// List returns every stored job in no particular order
+	List() ([]Job, error)
+}
+
+// MemoryStore keeps jobs in memory. Jobs are lost when the process exits.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// List implements Store
+func (s *MemoryStore) List() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// DirStore keeps each job as a JSON file in a directory, so queued work
+// survives a restart.
+type DirStore struct {
+	dir string
+}
+
+// NewDirStore returns a DirStore writing to dir, creating it if needed
+func NewDirStore(dir string) (*DirStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create job store: %w", err)
+	}
+	return &DirStore{dir: dir}, nil
+}
+
+func (s *DirStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// Put implements Store. The file is replaced atomically so a crash never
+// leaves a partial job behind.
+func (s *DirStore) Put(job Job) error {
+	if !validID(job.ID) {
+		return fmt.Errorf("invalid job ID %q", job.ID)
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".job-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(job.ID))
+}
+
+// Get implements Store
+func (s *DirStore) Get(id string) (Job, error) {
+	if !validID(id) {
+		return Job{}, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Job{}, ErrNotFound
+	}
+	if err != nil {
+		return Job{}, err
+	}
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return Job{}, fmt.Errorf("decode job %s: %w", id, err)
+	}
+	return job, nil
+}
+
+// Delete implements Store
+func (s *DirStore) Delete(id string) error {
+	if !validID(id) {
+		return nil
+	}
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List implements Store. Files that cannot be decoded are skipped.
+func (s *DirStore) List() ([]Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []Job
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if job, err := s.Get(id); err == nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
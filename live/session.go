@@ -0,0 +1,225 @@
+// Package live implements the live-conversion protocol spoken over the
+// /api/live WebSocket. A client keeps one session per editor, sends every
+// edit with a sequence number and receives the converted code of the latest
+// edit. Rapid edits are coalesced and a conversion made stale by a newer edit
+// is abandoned, so a busy typist never waits for outdated results.
+package live
+
+import (
+	"context"
+	"go/parser"
+	"go/scanner"
+	"strings"
+	"sync"
+	"time"
+
+	"html2go-converter/converter"
+)
+
+// DefaultDebounce is how long a session waits for further edits before
+// converting.
+const DefaultDebounce = 75 * time.Millisecond
+
+// Message types sent by the server
+const (
+	TypeResult = "result"
+	TypeError  = "error"
+)
+
+// ClientMessage is an edit sent by the client. Nil fields keep the value of
+// the previous message, so a client may send the options once and then only
+// the document.
+type ClientMessage struct {
+	// Seq numbers the edit; the result of the edit carries the same number.
+	// When zero, the session numbers the edit after the previous one.
+	Seq  int64   `json:"seq"`
+	HTML *string `json:"html,omitempty"`
+	converter.Overrides
+}
+
+// Diagnostic is a problem found while converting an edit. Line and Column
+// refer to the converted code and are zero when unknown.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// ServerMessage is the outcome of converting the edit numbered Seq.
+type ServerMessage struct {
+	Type  string `json:"type"`
+	Seq   int64  `json:"seq"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+	// ErrorCode, ErrorField and ErrorDetails describe Error like the problem
+	// document of a conversion request would
+	ErrorCode    string         `json:"errorCode,omitempty"`
+	ErrorField   string         `json:"errorField,omitempty"`
+	ErrorDetails map[string]any `json:"errorDetails,omitempty"`
+	Diagnostics  []Diagnostic   `json:"diagnostics,omitempty"`
+	// DurationMs is the conversion time in milliseconds
+	DurationMs int64 `json:"durationMs"`
+}
+
+// Session coalesces the edits of one client and converts the latest one.
+type Session struct {
+	send     func(ServerMessage) error
+	debounce time.Duration
+
+	mu      sync.Mutex
+	req     converter.Request
+	seq     int64
+	changed chan struct{}
+}
+
+// NewSession returns a session converting with defaults until the client
+// overrides them. Results are passed to send, one call at a time.
+func NewSession(defaults converter.Request, debounce time.Duration, send func(ServerMessage) error) *Session {
+	if defaults.Direction == "" {
+		defaults.Direction = converter.DirectionHTMLToGo
+	}
+	return &Session{
+		send:     send,
+		debounce: debounce,
+		req:      defaults,
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Edit records an edit from the client and schedules its conversion
+func (s *Session) Edit(msg ClientMessage) {
+	s.mu.Lock()
+	s.req = msg.Overrides.Apply(s.req)
+	if msg.HTML != nil {
+		s.req.HTML = *msg.HTML
+	}
+	if msg.Seq != 0 {
+		s.seq = msg.Seq
+	} else {
+		s.seq++
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Run converts edits until ctx is done or send fails
+func (s *Session) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.changed:
+		}
+
+		// Coalesce: wait until no edit arrived for the debounce period, and
+		// start over whenever a newer edit makes the conversion stale
+		for {
+			if !s.settle(ctx) {
+				return ctx.Err()
+			}
+			msg, stale := s.convertLatest(ctx)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if stale {
+				continue
+			}
+			if err := s.send(msg); err != nil {
+				return err
+			}
+			break
+		}
+	}
+}
+
+// convertLatest converts the latest edit. It gives up and reports stale as
+// soon as another edit arrives.
+func (s *Session) convertLatest(ctx context.Context) (msg ServerMessage, stale bool) {
+	s.mu.Lock()
+	req, seq := s.req, s.seq
+	s.mu.Unlock()
+
+	convCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan ServerMessage, 1)
+	go func() {
+		done <- convert(convCtx, req, seq)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ServerMessage{}, false
+	case <-s.changed:
+		return ServerMessage{}, true
+	case msg := <-done:
+		return msg, false
+	}
+}
+
+// settle waits until no edit arrived for the debounce period. It returns
+// false if ctx is done first.
+func (s *Session) settle(ctx context.Context) bool {
+	if s.debounce <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(s.debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.changed:
+			timer.Reset(s.debounce)
+		case <-timer.C:
+			return true
+		}
+	}
+}
+
+// convert converts one edit and describes the outcome
+func convert(ctx context.Context, req converter.Request, seq int64) ServerMessage {
+	start := time.Now()
+	resp, err := converter.ConvertContext(ctx, req)
+	msg := ServerMessage{Type: TypeResult, Seq: seq, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		info := converter.ErrorInfo(err)
+		msg.Type = TypeError
+		msg.Error = err.Error()
+		msg.ErrorCode, msg.ErrorField, msg.ErrorDetails = info.Code, info.Field, info.Details
+		msg.Diagnostics = []Diagnostic{{Severity: "error", Message: err.Error()}}
+		return msg
+	}
+	msg.Code = resp.Code
+	msg.Diagnostics = checkSyntax(req.PackagePrefix, resp.Code)
+	return msg
+}
+
+// checkSyntax reports the first syntax error of the converted code, which
+// html2go produces for some malformed documents
+func checkSyntax(packagePrefix, code string) []Diagnostic {
+	if strings.TrimSpace(code) == "" {
+		return []Diagnostic{{Severity: "warning", Message: "The document produced no code"}}
+	}
+	prefix := ""
+	if packagePrefix != "" {
+		prefix = packagePrefix + "."
+	}
+	// Wrap the code on its own lines so positions map onto the code
+	_, err := parser.ParseExpr(prefix + "Components(\n" + code + ",\n)")
+	if err == nil {
+		return nil
+	}
+
+	diagnostic := Diagnostic{Severity: "warning", Message: "Generated code is not valid Go: " + err.Error()}
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		diagnostic.Message = "Generated code is not valid Go: " + list[0].Msg
+		diagnostic.Line = list[0].Pos.Line - 1
+		diagnostic.Column = list[0].Pos.Column
+	}
+	return []Diagnostic{diagnostic}
+}
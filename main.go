@@ -1,55 +1,261 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	handler "html2go-converter/api"
+	"html2go-converter/assets"
+	"html2go-converter/config"
+	"html2go-converter/converter"
+	"html2go-converter/examples"
+	"html2go-converter/history"
+	"html2go-converter/jobs"
+	"html2go-converter/middleware"
+	"html2go-converter/preview"
+	"html2go-converter/routes"
+	"html2go-converter/serverless"
+	"html2go-converter/snippets"
 )
 
 func main() {
 	// Define command line parameters
 	portPtr := flag.Int("port", 8080, "端口号")
+	publicDirPtr := flag.String("public-dir", "", "从磁盘目录提供静态文件（开发用），默认使用内嵌资源")
+	var overlayDirs []string
+	flag.Func("overlay-dir", "叠加在默认资源之上的目录，可重复指定，后指定的优先", func(dir string) error {
+		overlayDirs = append(overlayDirs, dir)
+		return nil
+	})
+	cspPtr := flag.String("csp", middleware.DefaultSecurityOptions.ContentSecurityPolicy, "Content-Security-Policy模板，{nonce}会被替换为每个请求的随机数")
+	cspReportOnlyPtr := flag.Bool("csp-report-only", false, "仅报告CSP违规而不拦截")
+	modePtr := flag.String("mode", "", "运行方式：server、lambda、cgi或fcgi，默认根据环境自动检测")
+	fcgiAddrPtr := flag.String("fcgi-addr", "", "FastCGI监听地址（TCP地址或Unix套接字路径），为空时使用标准输入上的监听器")
+	jobsDirPtr := flag.String("jobs-dir", "", "异步任务的持久化目录，重启后继续排队中的任务，为空时仅保存在内存中")
+	jobWorkersPtr := flag.Int("job-workers", jobs.DefaultOptions.Workers, "同时执行的异步任务数")
+	jobQueuePtr := flag.Int("job-queue", jobs.DefaultOptions.QueueSize, "异步任务队列长度")
+	jobTTLPtr := flag.Duration("job-ttl", jobs.DefaultOptions.TTL, "异步任务及其结果的保留时间")
+	limits := config.ConversionLimits()
+	flag.Int64Var(&limits.MaxBodyBytes, "max-body-bytes", limits.MaxBodyBytes, "请求体的最大字节数，0表示不限制")
+	flag.IntVar(&limits.MaxDepth, "max-html-depth", limits.MaxDepth, "HTML元素的最大嵌套深度，0表示不限制")
+	flag.IntVar(&limits.MaxNodes, "max-html-nodes", limits.MaxNodes, "HTML的最大节点数，0表示不限制")
+	flag.DurationVar(&limits.Timeout, "conversion-timeout", limits.Timeout, "单次转换的最长时间，0表示不限制")
+	flag.IntVar(&limits.MaxConcurrent, "max-concurrent", limits.MaxConcurrent, "同时进行的转换数，超出的转换排队等待，0表示不限制")
+	flag.DurationVar(&limits.QueueTimeout, "queue-timeout", limits.QueueTimeout, "转换排队等待的最长时间，0表示一直等待")
+	flag.Int64Var(&limits.JobMaxBodyBytes, "job-max-body-bytes", limits.JobMaxBodyBytes, "异步任务请求体的最大字节数，0表示不限制")
+	flag.DurationVar(&limits.JobTimeout, "job-timeout", limits.JobTimeout, "异步任务中单次转换的最长时间，0表示不限制")
+	cacheBytes, cacheDir, cacheDirBytes := config.Cache()
+	flag.Int64Var(&cacheBytes, "cache-max-bytes", cacheBytes, "内存中转换结果缓存的最大字节数，0表示不缓存")
+	flag.StringVar(&cacheDir, "cache-dir", cacheDir, "转换结果的磁盘缓存目录，重启后仍然有效，为空时仅缓存在内存中")
+	flag.Int64Var(&cacheDirBytes, "cache-dir-max-bytes", cacheDirBytes, "磁盘缓存的最大字节数，超出时删除最久未使用的结果")
+	quarantineDir, quarantineBytes := config.Quarantine()
+	flag.StringVar(&quarantineDir, "quarantine-dir", quarantineDir, "保存导致转换器崩溃的输入的目录，为空时不保存")
+	flag.Int64Var(&quarantineBytes, "quarantine-max-bytes", quarantineBytes, "隔离目录的最大字节数，超出时删除最旧的输入")
+	snippetsDB, snippetPolicy := config.Snippets()
+	flag.StringVar(&snippetsDB, "snippets-db", snippetsDB, "保存分享链接的BoltDB文件，为空时仅保存在内存中")
+	flag.StringVar(&snippetPolicy.DefaultExpiry, "snippet-expiry", snippetPolicy.DefaultExpiry, "分享链接的默认有效期：1h、1d、7d、30d或never")
+	flag.DurationVar(&snippetPolicy.MaxTTL, "snippet-max-ttl", snippetPolicy.MaxTTL, "分享链接的最长有效期，0表示允许永不过期")
+	historyDB, historyPolicy := config.History()
+	flag.StringVar(&historyDB, "history-db", historyDB, "保存转换历史的BoltDB文件，为空时仅保存在内存中")
+	flag.DurationVar(&historyPolicy.TTL, "history-ttl", historyPolicy.TTL, "转换历史在最后一次保存后的保留时间，0表示永久保留")
+	flag.IntVar(&historyPolicy.MaxHistories, "history-max-entries", historyPolicy.MaxHistories, "保留的转换历史数量，超出时删除最久未保存的历史，0表示不限制")
+	flag.IntVar(&historyPolicy.MaxRevisions, "history-max-revisions", historyPolicy.MaxRevisions, "每个转换历史的最大修订版本数，0表示不限制")
+	examplesDirPtr := flag.String("examples-dir", config.ExamplesDir(), "示例目录（带注释的HTML文件），为空时使用内嵌的示例")
+	previewTTL, previewMaxEntries, previewCSSDir := config.Preview()
+	flag.DurationVar(&previewTTL, "preview-ttl", previewTTL, "实时预览的保留时间")
+	flag.Int64Var(&previewMaxEntries, "preview-max-entries", previewMaxEntries, "内存中保留的实时预览数量，超出时删除最旧的预览")
+	flag.StringVar(&previewCSSDir, "preview-css-dir", previewCSSDir, "实时预览可引入的Tailwind/Vuetify样式表目录，为空时使用内嵌的样式表")
 	flag.Parse()
 	port := *portPtr
 
-	// Create a new router
-	mux := http.NewServeMux()
+	// Serve assets from disk when a public directory is given
+	base := assets.Embedded()
+	if *publicDirPtr != "" {
+		base = assets.Dir(*publicDirPtr)
+		log.Printf("Serving static files from %s", *publicDirPtr)
+	}
+
+	// Stack overlay directories on top of the base assets, last one first
+	layers := []assets.Source{base}
+	for _, dir := range overlayDirs {
+		layers = append([]assets.Source{assets.Dir(dir)}, layers...)
+		log.Printf("Overlaying static files from %s", dir)
+	}
+	if len(layers) > 1 {
+		handler.UseAssets(assets.Overlay(layers...))
+	} else {
+		handler.UseAssets(base)
+	}
 
-	// Register API handlers
-	mux.HandleFunc("/convert", handler.Handler)
+	// Bound the resources of every conversion and keep the inputs that crash it
+	var quarantine *converter.Quarantine
+	if quarantineDir != "" {
+		var err error
+		if quarantine, err = converter.NewQuarantine(quarantineDir, quarantineBytes); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Quarantining crashing inputs in %s", quarantineDir)
+	}
+	handler.UseLimits(limits, quarantine)
 
-	// Serve static files from public directory
-	publicHandler := http.FileServer(http.Dir("public"))
-	mux.Handle("/static/", http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Set proper content types based on file extensions
-		if strings.HasSuffix(r.URL.Path, ".js") {
-			w.Header().Set("Content-Type", "application/javascript")
-		} else if strings.HasSuffix(r.URL.Path, ".css") {
-			w.Header().Set("Content-Type", "text/css")
+	// Cache conversion results by content
+	var cache *converter.Cache
+	if cacheBytes > 0 {
+		var err error
+		if cache, err = converter.NewCache(cacheBytes, cacheDir, cacheDirBytes); err != nil {
+			log.Fatal(err)
+		}
+		if cacheDir != "" {
+			log.Printf("Caching conversion results in %s", cacheDir)
 		}
-		publicHandler.ServeHTTP(w, r)
-	})))
+	}
+	handler.UseCache(cache)
+
+	// Validate and convert every example before serving any request
+	examplesFS := examples.Embedded()
+	if *examplesDirPtr != "" {
+		examplesFS = os.DirFS(*examplesDirPtr)
+		log.Printf("Loading examples from %s", *examplesDirPtr)
+	}
+	registry, err := examples.Load(examplesFS)
+	if err != nil {
+		log.Fatalf("Invalid examples:\n%v", err)
+	}
+	handler.UseExamples(registry)
 
-	// Also serve script.js and other root assets directly
-	mux.HandleFunc("/script.js", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/javascript")
-		http.ServeFile(w, r, "public/script.js")
+	// Configure security headers
+	security := middleware.DefaultSecurityOptions
+	security.ContentSecurityPolicy = *cspPtr
+	security.ReportOnly = *cspReportOnlyPtr
+
+	// Run asynchronous conversion jobs, persisting them when a directory is given
+	var store jobs.Store = jobs.NewMemoryStore()
+	if *jobsDirPtr != "" {
+		dirStore, err := jobs.NewDirStore(*jobsDirPtr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		store = dirStore
+		log.Printf("Storing conversion jobs in %s", *jobsDirPtr)
+	}
+	jobManager := jobs.NewManager(store, jobs.Options{
+		Workers:   *jobWorkersPtr,
+		QueueSize: *jobQueuePtr,
+		TTL:       *jobTTLPtr,
 	})
+	if err := jobManager.Start(context.Background()); err != nil {
+		log.Fatalf("Failed to start job manager: %v", err)
+	}
+	handler.UseJobManager(jobManager)
 
-	// Handle root path last
-	mux.HandleFunc("/", handler.Index)
+	// Stores opened below are closed on shutdown: log.Fatal exits without
+	// running deferred calls
+	var closers []io.Closer
+	closeStores := func() {
+		for _, c := range closers {
+			if err := c.Close(); err != nil {
+				log.Printf("Failed to close store: %v", err)
+			}
+		}
+	}
+
+	// Store shared snippets, persisting them when a database file is given
+	if _, err := snippetPolicy.TTL(""); err != nil {
+		log.Fatalf("Invalid default snippet expiry: %v", err)
+	}
+	var snippetStore snippets.Store = snippets.NewMemoryStore()
+	if snippetsDB != "" {
+		boltStore, err := snippets.OpenBoltStore(snippetsDB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		closers = append(closers, boltStore)
+		snippetStore = boltStore
+		log.Printf("Storing shared snippets in %s", snippetsDB)
+	}
+	go snippets.SweepEvery(context.Background(), snippetStore, time.Hour)
+	handler.UseSnippetStore(snippetStore, snippetPolicy)
+
+	// Keep the revisions of saved conversions
+	var historyStore history.Store = history.NewMemoryStore()
+	if historyDB != "" {
+		boltStore, err := history.OpenBoltStore(historyDB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		closers = append(closers, boltStore)
+		historyStore = boltStore
+		log.Printf("Storing conversion histories in %s", historyDB)
+	}
+	go history.SweepEvery(context.Background(), historyStore, historyPolicy, time.Hour)
+	handler.UseHistoryStore(historyStore, historyPolicy)
+
+	// Render live previews with the vendored stylesheets
+	previewAssets := preview.Vendored()
+	if previewCSSDir != "" {
+		previewAssets = os.DirFS(previewCSSDir)
+		log.Printf("Serving preview stylesheets from %s", previewCSSDir)
+	}
+	if names := preview.Available(previewAssets); len(names) > 0 {
+		log.Printf("Preview stylesheets: %s", strings.Join(names, ", "))
+	} else {
+		log.Printf("No preview stylesheets vendored, see preview/vendor/README.md")
+	}
+	handler.UsePreviews(preview.NewStore(int(previewMaxEntries), previewTTL), previewAssets)
+
+	// Build the application from the routes shared with vercel.json
+	app := routes.NewHandler(security)
+
+	// Pick the hosting mode, detecting serverless environments by default
+	mode := *modePtr
+	if mode == "" {
+		switch {
+		case serverless.IsLambda():
+			mode = "lambda"
+		case serverless.IsCGI():
+			mode = "cgi"
+		default:
+			mode = "server"
+		}
+	}
+
+	switch mode {
+	case "lambda":
+		err := serverless.StartLambda(app)
+		closeStores()
+		log.Fatal(err)
+	case "cgi":
+		err := serverless.ServeCGI(app)
+		closeStores()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	case "fcgi":
+		err := serverless.ServeFastCGI(app, *fcgiAddrPtr)
+		closeStores()
+		log.Fatal(err)
+	case "server":
+	default:
+		closeStores()
+		log.Fatalf("Unknown mode: %s", mode)
+	}
 
 	// Configure the HTTP server
 	addr := fmt.Sprintf(":%d", port)
 	server := &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      app,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
@@ -58,10 +264,31 @@ func main() {
 	// Start the server
 	listener, err := net.Listen("tcp", addr)
 	if err != nil {
+		closeStores()
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
+	// Drain the open connections on SIGINT or SIGTERM, then close the stores
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		<-ctx.Done()
+		log.Printf("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Shutdown: %v", err)
+		}
+	}()
+
 	actualPort := listener.Addr().(*net.TCPAddr).Port
 	log.Printf("Starting server on http://localhost:%d", actualPort)
-	log.Fatal(server.Serve(listener))
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		closeStores()
+		log.Fatal(err)
+	}
+	<-drained
+	closeStores()
 }
@@ -0,0 +1,279 @@
+// Package middleware contains HTTP middleware shared by the local server.
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressOptions configures the Compress middleware.
+type CompressOptions struct {
+	// MinSize is the smallest response body, in bytes, worth compressing.
+	// Streaming responses that flush before reaching it are compressed anyway.
+	MinSize int
+	// ContentTypes lists the media types that may be compressed.
+	ContentTypes []string
+	// Level is the compression level used for gzip and deflate.
+	Level int
+}
+
+// DefaultCompressOptions compresses text responses of 1 KiB and more.
+var DefaultCompressOptions = CompressOptions{
+	MinSize: 1024,
+	ContentTypes: []string{
+		"application/json",
+		"application/x-ndjson",
+		"application/problem+json",
+		"application/javascript",
+		"text/html",
+		"text/css",
+		"text/plain",
+		"text/x-go",
+		"image/svg+xml",
+	},
+	Level: gzip.DefaultCompression,
+}
+
+// Compress negotiates gzip or deflate with the client and compresses
+// responses whose content type is allowed. Responses that already carry a
+// Content-Encoding, such as precompressed static assets, pass through as is,
+// and so do protocol upgrades such as WebSocket handshakes. Every response
+// of an allowed content type varies on Accept-Encoding, whether or not this
+// one was compressed.
+//
+// A compressed response gets its own entity tag: the ETag set by the handler
+// with "-gzip" or "-deflate" appended inside the quotes. The suffix is taken
+// off If-None-Match before the handler compares it with its ETag, and put
+// back on the ETag of the resulting 304 Not Modified.
+func Compress(next http.Handler, opts CompressOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if r.Method == http.MethodHead {
+			encoding = ""
+		}
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, opts: opts}
+		if match := r.Header.Get("If-None-Match"); encoding != "" && match != "" {
+			if trimmed := trimETagSuffix(match, encoding); trimmed != match {
+				r = r.Clone(r.Context())
+				r.Header.Set("If-None-Match", trimmed)
+				cw.matchedSuffix = true
+			}
+		}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// honouring q-values. It returns "" when neither is acceptable.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "gzip" && name != "deflate" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		// Prefer gzip on ties, it is the more widely supported framing
+		if q > bestQ || (q == bestQ && name == "gzip") {
+			best, bestQ = name, q
+		}
+	}
+	if bestQ <= 0 {
+		return ""
+	}
+	return best
+}
+
+// etagSuffix returns the suffix that marks the entity tag of a response
+// compressed with encoding. Precompressed assets use "-gz" and "-br", so
+// their tags are never taken for ours.
+func etagSuffix(encoding string) string {
+	return "-" + encoding + `"`
+}
+
+// trimETagSuffix removes the suffix of encoding from every entity tag in an
+// If-None-Match list
+func trimETagSuffix(list, encoding string) string {
+	suffix := etagSuffix(encoding)
+	tags := strings.Split(list, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if strings.HasSuffix(tag, suffix) {
+			tag = strings.TrimSuffix(tag, suffix) + `"`
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", ")
+}
+
+// addVary adds field to the Vary header unless it is already listed
+func addVary(h http.Header, field string) {
+	for _, value := range h.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), field) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", field)
+}
+
+// compressWriter buffers the start of a response until it can decide whether
+// to compress it, then either compresses or passes the body through. With no
+// encoding it decides as soon as the header is written.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	opts     CompressOptions
+	// matchedSuffix is set when If-None-Match named a compressed response
+	matchedSuffix bool
+
+	status     int
+	buf        bytes.Buffer
+	decided    bool
+	compressor io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 {
+		return
+	}
+	cw.status = status
+	if status == http.StatusNotModified && cw.matchedSuffix {
+		// The client holds the compressed response; confirm its tag
+		if etag := cw.Header().Get("ETag"); etag != "" {
+			cw.Header().Set("ETag", strings.TrimSuffix(etag, `"`)+etagSuffix(cw.encoding))
+		}
+	}
+	// Bodiless responses are never compressed
+	if cw.encoding == "" || status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		return cw.write(p)
+	}
+	cw.buf.Write(p)
+	if cw.buf.Len() >= cw.opts.MinSize {
+		cw.decide(cw.eligible())
+	}
+	return len(p), nil
+}
+
+// Flush decides immediately so streaming responses reach the client.
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if cw.status == 0 {
+			cw.WriteHeader(http.StatusOK)
+		}
+		cw.decide(cw.eligible())
+	}
+	if f, ok := cw.compressor.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the response, compressing it only if it reached MinSize.
+func (cw *compressWriter) Close() error {
+	if !cw.decided {
+		if cw.status == 0 && cw.buf.Len() == 0 {
+			// Nothing was written; let net/http send its default response
+			return nil
+		}
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+		cw.decide(cw.eligible() && cw.buf.Len() >= cw.opts.MinSize)
+	}
+	if cw.compressor != nil {
+		return cw.compressor.Close()
+	}
+	return nil
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// eligible reports whether the response may be compressed, judging by the
+// headers the handler has set.
+func (cw *compressWriter) eligible() bool {
+	return cw.Header().Get("Content-Encoding") == "" && cw.compressible()
+}
+
+// compressible reports whether the content type of the response is allowed
+func (cw *compressWriter) compressible() bool {
+	mediaType, _, err := mime.ParseMediaType(cw.Header().Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, allowed := range cw.opts.ContentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// decide writes the header and flushes the buffered body, compressed or not.
+func (cw *compressWriter) decide(compress bool) {
+	cw.decided = true
+	h := cw.Header()
+	if compress || cw.compressible() {
+		addVary(h, "Accept-Encoding")
+	}
+	if compress {
+		h.Set("Content-Encoding", cw.encoding)
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", strings.TrimSuffix(etag, `"`)+etagSuffix(cw.encoding))
+		}
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		if cw.encoding == "gzip" {
+			cw.compressor, _ = gzip.NewWriterLevel(cw.ResponseWriter, cw.opts.Level)
+		} else {
+			cw.compressor, _ = flate.NewWriter(cw.ResponseWriter, cw.opts.Level)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.buf.Len() > 0 {
+		cw.write(cw.buf.Bytes())
+		cw.buf.Reset()
+	}
+}
+
+func (cw *compressWriter) write(p []byte) (int, error) {
+	if cw.compressor != nil {
+		return cw.compressor.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDs tags every request with an ID, echoed in the X-Request-ID
+// response header and available to handlers through RequestID. An ID sent
+// by the client or a proxy is kept when it is well formed.
+func RequestIDs(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := incomingRequestID(r)
+		if id == "" {
+			id = NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestID returns the ID of r. Outside the RequestIDs middleware, e.g. in
+// a Vercel function, it falls back to the ID assigned by the proxy, and
+// returns "" if there is none.
+func RequestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return incomingRequestID(r)
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// incomingRequestID returns the request ID set by the client or by Vercel
+func incomingRequestID(r *http.Request) string {
+	for _, header := range []string{RequestIDHeader, "X-Vercel-Id"} {
+		if id := r.Header.Get(header); validRequestID(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// validRequestID accepts short IDs made of URL-safe characters, so a client
+// cannot inject anything into logs or headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
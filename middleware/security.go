@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// NoncePlaceholder is replaced by the per-request nonce in a
+// Content-Security-Policy template.
+const NoncePlaceholder = "{nonce}"
+
+// SecurityOptions configures the SecurityHeaders middleware.
+type SecurityOptions struct {
+	// ContentSecurityPolicy is the policy template. Every NoncePlaceholder is
+	// replaced by a fresh nonce for each request.
+	ContentSecurityPolicy string
+	// ReportOnly sends the policy as Content-Security-Policy-Report-Only, so
+	// violations are reported but not blocked.
+	ReportOnly bool
+	// ReferrerPolicy is the value of the Referrer-Policy header.
+	ReferrerPolicy string
+	// PermissionsPolicy is the value of the Permissions-Policy header.
+	PermissionsPolicy string
+}
+
+// DefaultSecurityOptions allows the CDNs the web UI loads Tailwind, Monaco
+// and Vercel Analytics from, and reports violations to /csp-report.
+var DefaultSecurityOptions = SecurityOptions{
+	ContentSecurityPolicy: strings.Join([]string{
+		"default-src 'self'",
+		"script-src 'self' 'nonce-" + NoncePlaceholder + "' https://cdn.tailwindcss.com https://cdnjs.cloudflare.com https://esm.sh https://unpkg.com https://va.vercel-scripts.com",
+		// Tailwind and Monaco inject styles at runtime
+		"style-src 'self' 'unsafe-inline' https://cdnjs.cloudflare.com",
+		"font-src 'self' data: https://cdnjs.cloudflare.com",
+		"img-src 'self' data: https:",
+		"connect-src 'self' https://esm.sh https://va.vercel-scripts.com https://vitals.vercel-insights.com",
+		"worker-src 'self' blob:",
+		"object-src 'none'",
+		"base-uri 'self'",
+		"form-action 'self'",
+		"frame-ancestors 'none'",
+		"report-uri /csp-report",
+	}, "; "),
+	ReferrerPolicy:    "strict-origin-when-cross-origin",
+	PermissionsPolicy: "camera=(), microphone=(), geolocation=(), payment=(), usb=(), interest-cohort=()",
+}
+
+type nonceKey struct{}
+
+// SecurityHeaders sets the Content-Security-Policy and related hardening
+// headers on every response. The nonce of the request is available to
+// handlers through Nonce.
+func SecurityHeaders(next http.Handler, opts SecurityOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		if opts.ReferrerPolicy != "" {
+			h.Set("Referrer-Policy", opts.ReferrerPolicy)
+		}
+		if opts.PermissionsPolicy != "" {
+			h.Set("Permissions-Policy", opts.PermissionsPolicy)
+		}
+
+		if opts.ContentSecurityPolicy != "" {
+			nonce := newNonce()
+			policy := strings.ReplaceAll(opts.ContentSecurityPolicy, NoncePlaceholder, nonce)
+			if opts.ReportOnly {
+				h.Set("Content-Security-Policy-Report-Only", policy)
+			} else {
+				h.Set("Content-Security-Policy", policy)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), nonceKey{}, nonce))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Nonce returns the CSP nonce of the request, or "" when the request did not
+// pass through SecurityHeaders.
+func Nonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(nonceKey{}).(string)
+	return nonce
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("middleware: cannot read random nonce: " + err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// maxReportSize bounds the body of a CSP violation report.
+const maxReportSize = 64 << 10
+
+// CSPReport logs Content-Security-Policy violation reports sent by browsers,
+// in both the report-uri and the Reporting API formats.
+func CSPReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxReportSize))
+	if err != nil {
+		http.Error(w, "Report too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// report-uri sends {"csp-report": {...}}, the Reporting API an array
+	var legacy struct {
+		Report map[string]any `json:"csp-report"`
+	}
+	var reports []struct {
+		Type string         `json:"type"`
+		Body map[string]any `json:"body"`
+	}
+	switch {
+	case json.Unmarshal(body, &legacy) == nil && legacy.Report != nil:
+		logViolation(legacy.Report)
+	case json.Unmarshal(body, &reports) == nil:
+		for _, report := range reports {
+			if report.Type == "csp-violation" {
+				logViolation(report.Body)
+			}
+		}
+	default:
+		log.Printf("CSP report: unparseable body: %q", body)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func logViolation(report map[string]any) {
+	directive := report["violated-directive"]
+	if directive == nil {
+		directive = report["effectiveDirective"]
+	}
+	blocked := report["blocked-uri"]
+	if blocked == nil {
+		blocked = report["blockedURL"]
+	}
+	document := report["document-uri"]
+	if document == nil {
+		document = report["documentURL"]
+	}
+	log.Printf("CSP violation: directive=%v blocked=%v document=%v", directive, blocked, document)
+}
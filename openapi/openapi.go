@@ -0,0 +1,268 @@
+// Package openapi builds OpenAPI 3 documents whose schemas are derived from
+// Go types by reflection, so the published contract cannot drift from the
+// structs the handlers decode and encode.
+//
+// Schemas follow encoding/json: fields are named by their json tag, embedded
+// structs are flattened and unexported fields are skipped. Three more struct
+// tags refine a field:
+//
+//	doc:"..."         the field description
+//	required:"true"   the field must be present
+//	default:"..."     the value assumed when the field is omitted
+//
+// Named string types implementing EnumValues() []string become enums.
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	types      map[reflect.Type]string
+}
+
+// Info describes the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+// Operation is an API operation.
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a query or path parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body of a request.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a response.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the schema of a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas, keyed by Go type name.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema in the OpenAPI dialect.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// enum is implemented by named string types with a fixed set of values.
+type enum interface {
+	EnumValues() []string
+}
+
+var (
+	enumType = reflect.TypeOf((*enum)(nil)).Elem()
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// New returns an empty document.
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+		types:      make(map[reflect.Type]string),
+	}
+}
+
+// Add registers op for method on path.
+func (d *Document) Add(method, path string, op *Operation) {
+	if d.Paths[path] == nil {
+		d.Paths[path] = make(PathItem)
+	}
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+// SchemaOf returns the schema of the type of v. Named structs and enums are
+// added to the components and referenced.
+func (d *Document) SchemaOf(v any) *Schema {
+	return d.schema(reflect.TypeOf(v))
+}
+
+// JSONBody returns a required JSON request body of the type of v.
+func (d *Document) JSONBody(v any) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{"application/json": {Schema: d.SchemaOf(v)}},
+	}
+}
+
+// JSONResponse returns a response with a JSON body of the type of v.
+func (d *Document) JSONResponse(description string, v any) Response {
+	return d.ContentResponse(description, "application/json", v)
+}
+
+// ContentResponse returns a response with a body of the type of v in the
+// given content type.
+func (d *Document) ContentResponse(description, contentType string, v any) Response {
+	return Response{
+		Description: description,
+		Content:     map[string]MediaType{contentType: {Schema: d.SchemaOf(v)}},
+	}
+}
+
+func (d *Document) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Implements(enumType) && t.Kind() == reflect.String {
+		return d.component(t, func() *Schema {
+			values := reflect.Zero(t).Interface().(enum).EnumValues()
+			return &Schema{Type: "string", Enum: values}
+		})
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return d.component(t, func() *Schema { return d.structSchema(t) })
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// Interfaces and other dynamic values accept anything
+		return &Schema{}
+	}
+}
+
+// component registers the schema of a named type once and returns a
+// reference to it
+func (d *Document) component(t reflect.Type, build func() *Schema) *Schema {
+	name, ok := d.types[t]
+	if !ok {
+		name = t.Name()
+		for n := 2; d.Components.Schemas[name] != nil; n++ {
+			name = t.Name() + strconv.Itoa(n)
+		}
+		d.types[t] = name
+		// Reserve the name before building, for recursive types
+		d.Components.Schemas[name] = &Schema{}
+		*d.Components.Schemas[name] = *build()
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(s, t)
+	return s
+}
+
+// addFields adds the JSON fields of struct type t to s, flattening embedded
+// structs the way encoding/json does
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				d.addFields(s, ft)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := d.schema(field.Type)
+		doc, def := field.Tag.Get("doc"), field.Tag.Get("default")
+		if prop.Ref != "" && (doc != "" || def != "") {
+			// Siblings of $ref are ignored in OpenAPI 3.0, so wrap it
+			prop = &Schema{AllOf: []*Schema{prop}}
+		}
+		prop.Description = doc
+		if def != "" {
+			prop.Default = def
+		}
+		s.Properties[name] = prop
+		if field.Tag.Get("required") == "true" {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
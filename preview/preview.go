@@ -0,0 +1,145 @@
+// Package preview renders untrusted HTML for the live preview. The markup is
+// sanitized, wrapped in a standalone document and kept under a random token
+// for a short time. Documents are served with ContentSecurityPolicy, whose
+// sandbox directive gives them an opaque origin: even opened directly, a
+// preview cannot run scripts or reach the cookies, storage or API of the
+// application.
+package preview
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"html"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"html2go-converter/errcode"
+)
+
+// Errors returned for preview operations
+var (
+	ErrNotFound          = errcode.New(http.StatusNotFound, "preview_not_found", "Preview not found or expired")
+	ErrUnknownStylesheet = errcode.New(http.StatusBadRequest, "stylesheet_unavailable", "Stylesheet is not available").WithField("stylesheets")
+)
+
+// ContentSecurityPolicy is the policy of preview documents. Only inline
+// styles, the vendored stylesheets, images and fonts may load; sandbox
+// without allow-scripts or allow-same-origin disables scripts and isolates
+// the document in an opaque origin.
+var ContentSecurityPolicy = strings.Join([]string{
+	"default-src 'none'",
+	"style-src 'self' 'unsafe-inline'",
+	"img-src 'self' data: https:",
+	"font-src 'self' data: https:",
+	"media-src data: https:",
+	"base-uri 'none'",
+	"form-action 'none'",
+	"frame-ancestors 'self'",
+	"sandbox",
+}, "; ")
+
+// AssetPath is the URL path the vendored stylesheets are served under
+const AssetPath = "/preview/assets/"
+
+// Stylesheets maps the stylesheets a preview can include to their file in
+// the vendor directory
+var Stylesheets = map[string]string{
+	"tailwind": "tailwind.css",
+	"vuetify":  "vuetify.css",
+}
+
+// vendor holds the stylesheets built into the binary, see vendor/README.md.
+// The minified builds keep the licence headers of their projects.
+//
+//go:generate curl -fsSL -o vendor/tailwind.css https://unpkg.com/tailwindcss@2.2.19/dist/tailwind.min.css
+//go:generate curl -fsSL -o vendor/vuetify.css https://unpkg.com/vuetify@2.7.2/dist/vuetify.min.css
+//go:embed vendor
+var vendor embed.FS
+
+// Vendored returns the stylesheets built into the binary
+func Vendored() fs.FS {
+	sub, _ := fs.Sub(vendor, "vendor")
+	return sub
+}
+
+// Available returns the names of the stylesheets present in assets, sorted
+func Available(assets fs.FS) []string {
+	names := []string{}
+	for name, file := range Stylesheets {
+		if _, err := fs.Stat(assets, file); err == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Stylesheet returns the content of the stylesheet name from assets, or
+// ErrUnknownStylesheet
+func Stylesheet(assets fs.FS, name string) ([]byte, error) {
+	file, ok := Stylesheets[name]
+	if !ok {
+		return nil, ErrUnknownStylesheet
+	}
+	data, err := fs.ReadFile(assets, file)
+	if err != nil {
+		return nil, ErrUnknownStylesheet
+	}
+	return data, nil
+}
+
+// Render sanitizes input and returns it as a standalone document linking
+// the given stylesheets, which must be available in assets, with the names
+// of the elements and attributes that were removed
+func Render(assets fs.FS, input string, stylesheets []string) (document string, removed []string, err error) {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n")
+	b.WriteString("<meta charset=\"utf-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	b.WriteString("<title>Preview</title>\n")
+	for _, name := range stylesheets {
+		if _, err := Stylesheet(assets, name); err != nil {
+			return "", nil, err
+		}
+		b.WriteString("<link rel=\"stylesheet\" href=\"" + html.EscapeString(AssetPath+name+".css") + "\">\n")
+	}
+
+	body, removed, err := Sanitize(input)
+	if err != nil {
+		return "", nil, err
+	}
+	b.WriteString("</head>\n<body>\n")
+	b.WriteString(body)
+	b.WriteString("\n</body>\n</html>\n")
+	return b.String(), removed, nil
+}
+
+// Preview is a rendered document kept under a token
+type Preview struct {
+	Token     string
+	Document  string
+	ExpiresAt time.Time
+}
+
+// validToken matches the tokens generated by newToken
+var validToken = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// ValidToken reports whether token has the form of a preview token
+func ValidToken(token string) bool {
+	return validToken.MatchString(token)
+}
+
+// newToken returns 128 random bits: previews hold user input and are only
+// reachable by whoever knows the token
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("preview: cannot read random token: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,156 @@
+package preview
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// removedElements are dropped with their content: they run scripts, embed
+// other documents or change how the preview document itself behaves
+var removedElements = map[string]bool{
+	"script":   true,
+	"noscript": true,
+	"iframe":   true,
+	"frame":    true,
+	"frameset": true,
+	"object":   true,
+	"embed":    true,
+	"applet":   true,
+	"portal":   true,
+	"base":     true,
+	"link":     true,
+	"meta":     true,
+	"title":    true,
+}
+
+// removedAttributes are dropped wherever they appear
+var removedAttributes = map[string]bool{
+	"srcdoc":     true,
+	"http-equiv": true,
+}
+
+// urlAttributes hold URLs, dropped when they are script URLs. to, from and
+// values are those of SVG animations, which can rewrite an href.
+var urlAttributes = map[string]bool{
+	"href":       true,
+	"src":        true,
+	"action":     true,
+	"formaction": true,
+	"poster":     true,
+	"background": true,
+	"cite":       true,
+	"data":       true,
+	"to":         true,
+	"from":       true,
+	"values":     true,
+}
+
+// Sanitize parses input as an HTML document and returns the content of its
+// body, preceded by the style elements of its head, without scripts, event
+// handlers or script URLs. It also returns the sorted names of what was
+// removed: elements as "<name>", attributes by name.
+//
+// The preview is sandboxed by its Content-Security-Policy anyway; removing
+// scripts keeps the markup honest about what will render.
+func Sanitize(input string) (string, []string, error) {
+	doc, err := html.Parse(strings.NewReader(input))
+	if err != nil {
+		return "", nil, err
+	}
+
+	seen := map[string]bool{}
+	clean(doc, seen)
+
+	var b strings.Builder
+	for _, section := range []atom.Atom{atom.Head, atom.Body} {
+		parent := find(doc, section)
+		if parent == nil {
+			continue
+		}
+		for c := parent.FirstChild; c != nil; c = c.NextSibling {
+			// Only styles are kept from the head
+			if section == atom.Head && !(c.Type == html.ElementNode && c.DataAtom == atom.Style) {
+				continue
+			}
+			if err := html.Render(&b, c); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	removed := make([]string, 0, len(seen))
+	for name := range seen {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	return strings.TrimSpace(b.String()), removed, nil
+}
+
+// clean removes the unsafe descendants and attributes of n, recording their
+// names in removed
+func clean(n *html.Node, removed map[string]bool) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == html.CommentNode:
+			n.RemoveChild(c)
+		case c.Type == html.ElementNode && removedElements[strings.ToLower(c.Data)]:
+			removed["<"+strings.ToLower(c.Data)+">"] = true
+			n.RemoveChild(c)
+		default:
+			if c.Type == html.ElementNode {
+				c.Attr = cleanAttributes(c.Attr, removed)
+			}
+			clean(c, removed)
+		}
+		c = next
+	}
+}
+
+func cleanAttributes(attrs []html.Attribute, removed map[string]bool) []html.Attribute {
+	kept := attrs[:0]
+	for _, a := range attrs {
+		key := strings.ToLower(a.Key)
+		if strings.HasPrefix(key, "on") || removedAttributes[key] || (urlAttributes[key] && unsafeURL(a.Val)) {
+			removed[key] = true
+			continue
+		}
+		kept = append(kept, a)
+	}
+	return kept
+}
+
+// unsafeURL reports whether v is a script URL, or a data URL of anything
+// but an image. Browsers ignore whitespace and control characters inside
+// the scheme, so they are ignored here too.
+func unsafeURL(v string) bool {
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(v))
+	switch {
+	case strings.HasPrefix(v, "javascript:"), strings.HasPrefix(v, "vbscript:"):
+		return true
+	case strings.HasPrefix(v, "data:"):
+		return !strings.HasPrefix(v, "data:image/")
+	}
+	return false
+}
+
+// find returns the first element a in the tree of n
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package preview
+
+import (
+	"sync"
+	"time"
+)
+
+// Defaults of the store of the web UI
+const (
+	DefaultTTL        = time.Hour
+	DefaultMaxEntries = 1000
+)
+
+// Store keeps rendered previews in memory for a fixed time, dropping the
+// oldest ones beyond a maximum number. It is safe for concurrent use.
+type Store struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	previews map[string]Preview
+	// order holds the tokens from oldest to newest
+	order []string
+}
+
+// NewStore returns a store keeping up to maxEntries previews for ttl each
+func NewStore(maxEntries int, ttl time.Duration) *Store {
+	return &Store{ttl: ttl, maxEntries: max(maxEntries, 1), previews: make(map[string]Preview)}
+}
+
+// Put stores document under a new token and returns the preview
+func (s *Store) Put(document string, now time.Time) Preview {
+	p := Preview{Token: newToken(), Document: document, ExpiresAt: now.Add(s.ttl)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	for len(s.order) >= s.maxEntries {
+		delete(s.previews, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.previews[p.Token] = p
+	s.order = append(s.order, p.Token)
+	return p
+}
+
+// Get returns the preview with the given token, or ErrNotFound if it is
+// unknown or expired at now
+func (s *Store) Get(token string, now time.Time) (Preview, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.previews[token]
+	if !ok || !now.Before(p.ExpiresAt) {
+		return Preview{}, ErrNotFound
+	}
+	return p, nil
+}
+
+// Len returns the number of stored previews, including expired ones not yet
+// swept
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.previews)
+}
+
+// sweep drops the previews expired at now. Every preview lives for the same
+// time, so they expire in insertion order.
+func (s *Store) sweep(now time.Time) {
+	n := 0
+	for n < len(s.order) && !now.Before(s.previews[s.order[n]].ExpiresAt) {
+		delete(s.previews, s.order[n])
+		n++
+	}
+	s.order = s.order[n:]
+}
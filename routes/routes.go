@@ -0,0 +1,172 @@
+// Package routes is the single source of truth for the URL layout of the
+// application. The local server registers the table on a ServeMux and
+// vercel.json is generated from it, so local runs mirror production.
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	handler "html2go-converter/api"
+	"html2go-converter/middleware"
+)
+
+// Route maps a request path to its handler locally and to its destination
+// on Vercel.
+type Route struct {
+	// Src is the Vercel route regular expression. Empty for routes that only
+	// exist on the local server.
+	Src string
+	// Dest is the Vercel destination: a serverless function under /api or a
+	// static file under /web/public.
+	Dest string
+	// Pattern is the ServeMux pattern serving the route locally. Empty when
+	// the path is already covered by the pattern of another route.
+	Pattern string
+	// Handler serves Pattern locally.
+	Handler http.HandlerFunc
+}
+
+// Table lists the routes in Vercel matching order: the first route whose Src
+// matches wins.
+var Table = []Route{
+	{Src: "/api/v1/(.*)", Dest: "/api/v1.go", Pattern: "/api/v1/", Handler: handler.V1Handler},
+	{Src: "/api/convert/batch", Dest: "/api/batch.go", Pattern: "/api/convert/batch", Handler: handler.BatchHandler},
+	{Src: "/api/convert/archive", Dest: "/api/archive.go", Pattern: "/api/convert/archive", Handler: handler.ArchiveHandler},
+	{Src: "/api/convert", Dest: "/api/convert.go", Pattern: "/api/convert", Handler: handler.Handler},
+	{Src: "/convert", Dest: "/api/convert.go", Pattern: "/convert", Handler: handler.Handler},
+	{Src: "/api/jobs(/.*)?", Dest: "/api/jobs.go", Pattern: "/api/jobs/", Handler: handler.JobsHandler},
+	{Pattern: "/api/jobs", Handler: handler.JobsHandler},
+	{Src: "/api/results/(.*)", Dest: "/api/results.go", Pattern: "/api/results/", Handler: handler.ResultsHandler},
+	{Src: "/api/examples(/.*)?", Dest: "/api/examples.go", Pattern: "/api/examples/", Handler: handler.ExamplesHandler},
+	{Pattern: "/api/examples", Handler: handler.ExamplesHandler},
+	{Src: "/api/history(/.*)?", Dest: "/api/history.go", Pattern: "/api/history/", Handler: handler.HistoryHandler},
+	{Pattern: "/api/history", Handler: handler.HistoryHandler},
+	{Src: "/api/snippets(/.*)?", Dest: "/api/snippets.go", Pattern: "/api/snippets/", Handler: handler.SnippetsHandler},
+	{Pattern: "/api/snippets", Handler: handler.SnippetsHandler},
+	// Shared snippets open the editor rendered with their content
+	{Src: "/s/(.*)", Dest: "/api/snippets.go", Pattern: "/s/", Handler: handler.SnippetsHandler},
+	{Src: "/api/preview", Dest: "/api/preview.go", Pattern: "/api/preview", Handler: handler.PreviewHandler},
+	// Previews are sandboxed documents outside /api, under their own policy
+	{Src: "/preview/(.*)", Dest: "/api/preview.go", Pattern: "/preview/", Handler: handler.PreviewHandler},
+	// Vercel functions cannot hold WebSockets open
+	{Pattern: "/api/live", Handler: handler.LiveHandler},
+	{Pattern: "/csp-report", Handler: middleware.CSPReport},
+	// Fingerprinted assets only exist in the in-memory asset catalog
+	{Src: `/(static/)?(.*\.[0-9a-f]{10}\.(js|css|png|jpg|gif|svg|ico))`, Dest: "/api/index.go"},
+	{Src: "/static/(.*)", Dest: "/web/public/$1", Pattern: "/static/", Handler: handler.Index},
+	{Src: `/(.*\.(js|css|png|jpg|gif|svg|ico))`, Dest: "/web/public/$1"},
+	// index.html is rendered by the server with the runtime configuration
+	{Src: "/(.*)", Dest: "/api/index.go", Pattern: "/", Handler: handler.Index},
+}
+
+// Register adds every local route of the table to mux.
+func Register(mux *http.ServeMux) {
+	for _, route := range Table {
+		if route.Pattern != "" {
+			mux.HandleFunc(route.Pattern, route.Handler)
+		}
+	}
+}
+
+// NewHandler returns the complete application: the route table wrapped in
+// the request ID, security headers and compression middleware. The local server and the
+// serverless adapters all serve this handler.
+func NewHandler(security middleware.SecurityOptions) http.Handler {
+	mux := http.NewServeMux()
+	Register(mux)
+	return middleware.RequestIDs(middleware.Compress(middleware.SecurityHeaders(mux, security), middleware.DefaultCompressOptions))
+}
+
+// VercelRoute is an entry of the "routes" array of vercel.json.
+type VercelRoute struct {
+	Src  string `json:"src"`
+	Dest string `json:"dest"`
+}
+
+// VercelBuild is an entry of the "builds" array of vercel.json.
+type VercelBuild struct {
+	Src string `json:"src"`
+	Use string `json:"use"`
+}
+
+// VercelConfig is the subset of vercel.json the project uses.
+type VercelConfig struct {
+	Version int           `json:"version"`
+	Builds  []VercelBuild `json:"builds"`
+	Routes  []VercelRoute `json:"routes"`
+}
+
+// Vercel returns the vercel.json configuration derived from the table.
+func Vercel() VercelConfig {
+	config := VercelConfig{
+		Version: 2,
+		Builds: []VercelBuild{
+			{Src: "api/**/*.go", Use: "@vercel/go"},
+			{Src: "web/public/**/*", Use: "@vercel/static"},
+		},
+	}
+	for _, route := range Table {
+		if route.Src != "" {
+			config.Routes = append(config.Routes, VercelRoute{Src: route.Src, Dest: route.Dest})
+		}
+	}
+	return config
+}
+
+// VercelJSON renders the vercel.json file content, one build or route per
+// line so the file stays easy to review.
+func VercelJSON() ([]byte, error) {
+	config := Vercel()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "{\n  \"version\": %d,\n  \"builds\": [\n", config.Version)
+	for i, build := range config.Builds {
+		if err := writeEntry(&buf, i == len(config.Builds)-1, "src", build.Src, "use", build.Use); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("  ],\n  \"routes\": [\n")
+	for i, route := range config.Routes {
+		if err := writeEntry(&buf, i == len(config.Routes)-1, "src", route.Src, "dest", route.Dest); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("  ]\n}\n")
+	return buf.Bytes(), nil
+}
+
+// writeEntry writes a single-line JSON object of key/value string pairs.
+func writeEntry(buf *bytes.Buffer, last bool, pairs ...string) error {
+	buf.WriteString("    {")
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		value, err := marshalString(pairs[i+1])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, " %q: %s", pairs[i], value)
+	}
+	buf.WriteString(" }")
+	if !last {
+		buf.WriteString(",")
+	}
+	buf.WriteString("\n")
+	return nil
+}
+
+// marshalString encodes s as a JSON string without HTML escaping.
+func marshalString(s string) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,52 @@
+package serverless
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/fcgi"
+	"os"
+)
+
+// IsCGI reports whether the process was started by a web server as a CGI
+// script.
+func IsCGI() bool {
+	return os.Getenv("GATEWAY_INTERFACE") != "" && os.Getenv("REQUEST_METHOD") != ""
+}
+
+// ServeCGI serves the single request described by the CGI environment and
+// standard input.
+func ServeCGI(h http.Handler) error {
+	return cgi.Serve(h)
+}
+
+// ServeFastCGI serves FastCGI requests with h. With an empty addr it accepts
+// connections on the listener passed as standard input, as spawned by
+// mod_fcgid or spawn-fcgi; otherwise it listens on addr, which is a TCP
+// address or, when it starts with "/", a Unix socket path. A stale socket
+// at that path is replaced, but any other file is left alone.
+func ServeFastCGI(h http.Handler, addr string) error {
+	if addr == "" {
+		return fcgi.Serve(nil, h)
+	}
+
+	network := "tcp"
+	if addr[0] == '/' {
+		network = "unix"
+		if info, err := os.Lstat(addr); err == nil {
+			if info.Mode()&os.ModeSocket == 0 {
+				return fmt.Errorf("%s exists and is not a socket", addr)
+			}
+			if err := os.Remove(addr); err != nil {
+				return err
+			}
+		}
+	}
+	listener, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	return fcgi.Serve(listener, h)
+}
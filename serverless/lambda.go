@@ -0,0 +1,230 @@
+// Package serverless runs the application handler on hosts other than the
+// standalone server: AWS Lambda function URLs, CGI and FastCGI.
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LambdaRequest is the API Gateway v2 (HTTP API and function URL) event
+// payload, format version 2.0.
+type LambdaRequest struct {
+	Version         string            `json:"version"`
+	RawPath         string            `json:"rawPath"`
+	RawQueryString  string            `json:"rawQueryString"`
+	Cookies         []string          `json:"cookies,omitempty"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body,omitempty"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+	RequestContext  struct {
+		DomainName string `json:"domainName"`
+		RequestID  string `json:"requestId"`
+		HTTP       struct {
+			Method   string `json:"method"`
+			Path     string `json:"path"`
+			SourceIP string `json:"sourceIp"`
+		} `json:"http"`
+	} `json:"requestContext"`
+}
+
+// LambdaResponse is the API Gateway v2 response payload.
+type LambdaResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Cookies         []string          `json:"cookies,omitempty"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// HandleLambdaEvent serves a single API Gateway v2 event with h.
+func HandleLambdaEvent(ctx context.Context, h http.Handler, event LambdaRequest) (LambdaResponse, error) {
+	req, err := newLambdaHTTPRequest(ctx, event)
+	if err != nil {
+		return LambdaResponse{}, err
+	}
+
+	w := newBufferedWriter()
+	h.ServeHTTP(w, req)
+	return newLambdaResponse(w.status, w.header, w.body.Bytes()), nil
+}
+
+// newLambdaHTTPRequest converts an event into an *http.Request.
+func newLambdaHTTPRequest(ctx context.Context, event LambdaRequest) (*http.Request, error) {
+	body := []byte(event.Body)
+	if event.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(event.Body)
+		if err != nil {
+			return nil, fmt.Errorf("decode lambda body: %w", err)
+		}
+		body = decoded
+	}
+
+	path := event.RawPath
+	if path == "" {
+		path = event.RequestContext.HTTP.Path
+	}
+	target := path
+	if event.RawQueryString != "" {
+		target += "?" + event.RawQueryString
+	}
+
+	req, err := http.NewRequestWithContext(ctx, event.RequestContext.HTTP.Method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request from lambda event: %w", err)
+	}
+	for name, value := range event.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(event.Cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
+	req.Host = req.Header.Get("Host")
+	if req.Host == "" {
+		req.Host = event.RequestContext.DomainName
+	}
+	req.RemoteAddr = event.RequestContext.HTTP.SourceIP
+	req.ContentLength = int64(len(body))
+	req.RequestURI = target
+	return req, nil
+}
+
+// newLambdaResponse converts a buffered response into the event response,
+// base64-encoding bodies that are not plain text.
+func newLambdaResponse(status int, header http.Header, body []byte) LambdaResponse {
+	out := LambdaResponse{StatusCode: status, Headers: make(map[string]string)}
+	for name, values := range header {
+		if name == "Set-Cookie" {
+			out.Cookies = append(out.Cookies, values...)
+			continue
+		}
+		out.Headers[name] = strings.Join(values, ", ")
+	}
+
+	if isTextual(header) {
+		out.Body = string(body)
+	} else {
+		out.Body = base64.StdEncoding.EncodeToString(body)
+		out.IsBase64Encoded = true
+	}
+	return out
+}
+
+// isTextual reports whether a response body can be sent as a UTF-8 string.
+func isTextual(h http.Header) bool {
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/javascript" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml")
+}
+
+// runtimeAPIVersion is the path prefix of the Lambda Runtime API.
+const runtimeAPIVersion = "/2018-06-01/runtime"
+
+// IsLambda reports whether the process runs inside AWS Lambda.
+func IsLambda() bool {
+	return os.Getenv("AWS_LAMBDA_RUNTIME_API") != ""
+}
+
+// StartLambda serves Lambda invocations with h until the runtime shuts the
+// process down. It talks to the Runtime API named by AWS_LAMBDA_RUNTIME_API,
+// so the binary runs on the provided.al2023 custom runtime as "bootstrap".
+func StartLambda(h http.Handler) error {
+	return RunLambda(context.Background(), os.Getenv("AWS_LAMBDA_RUNTIME_API"), h)
+}
+
+// RunLambda polls the Runtime API at host for invocations and answers each
+// with h. It returns when ctx is cancelled or the Runtime API fails.
+func RunLambda(ctx context.Context, host string, h http.Handler) error {
+	base := "http://" + host + runtimeAPIVersion
+	client := &http.Client{}
+
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/invocation/next", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("fetch next invocation: %w", err)
+		}
+		payload, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read invocation: %w", err)
+		}
+
+		requestID := resp.Header.Get("Lambda-Runtime-Aws-Request-Id")
+		invokeCtx, cancel := invocationContext(ctx, resp.Header.Get("Lambda-Runtime-Deadline-Ms"))
+		result, err := invoke(invokeCtx, h, payload)
+		cancel()
+
+		if err != nil {
+			log.Printf("Lambda invocation %s failed: %v", requestID, err)
+			postRuntime(client, base+"/invocation/"+requestID+"/error", map[string]string{
+				"errorMessage": err.Error(),
+				"errorType":    "InvocationError",
+			})
+			continue
+		}
+		if err := postRuntime(client, base+"/invocation/"+requestID+"/response", result); err != nil {
+			return fmt.Errorf("post invocation response: %w", err)
+		}
+	}
+}
+
+// invocationContext applies the invocation deadline given in milliseconds
+// since the epoch.
+func invocationContext(ctx context.Context, deadlineMs string) (context.Context, context.CancelFunc) {
+	ms, err := strconv.ParseInt(deadlineMs, 10, 64)
+	if err != nil {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, time.UnixMilli(ms))
+}
+
+func invoke(ctx context.Context, h http.Handler, payload []byte) (LambdaResponse, error) {
+	var event LambdaRequest
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return LambdaResponse{}, fmt.Errorf("decode lambda event: %w", err)
+	}
+	return HandleLambdaEvent(ctx, h, event)
+}
+
+func postRuntime(client *http.Client, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("runtime API returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package serverless
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// bufferedWriter collects a complete response in memory for hosts that
+// expect the whole response at once.
+type bufferedWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newBufferedWriter() *bufferedWriter {
+	return &bufferedWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *bufferedWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(p)
+}
+
+// Flush is a no-op; the response is sent when the handler returns.
+func (w *bufferedWriter) Flush() {}
@@ -0,0 +1,160 @@
+// Package snippets stores conversions under short IDs so they can be shared
+// as permalinks. A snippet keeps the HTML input, the conversion options and
+// the generated code until it expires; its creator receives a deletion token
+// that is stored only as a hash.
+package snippets
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/errcode"
+)
+
+// Errors returned for snippet operations
+var (
+	ErrNotFound      = errcode.New(http.StatusNotFound, "snippet_not_found", "Snippet not found")
+	ErrExists        = errors.New("Snippet ID already exists")
+	ErrInvalidToken  = errcode.New(http.StatusForbidden, "snippet_forbidden", "Invalid deletion token")
+	ErrInvalidExpiry = errcode.New(http.StatusBadRequest, "invalid_expiry", "Invalid expiry").WithField("expiresIn")
+)
+
+// Snippet is a stored conversion
+type Snippet struct {
+	ID string `json:"id"`
+	converter.Request
+	Code      string     `json:"code"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// TokenHash is the SHA-256 of the deletion token
+	TokenHash string `json:"tokenHash"`
+}
+
+// Expired reports whether the snippet has expired at now
+func (s Snippet) Expired(now time.Time) bool {
+	return s.ExpiresAt != nil && !now.Before(*s.ExpiresAt)
+}
+
+// CheckToken reports whether token is the deletion token of the snippet
+func (s Snippet) CheckToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(s.TokenHash)) == 1
+}
+
+// Expiries are the lifetimes a creator can choose from. Never is zero.
+var Expiries = map[string]time.Duration{
+	"1h":    time.Hour,
+	"1d":    24 * time.Hour,
+	"7d":    7 * 24 * time.Hour,
+	"30d":   30 * 24 * time.Hour,
+	"never": 0,
+}
+
+// ExpiryNames lists the keys of Expiries from shortest to longest, never last
+func ExpiryNames() []string {
+	names := make([]string, 0, len(Expiries))
+	for name := range Expiries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := Expiries[names[i]], Expiries[names[j]]
+		return a != 0 && (b == 0 || a < b)
+	})
+	return names
+}
+
+// Policy decides how long snippets live
+type Policy struct {
+	// DefaultExpiry is used when the creator chooses none
+	DefaultExpiry string
+	// MaxTTL caps the lifetime of snippets; zero allows snippets that never
+	// expire
+	MaxTTL time.Duration
+}
+
+// DefaultPolicy keeps snippets a week unless their creator chooses otherwise
+var DefaultPolicy = Policy{DefaultExpiry: "7d"}
+
+// TTL returns the lifetime of a snippet created with the given expiry name,
+// zero meaning it never expires
+func (p Policy) TTL(expiry string) (time.Duration, error) {
+	if expiry == "" {
+		expiry = p.DefaultExpiry
+	}
+	ttl, ok := Expiries[expiry]
+	if !ok {
+		return 0, fmt.Errorf("%w: must be one of %s", ErrInvalidExpiry, strings.Join(ExpiryNames(), ", "))
+	}
+	if p.MaxTTL > 0 && (ttl == 0 || ttl > p.MaxTTL) {
+		return 0, fmt.Errorf("%w: must not exceed %v", ErrInvalidExpiry, p.MaxTTL)
+	}
+	return ttl, nil
+}
+
+// New returns a snippet of req and its code with a fresh ID, and the
+// deletion token to hand to its creator
+func New(req converter.Request, code string, ttl time.Duration, now time.Time) (Snippet, string) {
+	token := randomHex(16)
+	s := Snippet{
+		ID:        newID(),
+		Request:   req,
+		Code:      code,
+		CreatedAt: now,
+		TokenHash: hashToken(token),
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		s.ExpiresAt = &expiresAt
+	}
+	return s, token
+}
+
+// idAlphabet avoids characters that are easily confused when read aloud
+const idAlphabet = "23456789abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// idLength gives about 46 bits of randomness
+const idLength = 8
+
+// newID returns a short random ID
+func newID() string {
+	b := make([]byte, idLength)
+	max := big.NewInt(int64(len(idAlphabet)))
+	for i := range b {
+		n, _ := rand.Int(rand.Reader, max)
+		b[i] = idAlphabet[n.Int64()]
+	}
+	return string(b)
+}
+
+// ValidID reports whether id has the form of a snippet ID
+func ValidID(id string) bool {
+	if len(id) != idLength {
+		return false
+	}
+	for _, c := range id {
+		if !strings.ContainsRune(idAlphabet, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
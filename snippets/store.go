@@ -0,0 +1,193 @@
+package snippets
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store persists snippets. Implementations must be safe for concurrent use.
+type Store interface {
+	// Create stores a new snippet, or returns ErrExists if its ID is taken
+	Create(s Snippet) error
+	// Get returns the snippet with the given ID, or ErrNotFound. Expired
+	// snippets may still be returned until they are swept.
+	Get(id string) (Snippet, error)
+	// Delete removes a snippet; deleting an unknown snippet is not an error
+	Delete(id string) error
+	// Sweep removes the snippets expired at now and returns their number
+	Sweep(now time.Time) (int, error)
+}
+
+// MemoryStore keeps snippets in memory. Snippets are lost when the process
+// exits.
+type MemoryStore struct {
+	mu       sync.Mutex
+	snippets map[string]Snippet
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{snippets: make(map[string]Snippet)}
+}
+
+// Create implements Store
+func (m *MemoryStore) Create(s Snippet) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.snippets[s.ID]; ok {
+		return ErrExists
+	}
+	m.snippets[s.ID] = s
+	return nil
+}
+
+// Get implements Store
+func (m *MemoryStore) Get(id string) (Snippet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.snippets[id]
+	if !ok {
+		return Snippet{}, ErrNotFound
+	}
+	return s, nil
+}
+
+// Delete implements Store
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.snippets, id)
+	return nil
+}
+
+// Sweep implements Store
+func (m *MemoryStore) Sweep(now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, s := range m.snippets {
+		if s.Expired(now) {
+			delete(m.snippets, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// snippetsBucket holds the snippets of a BoltStore, keyed by ID
+var snippetsBucket = []byte("snippets")
+
+// BoltStore keeps snippets in a BoltDB file
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// OpenBoltStore opens or creates the BoltDB file at path
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snippetsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Close closes the database file
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
+
+// Create implements Store
+func (b *BoltStore) Create(s Snippet) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(snippetsBucket)
+		if bucket.Get([]byte(s.ID)) != nil {
+			return ErrExists
+		}
+		return bucket.Put([]byte(s.ID), data)
+	})
+}
+
+// Get implements Store
+func (b *BoltStore) Get(id string) (Snippet, error) {
+	var s Snippet
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(snippetsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &s)
+	})
+	return s, err
+}
+
+// Delete implements Store
+func (b *BoltStore) Delete(id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snippetsBucket).Delete([]byte(id))
+	})
+}
+
+// Sweep implements Store
+func (b *BoltStore) Sweep(now time.Time) (int, error) {
+	n := 0
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(snippetsBucket)
+		var expired [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var s Snippet
+			if err := json.Unmarshal(v, &s); err != nil {
+				return err
+			}
+			if s.Expired(now) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	return n, err
+}
+
+// SweepEvery sweeps store every interval until ctx is done
+func SweepEvery(ctx context.Context, store Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if n, err := store.Sweep(now); err != nil {
+				log.Printf("Failed to sweep snippets: %v", err)
+			} else if n > 0 {
+				log.Printf("Removed %d expired snippets", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+	"html2go-converter/examples"
+	"html2go-converter/history"
+	"html2go-converter/jobs"
+	"html2go-converter/preview"
+	"html2go-converter/snippets"
+)
+
+// TestErrorCodes 测试各类错误返回稳定的错误码与字段路径
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+		body    string
+		status  int
+		code    apierror.Code
+		field   string
+	}{
+		{"invalid json", api.V1Handler, "/api/v1/convert", `{`, http.StatusBadRequest, apierror.CodeInvalidJSON, ""},
+		{"empty body", api.V1Handler, "/api/v1/convert", ``, http.StatusBadRequest, apierror.CodeBodyRequired, ""},
+		{"validation", api.V1Handler, "/api/v1/convert", `{"direction": "x"}`, http.StatusBadRequest, apierror.CodeValidationFailed, "html"},
+		{"not implemented", api.V1Handler, "/api/v1/convert", `{"html": "x", "direction": "go2html"}`, http.StatusNotImplemented, apierror.CodeNotImplemented, "direction"},
+		{"unknown path", api.V1Handler, "/api/v1/unknown", `{}`, http.StatusNotFound, apierror.CodeNotFound, ""},
+		{"legacy html required", api.Handler, "/api/convert", `{"html": ""}`, http.StatusBadRequest, apierror.CodeHTMLRequired, "html"},
+		{"legacy invalid json", api.Handler, "/api/convert", `nope`, http.StatusBadRequest, apierror.CodeInvalidJSON, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Errorf("Status = %d, want %d", rec.Code, tt.status)
+			}
+			var resp apierror.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Invalid body %q: %v", rec.Body.String(), err)
+			}
+			if resp.Code != tt.code || resp.Field != tt.field {
+				t.Errorf("Code, field = %q, %q, want %q, %q", resp.Code, resp.Field, tt.code, tt.field)
+			}
+			if resp.Error == "" {
+				t.Error("Error message is empty")
+			}
+			if resp.RequestID == "" || resp.RequestID != rec.Header().Get("X-Request-ID") {
+				t.Errorf("RequestID = %q, header = %q", resp.RequestID, rec.Header().Get("X-Request-ID"))
+			}
+		})
+	}
+}
+
+// TestFieldErrorCodes 测试字段错误包含错误码
+func TestFieldErrorCodes(t *testing.T) {
+	rec := postV1("/api/v1/convert", `{"childrenMode": "yes", "goCode": "x"}`)
+	var resp apierror.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if len(resp.Fields) != 1 {
+		t.Fatalf("Fields = %+v, want 1 field", resp.Fields)
+	}
+	if f := resp.Fields[0]; f.Field != "childrenMode" || f.Code != converter.FieldInvalidType {
+		t.Errorf("Field = %+v, want childrenMode %s", f, converter.FieldInvalidType)
+	}
+
+	rec = postV1("/api/v1/convert", `{"direction": "x"}`)
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	codes := map[string]string{}
+	for _, f := range resp.Fields {
+		codes[f.Field] = f.Code
+	}
+	if codes["html"] != converter.FieldRequired || codes["direction"] != converter.FieldInvalidValue {
+		t.Errorf("Field codes = %v", codes)
+	}
+}
+
+// TestErrorDetails 测试错误详情对象
+func TestErrorDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	api.V1Handler(rec, httptest.NewRequest(http.MethodDelete, "/api/v1/errors", nil))
+	var resp apierror.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Code != apierror.CodeMethodNotAllowed {
+		t.Fatalf("Code = %q, want %q", resp.Code, apierror.CodeMethodNotAllowed)
+	}
+	if allow, _ := resp.Details["allow"].([]any); len(allow) != 2 {
+		t.Errorf("Details = %v, want allowed methods", resp.Details)
+	}
+}
+
+// TestProblemJSON 测试按Accept返回RFC 7807问题文档并沿用请求ID
+func TestProblemJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/convert", strings.NewReader(`{"direction": "x"}`))
+	r.Header.Set("Accept", "application/problem+json, application/json;q=0.5")
+	r.Header.Set("X-Request-ID", "client-id-1")
+	api.V1Handler(rec, r)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", ct)
+	}
+	if id := rec.Header().Get("X-Request-ID"); id != "client-id-1" {
+		t.Errorf("X-Request-ID = %q, want client-id-1", id)
+	}
+	var problem apierror.Problem
+	json.Unmarshal(rec.Body.Bytes(), &problem)
+	if problem.Type != apierror.TypeBase+string(apierror.CodeValidationFailed) {
+		t.Errorf("Type = %q", problem.Type)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Title == "" || problem.Detail == "" {
+		t.Errorf("Problem = %+v", problem)
+	}
+	if problem.Instance != "/api/v1/convert" || problem.RequestID != "client-id-1" || len(problem.Fields) != 2 {
+		t.Errorf("Problem = %+v", problem)
+	}
+}
+
+// TestErrorCodeList 测试错误码列表覆盖每个错误码
+func TestErrorCodeList(t *testing.T) {
+	rec := httptest.NewRecorder()
+	api.V1Handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/errors", nil))
+	var codes []api.ErrorCode
+	json.Unmarshal(rec.Body.Bytes(), &codes)
+	if len(codes) != len(apierror.Titles) {
+		t.Fatalf("Listed %d codes, want %d", len(codes), len(apierror.Titles))
+	}
+	for _, c := range codes {
+		if c.Title != apierror.Titles[c.Code] {
+			t.Errorf("Title of %s = %q, want %q", c.Code, c.Title, apierror.Titles[c.Code])
+		}
+	}
+}
+
+// TestDomainErrorCodes 测试各领域包的错误携带已登记的错误码与状态
+func TestDomainErrorCodes(t *testing.T) {
+	for _, err := range []error{
+		converter.ErrHTMLRequired, converter.ErrInvalidDirection, converter.ErrNotImplemented,
+		converter.ErrEmptyBatch, converter.ErrBatchTooLarge, converter.ErrUnknownArchive,
+		converter.ErrArchiveTooLarge, converter.ErrArchiveNoHTML, converter.ErrInvalidGoPackage,
+		converter.ErrBodyTooLarge, converter.ErrTooDeep, converter.ErrTooManyNodes,
+		converter.ErrTimeout, converter.ErrQueueTimeout, converter.ErrConverterPanic,
+		&converter.ValidationError{}, jobs.ErrNotFound, jobs.ErrJobFinished, jobs.ErrQueueFull,
+		snippets.ErrNotFound, snippets.ErrInvalidToken, snippets.ErrInvalidExpiry,
+		examples.ErrNotFound, preview.ErrNotFound, preview.ErrUnknownStylesheet,
+		history.ErrNotFound, history.ErrRevisionNotFound, history.ErrHistoryFull,
+	} {
+		e := apierror.From(fmt.Errorf("wrapped: %w", err))
+		if _, ok := apierror.Titles[e.Code]; !ok || e.Status < 400 || e.Code == apierror.CodeConversionFailed {
+			t.Errorf("%v: code %q, status %d", err, e.Code, e.Status)
+		}
+	}
+
+	e := apierror.From(&converter.PanicError{ReproductionID: "abc"})
+	if e.Code != apierror.CodeConverterCrashed || e.Field != "html" || e.Details["reproductionId"] != "abc" {
+		t.Errorf("PanicError = %+v", e)
+	}
+}
+
+// TestLimitErrors 测试超出各项限制时的错误码
+func TestLimitErrors(t *testing.T) {
+	defer api.UseLimits(converter.CurrentLimits(), nil)
+	api.UseLimits(converter.Limits{MaxBodyBytes: 200, MaxDepth: 3, MaxNodes: 10}, nil)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		path    string
+		body    string
+		status  int
+		code    apierror.Code
+		detail  string
+	}{
+		{"body too large", api.V1Handler, "/api/v1/convert", `{"html": "` + strings.Repeat("a", 300) + `"}`, http.StatusRequestEntityTooLarge, apierror.CodeBodyTooLarge, "maxBytes"},
+		{"legacy body too large", api.Handler, "/api/convert", `{"html": "` + strings.Repeat("a", 300) + `"}`, http.StatusRequestEntityTooLarge, apierror.CodeBodyTooLarge, "maxBytes"},
+		{"too deep", api.V1Handler, "/api/v1/convert", `{"html": "<div><div><div><div>x</div></div></div></div>"}`, http.StatusUnprocessableEntity, apierror.CodeTooDeep, "maxDepth"},
+		{"too many nodes", api.V1Handler, "/api/v1/convert", `{"html": "` + strings.Repeat("<b>a</b>", 6) + `"}`, http.StatusUnprocessableEntity, apierror.CodeTooManyNodes, "maxNodes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			var resp apierror.Response
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			if rec.Code != tt.status || resp.Code != tt.code {
+				t.Fatalf("Status, code = %d, %q, want %d, %q", rec.Code, resp.Code, tt.status, tt.code)
+			}
+			if _, ok := resp.Details[tt.detail]; !ok {
+				t.Errorf("Details = %v, want %s", resp.Details, tt.detail)
+			}
+		})
+	}
+}
+
+// TestConverterCrash 测试转换器崩溃时返回结构化的500错误
+func TestConverterCrash(t *testing.T) {
+	rec := postV1("/api/v1/convert", `{"html": "<!---->"}`)
+	var resp apierror.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusInternalServerError || resp.Code != apierror.CodeConverterCrashed {
+		t.Fatalf("Status, code = %d, %q, want %d, %q", rec.Code, resp.Code, http.StatusInternalServerError, apierror.CodeConverterCrashed)
+	}
+	want := converter.ReproductionID(converter.Request{HTML: "<!---->", Direction: converter.DirectionHTMLToGo})
+	if resp.Details["reproductionId"] != want {
+		t.Errorf("Details = %v, want reproductionId %s", resp.Details, want)
+	}
+}
@@ -0,0 +1,50 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/examples"
+)
+
+// TestExamples 测试示例列表过滤与单个示例
+func TestExamples(t *testing.T) {
+	registry, err := examples.Load(examples.Embedded())
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.UseExamples(registry)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		api.ExamplesHandler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/examples?category=vuetify")
+	var list api.ExamplesResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || len(list.Examples) == 0 || len(list.Categories) < 2 {
+		t.Fatalf("List = %d %+v", rec.Code, list)
+	}
+	for _, e := range list.Examples {
+		if e.Category != "vuetify" {
+			t.Errorf("Example %s has category %q, want vuetify", e.ID, e.Category)
+		}
+	}
+
+	rec = get("/api/examples/" + list.Examples[0].ID)
+	var example examples.Example
+	json.Unmarshal(rec.Body.Bytes(), &example)
+	if rec.Code != http.StatusOK || example.HTML == "" || example.Code == "" || example.Options.VuetifyPrefix != "v" {
+		t.Errorf("Get = %d %+v", rec.Code, example)
+	}
+
+	rec = get("/api/examples/missing")
+	if code := errorCode(t, rec); rec.Code != http.StatusNotFound || code != "example_not_found" {
+		t.Errorf("Get missing = %d %q", rec.Code, code)
+	}
+}
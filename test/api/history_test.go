@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/api"
+	"html2go-converter/history"
+)
+
+func serveHistory(method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	api.HistoryHandler(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+// TestHistory 测试保存、列出、恢复和对比转换历史
+func TestHistory(t *testing.T) {
+	api.UseHistoryStore(history.NewMemoryStore(), history.DefaultPolicy)
+
+	rec := serveHistory(http.MethodPost, "/api/history", `{"html": "<p>one</p>", "packagePrefix": "h"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var first api.RevisionResponse
+	json.Unmarshal(rec.Body.Bytes(), &first)
+	if first.Number != 1 || !history.ValidID(first.HistoryID) || !strings.Contains(first.Code, "h.P(") {
+		t.Fatalf("First revision = %+v", first)
+	}
+	base := "/api/history/" + first.HistoryID
+
+	if rec := serveHistory(http.MethodPost, base+"/revisions", `{"html": "<p>one</p>", "packagePrefix": "h"}`); rec.Code != http.StatusOK {
+		t.Errorf("Saving the head again status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := serveHistory(http.MethodPost, base+"/revisions", `{"html": "<p>two</p><br>", "packagePrefix": "h"}`); rec.Code != http.StatusCreated {
+		t.Errorf("Saving a change status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+
+	rec = serveHistory(http.MethodPost, base+"/revisions/1/restore", "")
+	var restored api.RevisionResponse
+	json.Unmarshal(rec.Body.Bytes(), &restored)
+	if rec.Code != http.StatusCreated || restored.Number != 3 || restored.RestoredFrom != 1 || restored.HTML != "<p>one</p>" {
+		t.Errorf("Restore = %d %+v", rec.Code, restored)
+	}
+
+	rec = serveHistory(http.MethodGet, base+"/revisions", "")
+	var list api.HistoryResponse
+	json.Unmarshal(rec.Body.Bytes(), &list)
+	if rec.Code != http.StatusOK || list.Head != 3 || len(list.Revisions) != 3 || list.Revisions[1].URL != base+"/revisions/2" {
+		t.Errorf("List = %d %+v", rec.Code, list)
+	}
+
+	rec = serveHistory(http.MethodGet, base+"/diff?from=1&to=2", "")
+	var d history.Diff
+	json.Unmarshal(rec.Body.Bytes(), &d)
+	if rec.Code != http.StatusOK || d.From != 1 || d.To != 2 || len(d.HTML) != 2 || d.Go.Unified == "" {
+		t.Errorf("Diff = %d %+v", rec.Code, d)
+	}
+}
+
+// TestHistoryErrors 测试未知历史、版本和无效版本号
+func TestHistoryErrors(t *testing.T) {
+	api.UseHistoryStore(history.NewMemoryStore(), history.DefaultPolicy)
+	rec := serveHistory(http.MethodPost, "/api/history", `{"html": "<p>one</p>"}`)
+	var first api.RevisionResponse
+	json.Unmarshal(rec.Body.Bytes(), &first)
+	base := "/api/history/" + first.HistoryID
+
+	tests := []struct {
+		method, path string
+		status       int
+		code         string
+	}{
+		{http.MethodGet, "/api/history/0123456789abcdef/revisions", http.StatusNotFound, "history_not_found"},
+		{http.MethodGet, "/api/history/not-an-id/revisions", http.StatusNotFound, "history_not_found"},
+		{http.MethodGet, base + "/revisions/7", http.StatusNotFound, "revision_not_found"},
+		{http.MethodGet, base + "/revisions/x", http.StatusBadRequest, "invalid_revision"},
+		{http.MethodGet, base + "/diff?from=0", http.StatusBadRequest, "invalid_revision"},
+		{http.MethodDelete, base + "/revisions", http.StatusMethodNotAllowed, "method_not_allowed"},
+	}
+	for _, tt := range tests {
+		rec := serveHistory(tt.method, tt.path, "")
+		if code := errorCode(t, rec); rec.Code != tt.status || string(code) != tt.code {
+			t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, rec.Code, code, tt.status, tt.code)
+		}
+	}
+
+	// 客户端不能自选ID创建历史
+	const unknown = "/api/history/0123456789abcdef/revisions"
+	rec = serveHistory(http.MethodPost, unknown, `{"html": "<p>one</p>"}`)
+	if code := errorCode(t, rec); rec.Code != http.StatusNotFound || code != "history_not_found" {
+		t.Errorf("POST %s = %d %q, want 404", unknown, rec.Code, code)
+	}
+	if rec := serveHistory(http.MethodGet, unknown, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Saving to an unknown ID created the history")
+	}
+}
+
+// TestHistoryPolicy 测试历史数量和版本数的限制
+func TestHistoryPolicy(t *testing.T) {
+	api.UseHistoryStore(history.NewMemoryStore(), history.Policy{MaxHistories: 1, MaxRevisions: 1})
+	defer api.UseHistoryStore(history.NewMemoryStore(), history.DefaultPolicy)
+
+	var first, second api.RevisionResponse
+	json.Unmarshal(serveHistory(http.MethodPost, "/api/history", `{"html": "<p>one</p>"}`).Body.Bytes(), &first)
+	time.Sleep(time.Millisecond)
+	json.Unmarshal(serveHistory(http.MethodPost, "/api/history", `{"html": "<p>two</p>"}`).Body.Bytes(), &second)
+
+	if rec := serveHistory(http.MethodGet, "/api/history/"+first.HistoryID+"/revisions", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Oldest history = %d, want it evicted", rec.Code)
+	}
+	rec := serveHistory(http.MethodPost, "/api/history/"+second.HistoryID+"/revisions", `{"html": "<p>three</p>"}`)
+	if code := errorCode(t, rec); rec.Code != http.StatusConflict || code != "history_full" {
+		t.Errorf("Save to a full history = %d %q, want 409 history_full", rec.Code, code)
+	}
+}
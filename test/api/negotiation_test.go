@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+)
+
+// TestRawHTMLBody 测试text/html请求体与查询参数选项
+func TestRawHTMLBody(t *testing.T) {
+	for _, tt := range []struct {
+		handler http.HandlerFunc
+		path    string
+	}{
+		{api.Handler, "/api/convert"},
+		{api.V1Handler, "/api/v1/convert"},
+	} {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, tt.path+"?packagePrefix=h&childrenMode=true", strings.NewReader(`<ul><li>1</li></ul>`))
+		r.Header.Set("Content-Type", "text/html; charset=utf-8")
+		tt.handler(rec, r)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, body: %s", tt.path, rec.Code, rec.Body.String())
+		}
+
+		want, _ := converter.Convert(converter.Request{HTML: `<ul><li>1</li></ul>`, PackagePrefix: "h", ChildrenMode: true, Direction: converter.DirectionHTMLToGo})
+		var got converter.Response
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if got.Code != want.Code {
+			t.Errorf("%s code = %q, want %q", tt.path, got.Code, want.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/convert?childrenMode=maybe", strings.NewReader(`<p></p>`))
+	r.Header.Set("Content-Type", "text/html")
+	api.V1Handler(rec, r)
+	var resp apierror.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusBadRequest || resp.Field != "childrenMode" {
+		t.Errorf("Invalid option status = %d, field = %q", rec.Code, resp.Field)
+	}
+}
+
+// TestPlainCodeResponse 测试按Accept返回纯Go代码
+func TestPlainCodeResponse(t *testing.T) {
+	want, _ := converter.Convert(converter.Request{HTML: `<div>a</div>`, PackagePrefix: "h", Direction: converter.DirectionHTMLToGo})
+
+	tests := []struct {
+		accept      string
+		contentType string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"text/x-go", "text/x-go; charset=utf-8"},
+		{"text/plain", "text/plain; charset=utf-8"},
+		{"application/json, text/plain;q=0.5", "application/json"},
+		{"text/x-go, */*;q=0.1", "text/x-go; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/api/convert?packagePrefix=h", strings.NewReader(`<div>a</div>`))
+		r.Header.Set("Content-Type", "text/html")
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		api.Handler(rec, r)
+
+		if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, ct, tt.contentType)
+			continue
+		}
+		if strings.HasPrefix(tt.contentType, "text/") && strings.TrimSpace(rec.Body.String()) != strings.TrimSpace(want.Code) {
+			t.Errorf("Accept %q: body = %q, want %q", tt.accept, rec.Body.String(), want.Code)
+		}
+	}
+}
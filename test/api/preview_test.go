@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"html2go-converter/api"
+	"html2go-converter/middleware"
+	"html2go-converter/preview"
+	"html2go-converter/routes"
+)
+
+// TestPreview 测试创建预览以及预览文档的沙箱响应头
+func TestPreview(t *testing.T) {
+	assets := fstest.MapFS{"vuetify.css": {Data: []byte(".v-btn{}")}}
+	api.UsePreviews(preview.NewStore(10, time.Minute), assets)
+	app := routes.NewHandler(middleware.DefaultSecurityOptions)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/api/preview", "")
+	if body := strings.TrimSpace(rec.Body.String()); rec.Code != http.StatusOK || body != `{"stylesheets":["vuetify"]}` {
+		t.Errorf("Stylesheets = %d %s", rec.Code, body)
+	}
+
+	rec = serve(http.MethodPost, "/api/preview", `{"html": "<p onclick=\"x()\">hi</p><script>x()</script>", "stylesheets": ["vuetify"]}`)
+	var created api.PreviewResponse
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != http.StatusCreated || created.URL != "/preview/"+created.Token || len(created.Removed) != 2 {
+		t.Fatalf("Create = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodGet, created.URL, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<p>hi</p>") || strings.Contains(rec.Body.String(), "<script") {
+		t.Errorf("Preview = %d %s", rec.Code, rec.Body)
+	}
+	if csp := rec.Header().Get("Content-Security-Policy"); csp != preview.ContentSecurityPolicy {
+		t.Errorf("Content-Security-Policy = %q, want %q", csp, preview.ContentSecurityPolicy)
+	}
+	if xfo := rec.Header().Get("X-Frame-Options"); xfo != "SAMEORIGIN" {
+		t.Errorf("X-Frame-Options = %q, want SAMEORIGIN", xfo)
+	}
+
+	rec = serve(http.MethodGet, "/preview/assets/vuetify.css", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != ".v-btn{}" || rec.Header().Get("Content-Type") != "text/css; charset=utf-8" {
+		t.Errorf("Stylesheet = %d %q %q", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+
+	rec = serve(http.MethodPost, "/api/preview", `{"html": "<p>x</p>", "stylesheets": ["tailwind"]}`)
+	if code := errorCode(t, rec); rec.Code != http.StatusBadRequest || code != "stylesheet_unavailable" {
+		t.Errorf("Unavailable stylesheet = %d %q", rec.Code, code)
+	}
+	rec = serve(http.MethodPost, "/api/preview", `{"html": "  "}`)
+	if code := errorCode(t, rec); rec.Code != http.StatusBadRequest || code != "html_required" {
+		t.Errorf("Empty HTML = %d %q", rec.Code, code)
+	}
+	rec = serve(http.MethodGet, "/preview/0123456789abcdef0123456789abcdef", "")
+	if code := errorCode(t, rec); rec.Code != http.StatusNotFound || code != "preview_not_found" {
+		t.Errorf("Missing preview = %d %q", rec.Code, code)
+	}
+}
+
+// TestPreviewStylesheetLink 测试选择的样式表被预览文档引用并可以访问
+func TestPreviewStylesheetLink(t *testing.T) {
+	assets := fstest.MapFS{"tailwind.css": {Data: []byte("/*! tailwindcss */ .p-4{padding:1rem}")}}
+	api.UsePreviews(preview.NewStore(10, time.Minute), assets)
+	app := routes.NewHandler(middleware.DefaultSecurityOptions)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/api/preview", `{"html": "<p class=\"p-4\">x</p>", "stylesheets": ["tailwind"]}`)
+	var created api.PreviewResponse
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(http.MethodGet, created.URL, "")
+	href := preview.AssetPath + "tailwind.css"
+	if !strings.Contains(rec.Body.String(), `<link rel="stylesheet" href="`+href+`">`) {
+		t.Fatalf("Preview does not link %s:\n%s", href, rec.Body)
+	}
+	rec = serve(http.MethodGet, href, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != string(assets["tailwind.css"].Data) {
+		t.Errorf("Stylesheet = %d %q", rec.Code, rec.Body)
+	}
+
+	// 不可用的样式表被拒绝，而不是被静默忽略
+	rec = serve(http.MethodPost, "/api/preview", `{"html": "<p>x</p>", "stylesheets": ["vuetify"]}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "stylesheet_unavailable") {
+		t.Errorf("Unavailable stylesheet = %d %s", rec.Code, rec.Body)
+	}
+}
+
+// TestVendoredStylesheets 测试内嵌的样式表是固定的版本并保留许可证头
+func TestVendoredStylesheets(t *testing.T) {
+	vendored := preview.Vendored()
+	want := map[string]string{"tailwind": "tailwindcss v2.2.19", "vuetify": "Vuetify v2.7.2"}
+	for name, version := range want {
+		if !slices.Contains(preview.Available(vendored), name) {
+			t.Skipf("preview/vendor/%s.css is missing, run go generate ./preview", name)
+		}
+		css, err := preview.Stylesheet(vendored, name)
+		if err != nil || !strings.Contains(string(css), version) || !strings.Contains(string(css), "MIT License") {
+			t.Errorf("%s.css is not %s with its licence header (%v)", name, version, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+)
+
+// TestResultCache 测试缓存响应头与按哈希获取结果
+func TestResultCache(t *testing.T) {
+	c, _ := converter.NewCache(1<<20, "", 0)
+	api.UseCache(c)
+	defer api.UseCache(nil)
+
+	body := `{"html": "<p>result</p>", "packagePrefix": "h"}`
+	first := postV1("/api/v1/convert", body)
+	if first.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("First X-Cache = %q, want MISS", first.Header().Get("X-Cache"))
+	}
+	second := postV1("/api/v1/convert", body)
+	if second.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("Second X-Cache = %q, want HIT", second.Header().Get("X-Cache"))
+	}
+
+	var resp converter.Response
+	json.Unmarshal(second.Body.Bytes(), &resp)
+	etag := second.Header().Get("ETag")
+	if resp.Hash == "" || etag != `"`+resp.Hash+`"` {
+		t.Fatalf("Hash = %q, ETag = %q", resp.Hash, etag)
+	}
+
+	get := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			r.Header.Set(k, v)
+		}
+		api.ResultsHandler(rec, r)
+		return rec
+	}
+
+	rec := get("/api/results/"+resp.Hash, nil)
+	var fetched converter.Response
+	json.Unmarshal(rec.Body.Bytes(), &fetched)
+	if rec.Code != http.StatusOK || fetched.Code != resp.Code || rec.Header().Get("ETag") != etag {
+		t.Errorf("GET result = %d %+v, ETag %q", rec.Code, fetched, rec.Header().Get("ETag"))
+	}
+
+	if rec := get("/api/results/"+resp.Hash, map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Errorf("Conditional GET status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if rec := get("/api/results/"+resp.Hash, map[string]string{"Accept": "text/x-go"}); rec.Body.String() != resp.Code+"\n" {
+		t.Errorf("Plain GET body = %q, want %q", rec.Body.String(), resp.Code+"\n")
+	}
+
+	rec = get("/api/results/0000000000000000000000000000000000000000000000000000000000000000", nil)
+	var errResp apierror.Response
+	json.Unmarshal(rec.Body.Bytes(), &errResp)
+	if rec.Code != http.StatusNotFound || errResp.Code != apierror.CodeResultNotFound {
+		t.Errorf("Unknown hash = %d %q", rec.Code, errResp.Code)
+	}
+}
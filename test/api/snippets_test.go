@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/api"
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+	"html2go-converter/snippets"
+)
+
+func serveSnippets(method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+	api.SnippetsHandler(rec, r)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) apierror.Code {
+	t.Helper()
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid error response %q: %v", rec.Body.String(), err)
+	}
+	return resp.Code
+}
+
+// TestSnippets 测试创建、获取、打开和删除分享链接
+func TestSnippets(t *testing.T) {
+	api.UseSnippetStore(snippets.NewMemoryStore(), snippets.DefaultPolicy)
+
+	rec := serveSnippets(http.MethodPost, "/api/snippets", `{"html": "<p>shared</p>", "packagePrefix": "x", "expiresIn": "1h"}`, nil)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var created api.SnippetResponse
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if created.URL != "/s/"+created.ID || rec.Header().Get("Location") != created.URL {
+		t.Errorf("URL = %q, Location = %q", created.URL, rec.Header().Get("Location"))
+	}
+	if created.DeleteToken == "" || !strings.Contains(created.Code, "x.P(") {
+		t.Errorf("Created = %+v", created)
+	}
+	if created.ExpiresAt == nil || created.ExpiresAt.Sub(created.CreatedAt) != time.Hour {
+		t.Errorf("ExpiresAt = %v, CreatedAt = %v", created.ExpiresAt, created.CreatedAt)
+	}
+
+	rec = serveSnippets(http.MethodGet, "/api/snippets/"+created.ID, "", nil)
+	var fetched api.SnippetResponse
+	json.Unmarshal(rec.Body.Bytes(), &fetched)
+	if rec.Code != http.StatusOK || fetched.HTML != "<p>shared</p>" || fetched.Code != created.Code {
+		t.Errorf("GET = %d %+v", rec.Code, fetched)
+	}
+	if fetched.DeleteToken != "" {
+		t.Error("GET exposes the deletion token")
+	}
+
+	rec = serveSnippets(http.MethodGet, "/s/"+created.ID, "", nil)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"`+created.ID+`"`) {
+		t.Errorf("Page = %d, snippet not injected", rec.Code)
+	}
+	if rec.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("Page Content-Type = %q", rec.Header().Get("Content-Type"))
+	}
+
+	rec = serveSnippets(http.MethodDelete, "/api/snippets/"+created.ID, "", map[string]string{"X-Delete-Token": "wrong"})
+	if code := errorCode(t, rec); rec.Code != http.StatusForbidden || code != "snippet_forbidden" {
+		t.Errorf("DELETE with wrong token = %d %q", rec.Code, code)
+	}
+	rec = serveSnippets(http.MethodDelete, "/api/snippets/"+created.ID+"?token="+created.DeleteToken, "", nil)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	rec = serveSnippets(http.MethodGet, "/api/snippets/"+created.ID, "", nil)
+	if code := errorCode(t, rec); rec.Code != http.StatusNotFound || code != "snippet_not_found" {
+		t.Errorf("GET deleted = %d %q", rec.Code, code)
+	}
+	if rec := serveSnippets(http.MethodGet, "/s/"+created.ID, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Deleted page status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+// TestSnippetExpiry 测试过期策略与过期的代码片段
+func TestSnippetExpiry(t *testing.T) {
+	store := snippets.NewMemoryStore()
+	api.UseSnippetStore(store, snippets.Policy{DefaultExpiry: "1d", MaxTTL: 24 * time.Hour})
+
+	rec := serveSnippets(http.MethodPost, "/api/snippets", `{"html": "<p>a</p>", "expiresIn": "never"}`, nil)
+	if code := errorCode(t, rec); rec.Code != http.StatusBadRequest || code != "invalid_expiry" {
+		t.Errorf("POST never = %d %q, want 400 invalid_expiry", rec.Code, code)
+	}
+
+	expired, _ := snippets.New(converter.Request{}, "code", time.Hour, time.Now().Add(-2*time.Hour))
+	store.Create(expired)
+	if rec := serveSnippets(http.MethodGet, "/api/snippets/"+expired.ID, "", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("GET expired status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,185 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/converter"
+	"html2go-converter/openapi"
+)
+
+func postV1(path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	api.V1Handler(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	return rec
+}
+
+// TestV1Convert 测试v1转换接口及默认转换方向
+func TestV1Convert(t *testing.T) {
+	rec := postV1("/api/v1/convert", `{"html": "<div>a</div>", "packagePrefix": "h"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var resp converter.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if !strings.Contains(resp.Code, "h.Div(") {
+		t.Errorf("Code = %q", resp.Code)
+	}
+
+	rec = postV1("/api/v1/convert", `{"html": "<div>a</div>", "direction": "go2html"}`)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("go2html status = %d, want %d", rec.Code, http.StatusNotImplemented)
+	}
+}
+
+// TestV1Verify 测试verify选项返回往返比较结果
+func TestV1Verify(t *testing.T) {
+	rec := postV1("/api/v1/convert", `{"html": "<div><p>a</p><p hidden>b</p></div>", "packagePrefix": "h", "verify": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var resp converter.Response
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	v := resp.Verification
+	if v == nil || v.Equal || len(v.Mismatches) != 1 || v.Mismatches[0].Path != "div[0]/p[1]@hidden" {
+		t.Errorf("Verification = %+v", v)
+	}
+}
+
+// TestV1FieldErrors 测试请求校验逐字段报告错误
+func TestV1FieldErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		fields []string
+	}{
+		{"missing html and bad direction", "/api/v1/convert", `{"direction": "sideways"}`, []string{"html", "direction"}},
+		{"wrong type", "/api/v1/convert", `{"html": "<p></p>", "childrenMode": "yes"}`, []string{"childrenMode"}},
+		{"unknown field", "/api/v1/convert", `{"html": "<p></p>", "goCode": "x"}`, []string{"goCode"}},
+		{"empty batch", "/api/v1/convert/batch", `{"items": []}`, []string{"items"}},
+		{"bad item direction", "/api/v1/convert/batch", `{"items": [{"html": "<p></p>"}, {"html": "<p></p>", "direction": "x"}]}`, []string{"items[1].direction"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postV1(tt.path, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("Status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			var resp api.ErrorResponse
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			var fields []string
+			for _, f := range resp.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("Fields = %q, want %q (%s)", fields, tt.fields, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestV1MethodAndPath 测试不支持的方法与路径
+func TestV1MethodAndPath(t *testing.T) {
+	rec := httptest.NewRecorder()
+	api.V1Handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/convert", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodPost {
+		t.Errorf("GET status = %d, Allow = %q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = postV1("/api/v1/unknown", "{}")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown path status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+// TestLegacyConvertShim 测试旧接口保持兼容并指向v1接口
+func TestLegacyConvertShim(t *testing.T) {
+	rec := httptest.NewRecorder()
+	body := `{"html": "<div>a</div>", "direction": "html2go", "extra": true}`
+	api.Handler(rec, httptest.NewRequest(http.MethodPost, "/convert", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Deprecation") == "" || !strings.Contains(rec.Header().Get("Link"), "/api/v1/convert") {
+		t.Errorf("Missing deprecation headers: %v", rec.Header())
+	}
+}
+
+// TestOpenAPIDocument 测试OpenAPI文档与Go类型保持一致
+func TestOpenAPIDocument(t *testing.T) {
+	rec := httptest.NewRecorder()
+	api.V1Handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d", rec.Code)
+	}
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Invalid document: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("openapi = %q, want %q", doc.OpenAPI, openapi.Version)
+	}
+	for _, path := range []string{"/api/v1/convert", "/api/v1/convert/batch", "/api/v1/openapi.json"} {
+		if doc.Paths[path] == nil {
+			t.Errorf("Missing path %s", path)
+		}
+	}
+
+	// Every JSON field of the request type is documented, and nothing else
+	request := doc.Components.Schemas["Request"]
+	if request == nil {
+		t.Fatal("Missing Request schema")
+	}
+	var documented, fields []string
+	for name := range request.Properties {
+		documented = append(documented, name)
+	}
+	rt := reflect.TypeOf(converter.Request{})
+	for i := 0; i < rt.NumField(); i++ {
+		name, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(documented)
+	sort.Strings(fields)
+	if !reflect.DeepEqual(documented, fields) {
+		t.Errorf("Request properties = %q, want %q", documented, fields)
+	}
+	if !reflect.DeepEqual(request.Required, []string{"html"}) {
+		t.Errorf("Request required = %q, want [html]", request.Required)
+	}
+
+	// A text/html body takes every option except the HTML as a query parameter
+	var parameters, options []string
+	for _, p := range doc.Paths["/api/v1/convert"]["post"].Parameters {
+		if p.In == "query" {
+			parameters = append(parameters, p.Name)
+		}
+	}
+	for _, f := range fields {
+		if f != "html" {
+			options = append(options, f)
+		}
+	}
+	sort.Strings(parameters)
+	if !reflect.DeepEqual(parameters, options) {
+		t.Errorf("Query parameters = %q, want %q", parameters, options)
+	}
+
+	direction := doc.Components.Schemas["Direction"]
+	if direction == nil || !reflect.DeepEqual(direction.Enum, converter.Direction("").EnumValues()) {
+		t.Errorf("Direction schema = %+v", direction)
+	}
+
+	// BatchItem flattens the embedded overrides
+	item := doc.Components.Schemas["BatchItem"]
+	if item == nil || item.Properties["packagePrefix"] == nil || item.Properties["Overrides"] != nil {
+		t.Errorf("BatchItem schema = %+v", item)
+	}
+}
@@ -0,0 +1,135 @@
+package assets_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"html2go-converter/api"
+	"html2go-converter/assets"
+)
+
+// TestCatalogFingerprints 测试指纹文件名与index.html引用改写
+func TestCatalogFingerprints(t *testing.T) {
+	src := assets.FS("test", fstest.MapFS{
+		"index.html":   {Data: []byte(`<script src="/static/script.js"></script><script src='/analytics.js'></script>`)},
+		"script.js":    {Data: []byte("console.log('hello')")},
+		"analytics.js": {Data: []byte("console.log('analytics')")},
+	})
+	catalog, err := assets.NewCatalog(src)
+	if err != nil {
+		t.Fatalf("NewCatalog error: %v", err)
+	}
+
+	script, immutable, ok := catalog.Lookup("script.js")
+	if !ok || immutable {
+		t.Fatalf("Lookup(script.js) = %v, %v; want found and mutable", ok, immutable)
+	}
+	if !regexp.MustCompile(`^script\.[0-9a-f]{10}\.js$`).MatchString(script.Fingerprinted) {
+		t.Errorf("Unexpected fingerprinted name %q", script.Fingerprinted)
+	}
+	if _, immutable, ok := catalog.Lookup(script.Fingerprinted); !ok || !immutable {
+		t.Errorf("Lookup(%s) = %v, %v; want found and immutable", script.Fingerprinted, ok, immutable)
+	}
+
+	index, _, _ := catalog.Lookup("index.html")
+	analytics, _, _ := catalog.Lookup("analytics.js")
+	want := `<script src="/static/` + script.Fingerprinted + `"></script><script src='/` + analytics.Fingerprinted + `'></script>`
+	if string(index.Content) != want {
+		t.Errorf("index.html = %q, want %q", index.Content, want)
+	}
+}
+
+// TestCatalogPrebuiltMismatch 测试与原文件不一致的预压缩文件不会被使用
+func TestCatalogPrebuiltMismatch(t *testing.T) {
+	content := []byte(strings.Repeat("console.log('current');\n", 50))
+	gzipped := func(data []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(data)
+		zw.Close()
+		return buf.Bytes()
+	}
+	current := gzipped(content)
+	stale := gzipped([]byte("console.log('old');"))
+
+	for name, prebuilt := range map[string][]byte{"current": current, "stale": stale, "corrupt": []byte("not gzip")} {
+		catalog, err := assets.NewCatalog(assets.FS("test", fstest.MapFS{
+			"script.js":    {Data: content},
+			"script.js.gz": {Data: prebuilt},
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, _, _ := catalog.Lookup("script.js")
+		if used := bytes.Equal(file.Gzip, prebuilt); used != (name == "current") {
+			t.Errorf("%s prebuilt variant used = %v", name, used)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(file.Gzip))
+		if err != nil {
+			t.Fatalf("%s: invalid gzip variant: %v", name, err)
+		}
+		if got, _ := io.ReadAll(zr); !bytes.Equal(got, content) {
+			t.Errorf("%s: gzip variant does not decompress to the file", name)
+		}
+	}
+}
+
+// TestStaticCaching 测试ETag、不可变缓存与预压缩响应
+func TestStaticCaching(t *testing.T) {
+	api.UsePublicDir("")
+
+	get := func(path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		api.Index(rec, req)
+		return rec
+	}
+
+	// index.html 引用指纹化的脚本
+	index := get("/", nil)
+	if index.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("index.html Cache-Control = %q, want no-cache", index.Header().Get("Cache-Control"))
+	}
+	match := regexp.MustCompile(`/static/(script\.[0-9a-f]{10}\.js)`).FindStringSubmatch(index.Body.String())
+	if match == nil {
+		t.Fatalf("index.html does not reference a fingerprinted script.js")
+	}
+
+	script := get("/static/"+match[1], nil)
+	if script.Code != http.StatusOK {
+		t.Fatalf("GET fingerprinted script status = %d", script.Code)
+	}
+	if got := script.Header().Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+		t.Errorf("Fingerprinted script Cache-Control = %q", got)
+	}
+
+	// If-None-Match 命中时返回304
+	etag := script.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Missing ETag header")
+	}
+	if rec := get("/script.js", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Errorf("Conditional GET status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+
+	// 按Accept-Encoding选择预压缩版本
+	for encoding, accept := range map[string]string{"br": "gzip, br", "gzip": "gzip, br;q=0"} {
+		rec := get("/script.js", map[string]string{"Accept-Encoding": accept})
+		if got := rec.Header().Get("Content-Encoding"); got != encoding {
+			t.Errorf("Accept-Encoding %q gave Content-Encoding %q, want %q", accept, got, encoding)
+		}
+		if rec.Header().Get("ETag") == etag {
+			t.Errorf("Encoded response for %q reused the identity ETag", accept)
+		}
+	}
+}
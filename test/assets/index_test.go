@@ -0,0 +1,107 @@
+package assets_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/api"
+)
+
+// TestIndexServesEmbeddedAssets 测试默认从内嵌资源提供静态文件
+func TestIndexServesEmbeddedAssets(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+	}{
+		{name: "Root", path: "/", contentType: "text/html; charset=utf-8"},
+		{name: "Script", path: "/script.js", contentType: "application/javascript; charset=utf-8"},
+		{name: "Static prefix", path: "/static/script.js", contentType: "application/javascript; charset=utf-8"},
+		{name: "SPA fallback", path: "/some/page", contentType: "text/html; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.Index(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, rec.Code, http.StatusOK)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("GET %s Content-Type = %q, want %q", tt.path, got, tt.contentType)
+			}
+			if rec.Body.Len() == 0 {
+				t.Errorf("GET %s returned an empty body", tt.path)
+			}
+		})
+	}
+}
+
+// TestIndexMissingFile 测试不存在的静态文件返回404
+func TestIndexMissingFile(t *testing.T) {
+	for _, p := range []string{"/missing.js", "/static/../../go.mod.js"} {
+		rec := httptest.NewRecorder()
+		api.Index(rec, httptest.NewRequest(http.MethodGet, p, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want %d", p, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+// TestUsePublicDir 测试使用磁盘目录覆盖内嵌资源
+func TestUsePublicDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html><body>Dev</body></html>"), 0o644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	api.UsePublicDir(dir)
+	defer api.UsePublicDir("")
+
+	rec := httptest.NewRecorder()
+	api.Index(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rec.Body.String(), "Dev") {
+		t.Errorf("Expected index.html from %s, got: %q", dir, rec.Body.String())
+	}
+}
+
+// TestUsePublicDirEdits 测试磁盘目录中的修改无需重启即可生效
+func TestUsePublicDirEdits(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, modTime time.Time) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modTime, modTime)
+	}
+	then := time.Now().Add(-time.Hour)
+	write("index.html", "<html><body>Before</body></html>", then)
+	write("script.js", "console.log('before')", then)
+
+	api.UsePublicDir(dir)
+	defer api.UsePublicDir("")
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		api.Index(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+	if !strings.Contains(get("/"), "Before") || !strings.Contains(get("/script.js"), "before") {
+		t.Fatal("Unexpected initial assets")
+	}
+
+	write("index.html", "<html><body>After</body></html>", time.Now())
+	write("script.js", "console.log('after')", time.Now())
+	if body := get("/"); !strings.Contains(body, "After") {
+		t.Errorf("index.html after the edit = %q", body)
+	}
+	if body := get("/script.js"); !strings.Contains(body, "after") {
+		t.Errorf("script.js after the edit = %q", body)
+	}
+}
@@ -0,0 +1,92 @@
+package assets_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/assets"
+	"html2go-converter/config"
+)
+
+// TestIndexInjectsConfig 测试index.html由服务端渲染并注入运行时配置
+func TestIndexInjectsConfig(t *testing.T) {
+	api.UsePublicDir("")
+	cfg := config.App{
+		APIBaseURL:  "https://api.example.com",
+		Version:     "9.9.9",
+		Environment: "staging",
+		Features:    []string{"batch"},
+		Defaults:    config.Defaults{PackagePrefix: "hh", VuetifyPrefix: "vv", VuetifyXPrefix: "vvx"},
+	}
+	api.SetAppConfig(cfg)
+	defer api.SetAppConfig(config.FromEnv())
+
+	for _, path := range []string{"/", "/index.html"} {
+		rec := httptest.NewRecorder()
+		api.Index(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		match := regexp.MustCompile(`<script id="app-config" type="application/json">(.*?)</script>`).FindStringSubmatch(rec.Body.String())
+		if match == nil {
+			t.Fatalf("GET %s: no app-config block in page", path)
+		}
+		var got config.App
+		if err := json.Unmarshal([]byte(match[1]), &got); err != nil {
+			t.Fatalf("GET %s: app-config is not valid JSON: %v (%s)", path, err, match[1])
+		}
+		if got.APIBaseURL != cfg.APIBaseURL || got.Version != cfg.Version || got.Environment != cfg.Environment ||
+			got.Defaults != cfg.Defaults || len(got.Features) != 1 || got.Features[0] != "batch" {
+			t.Errorf("GET %s: app-config = %+v, want %+v", path, got, cfg)
+		}
+	}
+}
+
+// TestIndexRenderCached 测试渲染结果被缓存且配置变化后更新
+func TestIndexRenderCached(t *testing.T) {
+	api.UsePublicDir("")
+	defer api.SetAppConfig(config.FromEnv())
+
+	etag := func() string {
+		rec := httptest.NewRecorder()
+		api.Index(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Header().Get("ETag")
+	}
+
+	api.SetAppConfig(config.App{Environment: "dev"})
+	first := etag()
+	if first == "" || etag() != first {
+		t.Errorf("Expected a stable ETag for the cached page")
+	}
+
+	api.SetAppConfig(config.App{Environment: "prod"})
+	if etag() == first {
+		t.Errorf("Expected the page to be re-rendered after the configuration changed")
+	}
+}
+
+// TestExecute 测试按请求渲染的页面与Render一致，但模板只解析一次且不压缩
+func TestExecute(t *testing.T) {
+	f := assets.NewFile("page.html", "test", []byte(`<p>{{.}}</p>`))
+	page, err := assets.Execute(f, "<a>")
+	if err != nil || string(page) != "<p>&lt;a&gt;</p>" {
+		t.Fatalf("Execute = %q, %v", page, err)
+	}
+	rendered, err := assets.Render(f, "<a>")
+	if err != nil || string(rendered.Content) != string(page) {
+		t.Errorf("Render = %q, %v, want %q", rendered.Content, err, page)
+	}
+
+	// 模板在首次使用时解析，之后修改内容不再生效
+	f.Content = []byte(`<b>{{.}}</b>`)
+	if page, _ := assets.Execute(f, "x"); string(page) != "<p>x</p>" {
+		t.Errorf("Execute after edit = %q, want the parsed template", page)
+	}
+
+	f = assets.NewFile("broken.html", "test", []byte(`{{`))
+	if _, err := assets.Execute(f, nil); err == nil {
+		t.Error("Execute of an invalid template succeeded")
+	}
+}
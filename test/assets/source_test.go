@@ -0,0 +1,110 @@
+package assets_test
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"html2go-converter/api"
+	"html2go-converter/assets"
+)
+
+// TestOverlaySource 测试叠加层的文件覆盖与来源报告
+func TestOverlaySource(t *testing.T) {
+	theme := assets.FS("theme", fstest.MapFS{
+		"index.html":        {Data: []byte("<html>Custom</html>")},
+		"themes/custom.css": {Data: []byte("body{}")},
+	})
+	src := assets.Overlay(theme, assets.Embedded())
+
+	tests := []struct {
+		name  string
+		file  string
+		layer string
+		want  string
+	}{
+		{name: "Overridden file", file: "index.html", layer: "theme", want: "Custom"},
+		{name: "Added file", file: "themes/custom.css", layer: "theme", want: "body{}"},
+		{name: "Default file", file: "script.js", layer: "embedded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, layer, err := assets.ReadFile(src, tt.file)
+			if err != nil {
+				t.Fatalf("ReadFile(%s) error: %v", tt.file, err)
+			}
+			if layer != tt.layer {
+				t.Errorf("ReadFile(%s) layer = %q, want %q", tt.file, layer, tt.layer)
+			}
+			if !strings.Contains(string(content), tt.want) {
+				t.Errorf("ReadFile(%s) = %q, want it to contain %q", tt.file, content, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := assets.ReadFile(src, "missing.js"); err == nil {
+		t.Error("Expected an error for a file missing from every layer")
+	}
+
+	// 目录内容应合并所有层
+	entries, err := fs.ReadDir(src, ".")
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	for _, want := range []string{"index.html", "script.js", "themes"} {
+		if !names[want] {
+			t.Errorf("ReadDir(.) missing %s, got %v", want, names)
+		}
+	}
+}
+
+// TestIndexReportsLayer 测试响应头报告提供文件的资源层
+func TestIndexReportsLayer(t *testing.T) {
+	theme := assets.FS("theme", fstest.MapFS{
+		"index.html": {Data: []byte("<html>Custom</html>")},
+	})
+	api.UseAssets(assets.Overlay(theme, assets.Embedded()))
+	defer api.UsePublicDir("")
+
+	for path, want := range map[string]string{"/": "theme", "/script.js": "embedded"} {
+		rec := httptest.NewRecorder()
+		api.Index(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := rec.Header().Get("X-Asset-Layer"); got != want {
+			t.Errorf("GET %s X-Asset-Layer = %q, want %q", path, got, want)
+		}
+	}
+}
+
+// TestEmbeddedMatchesPublic 测试内嵌资源包含public目录的全部文件且不含Go源码
+func TestEmbeddedMatchesPublic(t *testing.T) {
+	var want, got []string
+	fs.WalkDir(os.DirFS("../../web/public"), ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			want = append(want, path)
+		}
+		return err
+	})
+	fs.WalkDir(assets.Embedded(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			got = append(got, path)
+		}
+		return err
+	})
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Embedded files = %v, want %v", got, want)
+	}
+	for _, path := range got {
+		if strings.HasSuffix(path, ".go") {
+			t.Errorf("Go source %s is published with the assets", path)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package converter_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"html2go-converter/api"
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+)
+
+var archiveFiles = map[string]string{
+	"index.html":           `<div class="a"><v-btn>Hi</v-btn></div>`,
+	"pages/user-list.html": "<ul><li>x</li></ul>",
+	"pages/empty.html":     "",
+	"style.css":            "body{}",
+}
+
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func readZipFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Invalid zip: %v", err)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+// TestReadArchive 测试zip与tar.gz归档都能读取且只保留HTML文件
+func TestReadArchive(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"zip":    buildZip(t, archiveFiles),
+		"tar.gz": buildTarGz(t, archiveFiles),
+	} {
+		files, skipped, err := converter.ReadArchive(data)
+		if err != nil {
+			t.Fatalf("%s: ReadArchive failed: %v", name, err)
+		}
+		if len(files) != 3 {
+			t.Errorf("%s: got %d HTML files, want 3", name, len(files))
+		}
+		if len(skipped) != 1 || skipped[0] != "style.css" {
+			t.Errorf("%s: skipped = %q, want [style.css]", name, skipped)
+		}
+	}
+
+	if _, _, err := converter.ReadArchive([]byte("not an archive")); err != converter.ErrUnknownArchive {
+		t.Errorf("Plain text error = %v, want %v", err, converter.ErrUnknownArchive)
+	}
+	if _, _, err := converter.ReadArchive(buildZip(t, map[string]string{"a.txt": "x"})); err != converter.ErrArchiveNoHTML {
+		t.Errorf("No HTML error = %v, want %v", err, converter.ErrArchiveNoHTML)
+	}
+}
+
+// TestReadArchiveSkippedLimit 测试被跳过的非HTML文件同样计入文件数上限
+func TestReadArchiveSkippedLimit(t *testing.T) {
+	files := map[string]string{"index.html": "<p>x</p>"}
+	for i := 0; i < converter.MaxArchiveFiles; i++ {
+		files[fmt.Sprintf("assets/%d.txt", i)] = ""
+	}
+	for name, data := range map[string][]byte{
+		"zip":    buildZip(t, files),
+		"tar.gz": buildTarGz(t, files),
+	} {
+		if _, _, err := converter.ReadArchive(data); !errors.Is(err, converter.ErrArchiveTooLarge) {
+			t.Errorf("%s: error = %v, want %v", name, err, converter.ErrArchiveTooLarge)
+		}
+	}
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	if _, _, err := converter.ReadFolder(fsys); !errors.Is(err, converter.ErrArchiveTooLarge) {
+		t.Errorf("folder: error = %v, want %v", err, converter.ErrArchiveTooLarge)
+	}
+
+	delete(files, "assets/0.txt")
+	if _, skipped, err := converter.ReadArchive(buildZip(t, files)); err != nil || len(skipped) != converter.MaxArchiveFiles-1 {
+		t.Errorf("At the limit: %d skipped, error %v", len(skipped), err)
+	}
+}
+
+// TestConvertArchive 测试生成的Go文件名、函数名、包名和清单诊断信息
+func TestConvertArchive(t *testing.T) {
+	files, skipped, err := converter.ReadFolder(fstest.MapFS{
+		"index.html":           {Data: []byte(archiveFiles["index.html"])},
+		"pages/user-list.html": {Data: []byte(archiveFiles["pages/user-list.html"])},
+		"pages/empty.html":     {Data: []byte{}},
+		"style.css":            {Data: []byte(archiveFiles["style.css"])},
+		".git/ignored.html":    {Data: []byte("<p>x</p>")},
+	})
+	if err != nil {
+		t.Fatalf("ReadFolder failed: %v", err)
+	}
+
+	opts := converter.ArchiveOptions{
+		Package: "pages",
+		Options: converter.Request{PackagePrefix: "h", VuetifyPrefix: "v", VuetifyXPrefix: "vx"},
+		Skipped: skipped,
+	}
+	data, manifest, err := converter.ConvertArchive(context.Background(), files, opts)
+	if err != nil {
+		t.Fatalf("ConvertArchive failed: %v", err)
+	}
+	if manifest.Converted != 2 || manifest.Failed != 1 {
+		t.Errorf("Manifest summary = %d converted, %d failed, want 2 and 1", manifest.Converted, manifest.Failed)
+	}
+
+	output := readZipFiles(t, data)
+	userList := output["pages_user_list.go"]
+	for _, want := range []string{"package pages", "func PagesUserList() h.HTMLComponent", "h.Ul(", "DO NOT EDIT"} {
+		if !strings.Contains(userList, want) {
+			t.Errorf("pages_user_list.go does not contain %q:\n%s", want, userList)
+		}
+	}
+	if strings.Contains(userList, "vuetify") {
+		t.Errorf("pages_user_list.go imports unused Vuetify packages:\n%s", userList)
+	}
+	if index := output["index.go"]; !strings.Contains(index, "func Index()") || !strings.Contains(index, `v "github.com/qor5/x/v3/ui/vuetify"`) {
+		t.Errorf("Unexpected index.go:\n%s", index)
+	}
+
+	var written converter.Manifest
+	if err := json.Unmarshal([]byte(output["manifest.json"]), &written); err != nil {
+		t.Fatalf("Invalid manifest.json: %v", err)
+	}
+	var failed *converter.ManifestEntry
+	for i := range written.Files {
+		if written.Files[i].Source == "pages/empty.html" {
+			failed = &written.Files[i]
+		}
+	}
+	if failed == nil || failed.Error != converter.ErrHTMLRequired.Error() || failed.ErrorCode != "html_required" || failed.Output != "" {
+		t.Errorf("Failed entry = %+v, want error %q", failed, converter.ErrHTMLRequired)
+	}
+	if len(written.Skipped) != 1 || written.Skipped[0] != "style.css" {
+		t.Errorf("Skipped = %q, want [style.css]", written.Skipped)
+	}
+
+	if _, _, err := converter.ConvertArchive(context.Background(), files, converter.ArchiveOptions{Package: "func"}); err != converter.ErrInvalidGoPackage {
+		t.Errorf("Keyword package error = %v, want %v", err, converter.ErrInvalidGoPackage)
+	}
+}
+
+// TestConvertArchiveCompiles 测试生成的Go文件在各种模式下都能通过类型检查
+func TestConvertArchiveCompiles(t *testing.T) {
+	files := []converter.SourceFile{
+		{Path: "index.html", Content: []byte(`<div class="a"><p>x</p><input type="text"></div><span>y</span>`)},
+		{Path: "single.html", Content: []byte(`<ul><li>x</li></ul>`)},
+	}
+	for _, prefix := range []string{"h", ""} {
+		for _, childrenMode := range []bool{false, true} {
+			data, manifest, err := converter.ConvertArchive(context.Background(), files, converter.ArchiveOptions{
+				Package: "pages",
+				Options: converter.Request{PackagePrefix: prefix, ChildrenMode: childrenMode},
+			})
+			if err != nil || manifest.Failed != 0 {
+				t.Fatalf("ConvertArchive = %+v, %v", manifest, err)
+			}
+
+			fset := token.NewFileSet()
+			var parsed []*ast.File
+			for name, content := range readZipFiles(t, data) {
+				if !strings.HasSuffix(name, ".go") {
+					continue
+				}
+				f, err := parser.ParseFile(fset, name, content, 0)
+				if err != nil {
+					t.Fatalf("prefix %q, childrenMode %v: %v", prefix, childrenMode, err)
+				}
+				parsed = append(parsed, f)
+			}
+			conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+			if _, err := conf.Check("pages", fset, parsed, nil); err != nil {
+				t.Errorf("prefix %q, childrenMode %v: %v", prefix, childrenMode, err)
+			}
+		}
+	}
+}
+
+// TestConvertArchiveNameCollision 测试路径推导出相同名称时自动重命名
+func TestConvertArchiveNameCollision(t *testing.T) {
+	files := []converter.SourceFile{
+		{Path: "user-list.html", Content: []byte("<p>a</p>")},
+		{Path: "user_list.htm", Content: []byte("<p>b</p>")},
+	}
+	data, manifest, err := converter.ConvertArchive(context.Background(), files, converter.ArchiveOptions{
+		Options: converter.Request{PackagePrefix: "h"},
+	})
+	if err != nil {
+		t.Fatalf("ConvertArchive failed: %v", err)
+	}
+	if manifest.Files[0].Function == manifest.Files[1].Function || manifest.Files[0].Output == manifest.Files[1].Output {
+		t.Fatalf("Names collide: %+v", manifest.Files)
+	}
+	if len(manifest.Files[1].Warnings) == 0 {
+		t.Errorf("Renamed entry has no warning: %+v", manifest.Files[1])
+	}
+	if len(readZipFiles(t, data)) != 3 {
+		t.Errorf("Expected two Go files and manifest.json")
+	}
+}
+
+// TestArchiveHandler 测试上传归档后返回生成的zip
+func TestArchiveHandler(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("package", "site")
+	mw.WriteField("packagePrefix", "h")
+	part, _ := mw.CreateFormFile("archive", "site.tar.gz")
+	part.Write(buildTarGz(t, archiveFiles))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/convert/archive", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	api.ArchiveHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %q, want %q", got, "application/zip")
+	}
+	if got := rec.Header().Get("X-Conversion-Failed"); got != "1" {
+		t.Errorf("X-Conversion-Failed = %q, want %q", got, "1")
+	}
+	if output := readZipFiles(t, rec.Body.Bytes()); !strings.Contains(output["index.go"], "package site") {
+		t.Errorf("Unexpected index.go:\n%s", output["index.go"])
+	}
+
+	// A request without an archive is rejected
+	req = httptest.NewRequest(http.MethodPost, "/api/convert/archive", strings.NewReader(""))
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec = httptest.NewRecorder()
+	api.ArchiveHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Status without archive = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	// Options that are not booleans are reported as invalid fields
+	body.Reset()
+	mw = multipart.NewWriter(&body)
+	mw.WriteField("childrenMode", "yes")
+	mw.WriteField("verify", "maybe")
+	part, _ = mw.CreateFormFile("archive", "site.tar.gz")
+	part.Write(buildTarGz(t, archiveFiles))
+	mw.Close()
+	req = httptest.NewRequest(http.MethodPost, "/api/convert/archive", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("Accept", "application/problem+json")
+	rec = httptest.NewRecorder()
+	api.ArchiveHandler(rec, req)
+	var problem apierror.Problem
+	json.Unmarshal(rec.Body.Bytes(), &problem)
+	if rec.Code != http.StatusBadRequest || problem.Code != apierror.CodeValidationFailed || len(problem.Fields) != 2 ||
+		problem.Fields[0].Field != "childrenMode" || problem.Fields[1].Field != "verify" {
+		t.Errorf("Invalid options = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,128 @@
+package converter_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/converter"
+)
+
+const batchBody = `{
+	"defaults": {"direction": "html2go", "packagePrefix": "h"},
+	"items": [
+		{"name": "plain", "html": "<div>a</div>"},
+		{"name": "override", "html": "<div>b</div>", "packagePrefix": "x"},
+		{"name": "empty", "html": ""},
+		{"name": "reverse", "html": "<div>c</div>", "direction": "go2html"}
+	]
+}`
+
+// TestBatchHandler 测试批量转换返回逐项结果且单项失败不影响整体
+func TestBatchHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	api.BatchHandler(rec, httptest.NewRequest(http.MethodPost, "/api/convert/batch", strings.NewReader(batchBody)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp converter.BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Invalid response: %v", err)
+	}
+	if resp.Succeeded != 2 || resp.Failed != 2 || len(resp.Results) != 4 {
+		t.Fatalf("Unexpected summary: %+v", resp)
+	}
+
+	for i, name := range []string{"plain", "override", "empty", "reverse"} {
+		if resp.Results[i].Index != i || resp.Results[i].Name != name {
+			t.Errorf("Result %d = %+v, want item %s", i, resp.Results[i], name)
+		}
+	}
+	if !strings.Contains(resp.Results[0].Code, "h.Div(") {
+		t.Errorf("Default prefix not applied: %q", resp.Results[0].Code)
+	}
+	if !strings.Contains(resp.Results[1].Code, "x.Div(") {
+		t.Errorf("Per-item override not applied: %q", resp.Results[1].Code)
+	}
+	if resp.Results[2].Error != converter.ErrHTMLRequired.Error() || resp.Results[3].Error != converter.ErrNotImplemented.Error() {
+		t.Errorf("Unexpected item errors: %q, %q", resp.Results[2].Error, resp.Results[3].Error)
+	}
+	if r := resp.Results[2]; r.ErrorCode != "html_required" || r.ErrorField != "html" {
+		t.Errorf("Empty item error code = %q, field %q", r.ErrorCode, r.ErrorField)
+	}
+	if r := resp.Results[3]; r.ErrorCode != "not_implemented" || r.ErrorField != "direction" {
+		t.Errorf("Reverse item error code = %q, field %q", r.ErrorCode, r.ErrorField)
+	}
+	if r := resp.Results[0]; r.ErrorCode != "" {
+		t.Errorf("Successful item has error code %q", r.ErrorCode)
+	}
+}
+
+// TestBatchHandlerStream 测试NDJSON流式批量转换
+func TestBatchHandlerStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(api.BatchHandler))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(batchBody))
+	req.Header.Set("Accept", "application/x-ndjson")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	seen := make(map[int]bool)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var result converter.BatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("Invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		seen[result.Index] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("Expected 4 streamed results, got %d", len(seen))
+	}
+}
+
+// TestBatchValidation 测试空批量与超限批量被拒绝
+func TestBatchValidation(t *testing.T) {
+	items := make([]string, converter.MaxBatchItems+1)
+	for i := range items {
+		items[i] = `{"html":"<p></p>"}`
+	}
+	for name, body := range map[string]string{
+		"Empty":     `{"items": []}`,
+		"Too large": `{"items": [` + strings.Join(items, ",") + `]}`,
+		"Malformed": `{"items": `,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			api.BatchHandler(rec, httptest.NewRequest(http.MethodPost, "/api/convert/batch", strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+// TestErrorInfo 测试错误信息带有错误码，没有错误码的错误报告为conversion_failed
+func TestErrorInfo(t *testing.T) {
+	limitErr := &converter.LimitError{Err: converter.ErrTooDeep, Limit: 2}
+	if info := converter.ErrorInfo(fmt.Errorf("item: %w", limitErr)); info.Code != "html_too_deep" || info.Field != "html" || info.Details["maxDepth"] != int64(2) {
+		t.Errorf("ErrorInfo(limit error) = %+v", info)
+	}
+	if info := converter.ErrorInfo(errors.New("parse error")); info.Code != "conversion_failed" || info.Status != http.StatusInternalServerError {
+		t.Errorf("ErrorInfo(uncoded error) = %+v", info)
+	}
+}
@@ -0,0 +1,179 @@
+package converter_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/diff"
+)
+
+// TestCacheLRU 测试内存缓存按字节数淘汰最久未使用的结果
+func TestCacheLRU(t *testing.T) {
+	c, err := converter.NewCache(3*(64+64+100), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(s string) string { return strings.Repeat(s, 64) }
+	resp := converter.Response{Code: strings.Repeat("x", 100)}
+
+	c.Put(key("a"), resp)
+	c.Put(key("b"), resp)
+	c.Put(key("c"), resp)
+	c.Get(key("a"))
+	c.Put(key("d"), resp)
+
+	if _, ok := c.Get(key("b")); ok {
+		t.Error("Least recently used entry b was kept")
+	}
+	for _, k := range []string{"a", "c", "d"} {
+		if _, ok := c.Get(key(k)); !ok {
+			t.Errorf("Entry %s was evicted", k)
+		}
+	}
+	if c.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", c.Len())
+	}
+}
+
+// TestCacheCountsVerification 测试往返验证结果计入缓存大小
+func TestCacheCountsVerification(t *testing.T) {
+	c, err := converter.NewCache(3*(64+64+100), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(s string) string { return strings.Repeat(s, 64) }
+	resp := converter.Response{Code: strings.Repeat("x", 100)}
+	c.Put(key("a"), resp)
+	c.Put(key("b"), resp)
+
+	resp.Verification = &converter.Verification{
+		HTML:       strings.Repeat("y", 100),
+		Mismatches: []diff.Change{{Op: diff.OpRemoved, Path: "div[0]@hidden"}},
+	}
+	c.Put(key("c"), resp)
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want the verified entry alone", c.Len())
+	}
+}
+
+// TestCacheDiskTier 测试磁盘缓存在重启后仍然有效，并只清除其他版本的结果
+func TestCacheDiskTier(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "0123456789ab")
+	os.MkdirAll(stale, 0o755)
+	os.WriteFile(filepath.Join(stale, "x.json"), []byte("{}"), 0o644)
+	// 缓存目录可能与其他数据共用
+	unrelated := filepath.Join(dir, "uploads")
+	os.MkdirAll(unrelated, 0o755)
+	os.WriteFile(filepath.Join(unrelated, "keep.txt"), []byte("x"), 0o644)
+
+	c, err := converter.NewCache(1<<20, dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("Results of another converter version were kept")
+	}
+	if _, err := os.Stat(filepath.Join(unrelated, "keep.txt")); err != nil {
+		t.Errorf("Unrelated data in the cache directory was removed: %v", err)
+	}
+
+	key := strings.Repeat("ab", 32)
+	c.Put(key, converter.Response{Code: "h.Div()", Hash: key})
+
+	// 新的缓存实例从磁盘读取结果
+	restarted, err := converter.NewCache(1<<20, dir, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp, ok := restarted.Get(key); !ok || resp.Code != "h.Div()" {
+		t.Errorf("Get() after restart = %+v, %v", resp, ok)
+	}
+	if _, ok := restarted.Get("../../etc/passwd"); ok {
+		t.Error("Get() accepted an invalid key")
+	}
+}
+
+// TestCacheDiskLRU 测试从磁盘读取的结果被刷新，清理磁盘时优先删除最久未使用的结果
+func TestCacheDiskLRU(t *testing.T) {
+	dir := t.TempDir()
+	resp := converter.Response{Code: strings.Repeat("x", 1000)}
+	c, err := converter.NewCache(1<<20, dir, 2500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, d := strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("d", 64)
+	c.Put(a, resp)
+	c.Put(b, resp)
+
+	path := func(key string) string {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*", key+".json"))
+		if len(matches) != 1 {
+			t.Fatalf("Cached file of %s not found", key)
+		}
+		return matches[0]
+	}
+	// a写入得最早
+	now := time.Now()
+	os.Chtimes(path(a), now.Add(-2*time.Hour), now.Add(-2*time.Hour))
+	os.Chtimes(path(b), now.Add(-time.Hour), now.Add(-time.Hour))
+
+	// 新实例的内存为空，a从磁盘读取
+	restarted, err := converter.NewCache(1<<20, dir, 2500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := restarted.Get(a); !ok {
+		t.Fatal("Get() missed the result on disk")
+	}
+	restarted.Put(d, resp)
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	var kept []string
+	for _, m := range matches {
+		kept = append(kept, filepath.Base(m)[:1])
+	}
+	if len(kept) != 2 || kept[0] != "a" || kept[1] != "d" {
+		t.Errorf("Kept %v, want the recently read a and the new d", kept)
+	}
+}
+
+// TestConvertCached 测试转换结果的缓存命中与内容寻址
+func TestConvertCached(t *testing.T) {
+	c, _ := converter.NewCache(1<<20, "", 0)
+	converter.UseCache(c)
+	defer converter.UseCache(nil)
+
+	req := converter.Request{HTML: `<div>cached</div>`, PackagePrefix: "h", Direction: converter.DirectionHTMLToGo}
+	first, status, err := converter.ConvertCached(context.Background(), req)
+	if err != nil || status != converter.CacheMiss {
+		t.Fatalf("First ConvertCached() = %v, %v", status, err)
+	}
+	if first.Hash != converter.RequestKey(req) {
+		t.Errorf("Hash = %q, want %q", first.Hash, converter.RequestKey(req))
+	}
+
+	second, status, _ := converter.ConvertCached(context.Background(), req)
+	if status != converter.CacheHit || second != first {
+		t.Errorf("Second ConvertCached() = %+v, %v, want a hit", second, status)
+	}
+	if got, ok := converter.CachedResult(first.Hash); !ok || got.Code != first.Code {
+		t.Errorf("CachedResult() = %+v, %v", got, ok)
+	}
+
+	// 失败的转换不缓存
+	if _, status, err := converter.ConvertCached(context.Background(), converter.Request{HTML: "", Direction: converter.DirectionHTMLToGo}); err == nil || status != converter.CacheMiss {
+		t.Errorf("Empty ConvertCached() = %v, %v", status, err)
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+	if !strings.HasSuffix(converter.ConverterVersion(), "/1") {
+		t.Errorf("ConverterVersion() = %q", converter.ConverterVersion())
+	}
+}
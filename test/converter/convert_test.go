@@ -0,0 +1,73 @@
+package converter_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/converter"
+	"html2go-converter/routes"
+)
+
+// TestEntryPointsAgree 测试Vercel函数与本地服务器的转换结果完全一致
+func TestEntryPointsAgree(t *testing.T) {
+	requests := []converter.Request{
+		{HTML: `<div class="p-4"><p>Hello</p></div>`, Direction: converter.DirectionHTMLToGo},
+		{HTML: `<div><v-btn>OK</v-btn><vx-dialog title="t">x</vx-dialog></div>`, PackagePrefix: "h", VuetifyPrefix: "v", VuetifyXPrefix: "vx", Direction: converter.DirectionHTMLToGo},
+		{HTML: `<div><v-btn>OK</v-btn></div>`, Direction: converter.DirectionHTMLToGo},
+		{HTML: `<ul><li>1</li><li>2</li></ul>`, ChildrenMode: true, Direction: converter.DirectionHTMLToGo},
+		{HTML: `<div></div>`, Direction: converter.DirectionGoToHTML},
+		{HTML: ``, Direction: converter.DirectionHTMLToGo},
+	}
+
+	mux := http.NewServeMux()
+	routes.Register(mux)
+
+	post := func(h http.Handler, path string, body []byte) (int, string) {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(string(body)))
+		// 固定请求ID，使错误响应可以逐字节比较
+		r.Header.Set("X-Request-ID", "entry-points")
+		h.ServeHTTP(rec, r)
+		return rec.Code, rec.Body.String()
+	}
+
+	for _, req := range requests {
+		body, _ := json.Marshal(req)
+		vercelStatus, vercelBody := post(http.HandlerFunc(api.Handler), "/api/convert", body)
+
+		for _, path := range []string{"/api/convert", "/convert"} {
+			status, localBody := post(mux, path, body)
+			if status != vercelStatus || localBody != vercelBody {
+				t.Errorf("%s for %s = %d %q, Vercel handler = %d %q", path, req.HTML, status, localBody, vercelStatus, vercelBody)
+			}
+		}
+
+		// 直接调用共享包的结果与HTTP响应一致
+		if vercelStatus == http.StatusOK {
+			want, err := converter.Convert(req)
+			if err != nil {
+				t.Fatalf("Convert(%s) error: %v", req.HTML, err)
+			}
+			var got converter.Response
+			json.Unmarshal([]byte(vercelBody), &got)
+			if got.Code != want.Code {
+				t.Errorf("HTTP code for %s = %q, Convert = %q", req.HTML, got.Code, want.Code)
+			}
+		}
+	}
+}
+
+// TestConvertKeepsEmptyPrefixes 测试空前缀不会被替换为默认值
+func TestConvertKeepsEmptyPrefixes(t *testing.T) {
+	resp, err := converter.Convert(converter.Request{HTML: `<v-btn>OK</v-btn>`, Direction: converter.DirectionHTMLToGo})
+	if err != nil {
+		t.Fatalf("Convert error: %v", err)
+	}
+	if strings.Contains(resp.Code, "v.VBtn") {
+		t.Errorf("Empty Vuetify prefix was replaced by a default: %q", resp.Code)
+	}
+}
@@ -0,0 +1,103 @@
+package converter_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"html2go-converter/converter"
+)
+
+// slowHTML 的转换耗时约百毫秒，用于制造并发重叠
+var slowHTML = strings.Repeat(`<div class="a"><p>text</p></div>`, 5000)
+
+// TestSingleflight 测试相同的并发请求共享一次转换
+func TestSingleflight(t *testing.T) {
+	defer converter.UseLimits(converter.CurrentLimits())
+	converter.UseLimits(converter.Limits{MaxConcurrent: 4})
+
+	req := converter.Request{HTML: slowHTML, PackagePrefix: "h", Direction: converter.DirectionHTMLToGo}
+	before := converter.CurrentStats()
+
+	const callers = 8
+	codes := make([]string, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := converter.ConvertContext(context.Background(), req)
+			if err != nil {
+				t.Errorf("ConvertContext() error = %v", err)
+			}
+			codes[i] = resp.Code
+		}(i)
+	}
+	wg.Wait()
+
+	after := converter.CurrentStats()
+	if started := after.Started - before.Started; started >= callers {
+		t.Errorf("Started %d conversions for %d identical requests", started, callers)
+	}
+	if after.Shared == before.Shared {
+		t.Error("No request shared a conversion")
+	}
+	for i := range codes {
+		if codes[i] == "" || codes[i] != codes[0] {
+			t.Fatalf("Caller %d got a different result", i)
+		}
+	}
+
+	// 选项不同的请求不共享结果
+	if converter.RequestKey(req) == converter.RequestKey(converter.Request{HTML: slowHTML, Direction: converter.DirectionHTMLToGo}) {
+		t.Error("RequestKey ignores the options")
+	}
+}
+
+// TestQueueTimeout 测试并发数已满时排队超时
+func TestQueueTimeout(t *testing.T) {
+	defer converter.UseLimits(converter.CurrentLimits())
+	converter.UseLimits(converter.Limits{MaxConcurrent: 1, QueueTimeout: 10 * time.Millisecond})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		converter.ConvertContext(context.Background(), converter.Request{HTML: slowHTML, Direction: converter.DirectionHTMLToGo})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	before := converter.CurrentStats()
+	_, err := converter.ConvertContext(context.Background(), converter.Request{HTML: `<p>x</p>`, Direction: converter.DirectionHTMLToGo})
+	var limitErr *converter.LimitError
+	if !errors.Is(err, converter.ErrQueueTimeout) || !errors.As(err, &limitErr) {
+		t.Fatalf("ConvertContext() error = %v, want %v", err, converter.ErrQueueTimeout)
+	}
+	if converter.CurrentStats().QueueTimeouts != before.QueueTimeouts+1 {
+		t.Error("Queue timeout not counted")
+	}
+
+	// 槽位释放后可以继续转换
+	<-done
+	if _, err := converter.ConvertContext(context.Background(), converter.Request{HTML: `<p>x</p>`, Direction: converter.DirectionHTMLToGo}); err != nil {
+		t.Errorf("ConvertContext() after release error = %v", err)
+	}
+}
+
+// TestAbandonedFlight 测试所有调用方放弃后重新发起转换
+func TestAbandonedFlight(t *testing.T) {
+	defer converter.UseLimits(converter.CurrentLimits())
+	converter.UseLimits(converter.Limits{MaxConcurrent: 4})
+
+	req := converter.Request{HTML: slowHTML, VuetifyPrefix: "v", Direction: converter.DirectionHTMLToGo}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if _, err := converter.ConvertContext(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ConvertContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := converter.ConvertContext(context.Background(), req); err != nil {
+		t.Errorf("ConvertContext() after abandon error = %v", err)
+	}
+}
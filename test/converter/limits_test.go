@@ -0,0 +1,69 @@
+package converter_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/converter"
+)
+
+// TestCheckStructure 测试嵌套深度与节点数限制
+func TestCheckStructure(t *testing.T) {
+	limits := converter.Limits{MaxDepth: 3, MaxNodes: 6}
+	tests := []struct {
+		name string
+		html string
+		want error
+	}{
+		{"within limits", `<div><p>a<br><img></p></div>`, nil},
+		{"closed siblings", `<div></div><div></div><div></div>`, nil},
+		{"too deep", `<div><div><div><div>x</div></div></div></div>`, converter.ErrTooDeep},
+		{"unclosed nesting", `<div><span><b><i>x`, converter.ErrTooDeep},
+		{"too many nodes", strings.Repeat(`<p>a</p>`, 4), converter.ErrTooManyNodes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.CheckStructure(tt.html)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("CheckStructure() = %v, want %v", err, tt.want)
+			}
+			var limitErr *converter.LimitError
+			if tt.want != nil && (!errors.As(err, &limitErr) || limitErr.Details() == nil) {
+				t.Errorf("CheckStructure() = %#v, want a *LimitError with details", err)
+			}
+		})
+	}
+
+	if err := (converter.Limits{}).CheckStructure(strings.Repeat("<div>", 10000)); err != nil {
+		t.Errorf("Zero limits: CheckStructure() = %v, want nil", err)
+	}
+}
+
+// TestConversionLimits 测试转换遵守当前限制
+func TestConversionLimits(t *testing.T) {
+	defer converter.UseLimits(converter.CurrentLimits())
+
+	converter.UseLimits(converter.Limits{MaxDepth: 2})
+	_, err := converter.Convert(converter.Request{HTML: `<div><div><div>x</div></div></div>`, Direction: converter.DirectionHTMLToGo})
+	if !errors.Is(err, converter.ErrTooDeep) {
+		t.Errorf("Convert() error = %v, want %v", err, converter.ErrTooDeep)
+	}
+
+	converter.UseLimits(converter.Limits{Timeout: time.Nanosecond})
+	html := strings.Repeat(`<div class="a"><p>text</p></div>`, 2000)
+	_, err = converter.ConvertContext(context.Background(), converter.Request{HTML: html, Direction: converter.DirectionHTMLToGo})
+	if !errors.Is(err, converter.ErrTimeout) {
+		t.Errorf("ConvertContext() error = %v, want %v", err, converter.ErrTimeout)
+	}
+
+	// 调用方取消时返回取消原因而非超时
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	converter.UseLimits(converter.Limits{Timeout: time.Minute})
+	if _, err = converter.ConvertContext(ctx, converter.Request{HTML: html, Direction: converter.DirectionHTMLToGo}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Cancelled ConvertContext() error = %v, want %v", err, context.Canceled)
+	}
+}
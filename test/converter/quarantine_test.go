@@ -0,0 +1,82 @@
+package converter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"html2go-converter/converter"
+)
+
+// crashingHTML 会使html2go发生空指针panic
+const crashingHTML = `<!---->`
+
+// TestPanicRecovery 测试转换器panic被转为带复现ID的错误
+func TestPanicRecovery(t *testing.T) {
+	req := converter.Request{HTML: crashingHTML, Direction: converter.DirectionHTMLToGo}
+
+	// 在goroutine中运行的转换同样不能使进程崩溃
+	_, err := converter.ConvertContext(context.Background(), req)
+	var panicErr *converter.PanicError
+	if !errors.As(err, &panicErr) || !errors.Is(err, converter.ErrConverterPanic) {
+		t.Fatalf("ConvertContext() error = %v, want a *PanicError", err)
+	}
+	if panicErr.ReproductionID != converter.ReproductionID(req) || len(panicErr.ReproductionID) != 16 {
+		t.Errorf("ReproductionID = %q, want %q", panicErr.ReproductionID, converter.ReproductionID(req))
+	}
+
+	other := req
+	other.ChildrenMode = true
+	if converter.ReproductionID(other) == converter.ReproductionID(req) {
+		t.Error("ReproductionID ignores the options")
+	}
+
+	// 批量转换中崩溃的条目单独失败
+	resp := converter.ConvertBatch(context.Background(), converter.BatchRequest{
+		Defaults: converter.Request{Direction: converter.DirectionHTMLToGo},
+		Items:    []converter.BatchItem{{HTML: crashingHTML}, {HTML: `<p>ok</p>`}},
+	}, 2)
+	if resp.Failed != 1 || resp.Succeeded != 1 {
+		t.Errorf("Batch succeeded, failed = %d, %d, want 1, 1", resp.Succeeded, resp.Failed)
+	}
+}
+
+// TestQuarantine 测试隔离目录保存崩溃输入、去重并限制大小
+func TestQuarantine(t *testing.T) {
+	dir := t.TempDir()
+	q, err := converter.NewQuarantine(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converter.UseQuarantine(q)
+	defer converter.UseQuarantine(nil)
+
+	req := converter.Request{HTML: crashingHTML, Direction: converter.DirectionHTMLToGo}
+	for i := 0; i < 2; i++ {
+		converter.Convert(req)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("Quarantined %d files, want 1", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	var entry converter.QuarantineEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.ReproductionID != converter.ReproductionID(req) || entry.Request.HTML != crashingHTML || entry.Panic == "" {
+		t.Errorf("Entry = %+v", entry)
+	}
+
+	// 超出容量时删除最旧的条目
+	q.MaxBytes = int64(len(data)) + 100
+	converter.Convert(converter.Request{HTML: `<!DOCTYPE>`, Direction: converter.DirectionHTMLToGo})
+	files, _ = filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 || filepath.Base(files[0]) == entry.ReproductionID+".json" {
+		t.Errorf("Quarantine after trim = %v, want only the newest entry", files)
+	}
+}
@@ -0,0 +1,119 @@
+package converter_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"html2go-converter/converter"
+	"html2go-converter/diff"
+)
+
+// TestVerifyRoundTrip 测试生成的代码渲染回HTML后与输入一致
+func TestVerifyRoundTrip(t *testing.T) {
+	const input = `<div class="a"><ul><li>x</li><li class="y">z</li></ul></div><input type="text" name="q">`
+	for _, prefix := range []string{"h", ""} {
+		for _, childrenMode := range []bool{false, true} {
+			req := converter.Request{HTML: input, PackagePrefix: prefix, Direction: converter.DirectionHTMLToGo, ChildrenMode: childrenMode, Verify: true}
+			resp, err := converter.Convert(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := resp.Verification
+			if v == nil || !v.Equal || v.Error != "" || len(v.Mismatches) != 0 {
+				t.Errorf("prefix %q, childrenMode %v: verification = %+v", prefix, childrenMode, v)
+			}
+		}
+	}
+
+	resp, _ := converter.Convert(converter.Request{HTML: input, Direction: converter.DirectionHTMLToGo})
+	if resp.Verification != nil {
+		t.Errorf("Verification without verify = %+v", resp.Verification)
+	}
+}
+
+// TestVerifyMismatches 测试渲染结果与输入不同时按路径报告差异
+func TestVerifyMismatches(t *testing.T) {
+	// htmlgo省略值为空的属性，布尔属性因此在往返中丢失
+	req := converter.Request{HTML: `<div><ul><li>a</li><li hidden>b</li></ul></div>`, PackagePrefix: "h", Direction: converter.DirectionHTMLToGo, Verify: true}
+	resp, err := converter.Convert(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := resp.Verification
+	want := []diff.Change{{Op: diff.OpRemoved, Path: "div[0]/ul[0]/li[1]@hidden"}}
+	if v.Equal || len(v.Mismatches) != 1 || v.Mismatches[0] != want[0] {
+		t.Errorf("verification = %+v, want mismatches %v", v, want)
+	}
+	if v.HTML == "" {
+		t.Error("verification has no rendered HTML")
+	}
+}
+
+// TestVerifyComponents 测试Vuetify组件按组件表渲染为对应的标签和属性
+func TestVerifyComponents(t *testing.T) {
+	req := converter.Request{
+		HTML:           `<v-card class="pa-2"><v-card-title>Title</v-card-title></v-card>`,
+		PackagePrefix:  "h",
+		VuetifyPrefix:  "v",
+		VuetifyXPrefix: "vx",
+		Direction:      converter.DirectionHTMLToGo,
+		ChildrenMode:   true,
+	}
+	resp, err := converter.Convert(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, err := converter.RenderCode(resp.Code, req)
+	if err != nil {
+		t.Fatalf("RenderCode(%q): %v", resp.Code, err)
+	}
+	if changes, _ := diff.HTML(req.HTML, html); len(changes) != 0 || !strings.Contains(html, "<v-card-title>Title</v-card-title>") {
+		t.Errorf("HTML = %q, changes = %v", html, changes)
+	}
+}
+
+// TestRenderCodeUnsupported 测试无法求值的代码返回ErrUnsupportedCode
+func TestRenderCodeUnsupported(t *testing.T) {
+	req := converter.Request{PackagePrefix: "h"}
+	for _, code := range []string{
+		`h.Div(`,
+		`os.Exit(1)`,
+		`h.Div().Foo("x")`,
+		`h.Div(x)`,
+	} {
+		if _, err := converter.RenderCode(code, req); !errors.Is(err, converter.ErrUnsupportedCode) {
+			t.Errorf("RenderCode(%q) error = %v", code, err)
+		}
+	}
+}
+
+// TestArchiveVerify 测试归档转换在verify模式下记录差异
+func TestArchiveVerify(t *testing.T) {
+	files := []converter.SourceFile{
+		{Path: "ok.html", Content: []byte(`<p class="a">x</p>`)},
+		{Path: "bad.html", Content: []byte(`<p hidden>x</p>`)},
+	}
+	data, manifest, err := converter.ConvertArchive(context.Background(), files, converter.ArchiveOptions{
+		Options: converter.Request{PackagePrefix: "h", Verify: true},
+	})
+	if err != nil {
+		t.Fatalf("ConvertArchive failed: %v", err)
+	}
+	if manifest.Mismatched != 1 {
+		t.Errorf("Mismatched = %d, want 1", manifest.Mismatched)
+	}
+	for _, entry := range manifest.Files {
+		if bad := entry.Source == "bad.html"; bad != (len(entry.Mismatches) == 1) {
+			t.Errorf("%s mismatches = %v", entry.Source, entry.Mismatches)
+		}
+	}
+
+	var written converter.Manifest
+	json.Unmarshal([]byte(readZipFiles(t, data)["manifest.json"]), &written)
+	if written.Mismatched != 1 {
+		t.Errorf("manifest.json = %+v", written)
+	}
+}
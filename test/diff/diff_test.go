@@ -0,0 +1,91 @@
+package diff_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"html2go-converter/diff"
+)
+
+// TestHTML 测试HTML的结构化差异与节点路径
+func TestHTML(t *testing.T) {
+	old := `<div class="a"><ul><li>1</li><li class="x">2</li></ul></div>`
+	new := `<div class="b" id="q">
+  <p>new</p>
+  <ul><li>1</li><li class="y">2!</li><li>3</li></ul>
+</div>`
+	changes, err := diff.HTML(old, new)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []diff.Change{
+		{Op: diff.OpChanged, Path: "div[0]@class", Old: "a", New: "b"},
+		{Op: diff.OpAdded, Path: "div[0]@id", New: "q"},
+		{Op: diff.OpAdded, Path: "div[0]/p[0]", New: "<p>new</p>"},
+		{Op: diff.OpChanged, Path: "div[0]/ul[1]/li[1]@class", Old: "x", New: "y"},
+		{Op: diff.OpChanged, Path: "div[0]/ul[1]/li[1]/#text[0]", Old: "2", New: "2!"},
+		{Op: diff.OpAdded, Path: "div[0]/ul[1]/li[2]", New: "<li>3</li>"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("HTML changes:\n%v\nwant:\n%v", changes, want)
+	}
+}
+
+// TestHTMLIgnoresFormatting 测试空白、注释和属性顺序不算差异
+func TestHTMLIgnoresFormatting(t *testing.T) {
+	old := `<div id="a" class="b"><p>some   text</p></div>`
+	new := "<div class=\"b\" id=\"a\">\n  <!-- note -->\n  <p>some\n text</p>\n</div>\n"
+	changes, err := diff.HTML(old, new)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("HTML = %v, %v, want no changes", changes, err)
+	}
+
+	changes, _ = diff.HTML(`<p>a</p><span>b</span>`, `<span>b</span>`)
+	if len(changes) != 1 || changes[0].Op != diff.OpRemoved || changes[0].Path != "p[0]" {
+		t.Errorf("Removed node = %v", changes)
+	}
+}
+
+// TestLines 测试按行差异与统一格式输出
+func TestLines(t *testing.T) {
+	lines := diff.Lines("a\nb\nc\nd\ne\nf\ng\nh\n", "a\nB\nc\nd\ne\nf\ng\nh\ni\n")
+	if added, removed := diff.Count(lines); added != 2 || removed != 1 {
+		t.Errorf("Count = %d, %d, want 2, 1", added, removed)
+	}
+
+	want := `--- old
++++ new
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -8 +8,2 @@
+ h
++i
+`
+	if got := diff.Unified("old", "new", lines, 1); got != want {
+		t.Errorf("Unified =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := diff.Unified("old", "new", diff.Lines("", "x\n"), 3); !strings.Contains(got, "@@ -0,0 +1 @@\n+x\n") {
+		t.Errorf("Unified of an addition to nothing =\n%s", got)
+	}
+	if got := diff.Unified("old", "new", diff.Lines("same\n", "same\n"), 3); got != "" {
+		t.Errorf("Unified of equal texts = %q, want empty", got)
+	}
+}
+
+// TestLinesLarge 测试完全不同的大文本也能快速比较
+func TestLinesLarge(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i < 20000; i++ {
+		a.WriteString("old line\n")
+		b.WriteString("new line\n")
+	}
+	added, removed := diff.Count(diff.Lines(a.String(), b.String()))
+	if added != 20000 || removed != 20000 {
+		t.Errorf("Count = %d, %d, want 20000, 20000", added, removed)
+	}
+}
@@ -0,0 +1,114 @@
+package examples_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"html2go-converter/examples"
+)
+
+// TestEmbeddedExamples 测试内嵌的所有示例都能通过校验并转换
+func TestEmbeddedExamples(t *testing.T) {
+	registry, err := examples.Load(examples.Embedded())
+	if err != nil {
+		t.Fatalf("Load embedded examples: %v", err)
+	}
+	summaries := registry.List(examples.Filter{})
+	if len(summaries) == 0 {
+		t.Fatal("No embedded examples")
+	}
+	for _, s := range summaries {
+		e, err := registry.Get(s.ID)
+		if err != nil || e.Code == "" || e.HTML == "" {
+			t.Errorf("Example %s = %+v, %v", s.ID, e, err)
+		}
+	}
+	if summaries[0].ID != "basic-structure" {
+		t.Errorf("First example = %q, want basic-structure", summaries[0].ID)
+	}
+}
+
+// TestLoad 测试注释解析、默认选项和过滤
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"01-plain.html":  {Data: []byte("<!--\ntitle: Plain\ncategory: html\ntags: layout, Tailwind\n-->\n<div class=\"a\">x</div>\n")},
+		"02-dialog.html": {Data: []byte("<!--\ntitle: Dialog\ndescription: A dialog\ncategory: vuetifyx\ntags: dialog\npackagePrefix:\nvuetifyXPrefix: x\nchildrenMode: true\n-->\n<vx-dialog>y</vx-dialog>")},
+		"notes.txt":      {Data: []byte("ignored")},
+	}
+	registry, err := examples.Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := registry.Get("plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Options != (examples.Options{PackagePrefix: "h", VuetifyPrefix: "v", VuetifyXPrefix: "vx"}) {
+		t.Errorf("Default options = %+v", plain.Options)
+	}
+	if !strings.Contains(plain.Code, `h.Div(`) {
+		t.Errorf("Code = %q, want h.Div(", plain.Code)
+	}
+
+	dialog, _ := registry.Get("dialog")
+	if dialog.Options != (examples.Options{PackagePrefix: "", VuetifyPrefix: "v", VuetifyXPrefix: "x", ChildrenMode: true}) {
+		t.Errorf("Dialog options = %+v", dialog.Options)
+	}
+	if dialog.Description != "A dialog" || dialog.Category != "vuetifyx" {
+		t.Errorf("Dialog = %+v", dialog.Summary)
+	}
+
+	tests := []struct {
+		filter examples.Filter
+		want   []string
+	}{
+		{examples.Filter{}, []string{"plain", "dialog"}},
+		{examples.Filter{Category: "VuetifyX"}, []string{"dialog"}},
+		{examples.Filter{Tag: "tailwind"}, []string{"plain"}},
+		{examples.Filter{Query: "a dia"}, []string{"dialog"}},
+		{examples.Filter{Query: "nothing"}, nil},
+	}
+	for _, tt := range tests {
+		var ids []string
+		for _, s := range registry.List(tt.filter) {
+			ids = append(ids, s.ID)
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("List(%+v) = %v, want %v", tt.filter, ids, tt.want)
+		}
+	}
+
+	if got := registry.Tags(); strings.Join(got, ",") != "Tailwind,dialog,layout" {
+		t.Errorf("Tags = %v", got)
+	}
+	if _, err := registry.Get("missing"); !errors.Is(err, examples.ErrNotFound) {
+		t.Errorf("Get missing error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestLoadInvalid 测试无效示例在加载时全部报告
+func TestLoadInvalid(t *testing.T) {
+	fsys := fstest.MapFS{
+		"no-header.html":     {Data: []byte("<div>x</div>")},
+		"no-title.html":      {Data: []byte("<!--\ncategory: html\n-->\n<div>x</div>")},
+		"unknown-key.html":   {Data: []byte("<!--\ntitle: T\ncategory: html\ncolour: red\n-->\n<div>x</div>")},
+		"bad-bool.html":      {Data: []byte("<!--\ntitle: T\ncategory: html\nchildrenMode: maybe\n-->\n<div>x</div>")},
+		"empty.html":         {Data: []byte("<!--\ntitle: T\ncategory: html\n-->\n")},
+		"Bad_ID.html":        {Data: []byte("<!--\ntitle: T\ncategory: html\n-->\n<div>x</div>")},
+		"01-dup.html":        {Data: []byte("<!--\ntitle: T\ncategory: html\n-->\n<div>x</div>")},
+		"02-dup.html":        {Data: []byte("<!--\ntitle: T\ncategory: html\n-->\n<div>x</div>")},
+		"converter-bug.html": {Data: []byte("<!--\ntitle: T\ncategory: html\n-->\n<!DOCTYPE html><div>x</div>")},
+	}
+	_, err := examples.Load(fsys)
+	if err == nil {
+		t.Fatal("Load succeeded, want errors")
+	}
+	for _, name := range []string{"no-header", "no-title", "unknown-key", "bad-bool", "empty", "Bad_ID", "02-dup", "converter-bug"} {
+		if !strings.Contains(err.Error(), name+".html") {
+			t.Errorf("Error does not report %s.html:\n%v", name, err)
+		}
+	}
+}
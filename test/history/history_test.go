@@ -0,0 +1,227 @@
+package history_test
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/history"
+)
+
+// TestStores 测试内存与BoltDB存储的修订版本编号和查询
+func TestStores(t *testing.T) {
+	bolt, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolt.Close()
+
+	for name, store := range map[string]history.Store{"memory": history.NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			id := history.NewID()
+			if _, err := store.Revisions(id); !errors.Is(err, history.ErrNotFound) {
+				t.Errorf("Revisions of unknown history error = %v, want ErrNotFound", err)
+			}
+
+			appendCode := func(code string) (history.Revision, bool, error) {
+				return store.Append(id, func(history.Revision) (history.Revision, bool) {
+					return history.Revision{Code: code}, true
+				})
+			}
+			if _, _, err := appendCode("one"); !errors.Is(err, history.ErrNotFound) {
+				t.Errorf("Append to unknown history error = %v, want ErrNotFound", err)
+			}
+			if rev, err := store.Create(id, history.Revision{Code: "one"}); err != nil || rev.Number != 1 {
+				t.Fatalf("Create = %+v, %v, want number 1", rev, err)
+			}
+			if _, err := store.Create(id, history.Revision{Code: "other"}); !errors.Is(err, history.ErrExists) {
+				t.Errorf("Create of existing history error = %v, want ErrExists", err)
+			}
+			for i, code := range []string{"two", "three"} {
+				rev, appended, err := appendCode(code)
+				if err != nil || !appended || rev.Number != i+2 {
+					t.Fatalf("Append = %+v, %v, %v, want number %d", rev, appended, err, i+2)
+				}
+			}
+			head, appended, err := store.Append(id, func(head history.Revision) (history.Revision, bool) {
+				return head, false
+			})
+			if err != nil || appended || head.Number != 3 || head.Code != "three" {
+				t.Errorf("Declined Append = %+v, %v, %v, want the head", head, appended, err)
+			}
+
+			revisions, err := store.Revisions(id)
+			if err != nil || len(revisions) != 3 || revisions[2].Code != "three" {
+				t.Errorf("Revisions = %+v, %v", revisions, err)
+			}
+			if rev, err := store.Revision(id, 2); err != nil || rev.Code != "two" {
+				t.Errorf("Revision 2 = %+v, %v", rev, err)
+			}
+			for _, n := range []int{0, 4} {
+				if _, err := store.Revision(id, n); !errors.Is(err, history.ErrRevisionNotFound) {
+					t.Errorf("Revision %d error = %v, want ErrRevisionNotFound", n, err)
+				}
+			}
+		})
+	}
+}
+
+// TestSaveAndRestore 测试保存去重与恢复旧版本
+func TestSaveAndRestore(t *testing.T) {
+	store := history.NewMemoryStore()
+	now := time.Now()
+	first := converter.Request{HTML: "<p>a</p>", PackagePrefix: "h"}
+
+	if _, _, err := history.Save(store, history.DefaultPolicy, history.NewID(), first, "h.P()", now); !errors.Is(err, history.ErrNotFound) {
+		t.Errorf("Save to unknown history error = %v, want ErrNotFound", err)
+	}
+	id, rev, err := history.Start(store, first, "h.P()", now)
+	if err != nil || !history.ValidID(id) || rev.Number != 1 {
+		t.Fatalf("Start = %q, %+v, %v", id, rev, err)
+	}
+	if rev, created, _ := history.Save(store, history.DefaultPolicy, id, first, "h.P()", now); created || rev.Number != 1 {
+		t.Errorf("Saving the head again = %+v, %v, want revision 1 unchanged", rev, created)
+	}
+	history.Save(store, history.DefaultPolicy, id, converter.Request{HTML: "<p>b</p>", PackagePrefix: "x"}, "x.P()", now)
+
+	restored, err := history.Restore(store, history.DefaultPolicy, id, 1, now)
+	if err != nil || restored.Number != 3 || restored.RestoredFrom != 1 || restored.HTML != "<p>a</p>" || restored.Code != "h.P()" {
+		t.Errorf("Restore = %+v, %v", restored, err)
+	}
+	if old, _ := store.Revision(id, 1); old.RestoredFrom != 0 {
+		t.Error("Restore changed the restored revision")
+	}
+	if _, err := history.Restore(store, history.DefaultPolicy, id, 9, now); !errors.Is(err, history.ErrRevisionNotFound) {
+		t.Errorf("Restore of missing revision error = %v", err)
+	}
+}
+
+// TestConcurrentSave 测试并发保存相同内容时只追加一个版本
+func TestConcurrentSave(t *testing.T) {
+	bolt, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolt.Close()
+
+	for name, store := range map[string]history.Store{"memory": history.NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			id, _, err := history.Start(store, converter.Request{HTML: "<p>a</p>"}, "h.P()", now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					history.Save(store, history.DefaultPolicy, id, converter.Request{HTML: "<p>b</p>"}, "h.P()", now)
+				}()
+			}
+			wg.Wait()
+			if revisions, _ := store.Revisions(id); len(revisions) != 2 {
+				t.Errorf("Revisions = %d, want 2", len(revisions))
+			}
+		})
+	}
+}
+
+// TestSweep 测试按保留时间和数量清理历史
+func TestSweep(t *testing.T) {
+	bolt, err := history.OpenBoltStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolt.Close()
+
+	for name, store := range map[string]history.Store{"memory": history.NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			start := func(at time.Time) string {
+				id, _, err := history.Start(store, converter.Request{HTML: "<p>a</p>"}, "h.P()", at)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return id
+			}
+			expired := start(now.Add(-2 * time.Hour))
+			oldest := start(now.Add(-50 * time.Minute))
+			// 最近保存的时间决定清理顺序
+			resaved := start(now.Add(-55 * time.Minute))
+			history.Save(store, history.DefaultPolicy, resaved, converter.Request{HTML: "<p>b</p>"}, "h.P()", now.Add(-time.Minute))
+			newest := start(now.Add(-10 * time.Minute))
+
+			policy := history.Policy{TTL: time.Hour, MaxHistories: 2}
+			if n, err := store.Sweep(now, policy); err != nil || n != 2 {
+				t.Fatalf("Sweep = %d, %v, want 2", n, err)
+			}
+			for _, id := range []string{expired, oldest} {
+				if _, err := store.Revisions(id); !errors.Is(err, history.ErrNotFound) {
+					t.Errorf("Swept history still has revisions: %v", err)
+				}
+			}
+			for _, id := range []string{resaved, newest} {
+				if _, err := store.Revisions(id); err != nil {
+					t.Errorf("Sweep removed a kept history: %v", err)
+				}
+			}
+			if n, _ := store.Sweep(now, policy); n != 0 {
+				t.Errorf("Second Sweep = %d, want 0", n)
+			}
+		})
+	}
+}
+
+// TestMaxRevisions 测试历史达到最大版本数后拒绝保存和恢复
+func TestMaxRevisions(t *testing.T) {
+	store := history.NewMemoryStore()
+	policy := history.Policy{MaxRevisions: 2}
+	now := time.Now()
+	id, _, _ := history.Start(store, converter.Request{HTML: "<p>a</p>"}, "h.P()", now)
+	if _, created, err := history.Save(store, policy, id, converter.Request{HTML: "<p>b</p>"}, "h.P()", now); err != nil || !created {
+		t.Fatalf("Save below the limit = %v, %v", created, err)
+	}
+	if _, _, err := history.Save(store, policy, id, converter.Request{HTML: "<p>c</p>"}, "h.P()", now); !errors.Is(err, history.ErrHistoryFull) {
+		t.Errorf("Save beyond the limit error = %v, want ErrHistoryFull", err)
+	}
+	if rev, created, err := history.Save(store, policy, id, converter.Request{HTML: "<p>b</p>"}, "h.P()", now); err != nil || created || rev.Number != 2 {
+		t.Errorf("Saving the head of a full history = %+v, %v, %v, want the head", rev, created, err)
+	}
+	if _, err := history.Restore(store, policy, id, 1, now); !errors.Is(err, history.ErrHistoryFull) {
+		t.Errorf("Restore beyond the limit error = %v, want ErrHistoryFull", err)
+	}
+}
+
+// TestCompare 测试两个版本之间的选项、HTML和Go代码差异
+func TestCompare(t *testing.T) {
+	a := history.Revision{Number: 1, Request: converter.Request{HTML: `<p class="a">x</p>`, PackagePrefix: "h"}, Code: "h.P(\n\th.Text(\"x\"),\n).Class(\"a\")"}
+	b := history.Revision{Number: 2, Request: converter.Request{HTML: `<p class="b">x</p>`, PackagePrefix: "v"}, Code: "v.P(\n\tv.Text(\"x\"),\n).Class(\"b\")"}
+	d, err := history.Compare(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Options) != 1 || d.Options[0].Path != "packagePrefix" || d.Options[0].New != "v" {
+		t.Errorf("Options = %+v", d.Options)
+	}
+	if len(d.HTML) != 1 || d.HTML[0].Path != "p[0]@class" {
+		t.Errorf("HTML = %+v", d.HTML)
+	}
+	if d.Go.Added != 3 || d.Go.Removed != 3 || d.Go.Unified == "" {
+		t.Errorf("Go = %+v", d.Go)
+	}
+
+	verified := a
+	verified.Verify = true
+	if d, _ := history.Compare(a, verified); len(d.Options) != 1 || d.Options[0].Path != "verify" || d.Options[0].New != "true" {
+		t.Errorf("Options with verify changed = %+v", d.Options)
+	}
+
+	same, _ := history.Compare(a, a)
+	if len(same.Options) != 0 || len(same.HTML) != 0 || same.Go.Unified != "" {
+		t.Errorf("Compare with itself = %+v", same)
+	}
+}
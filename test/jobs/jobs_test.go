@@ -0,0 +1,287 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/api"
+	"html2go-converter/apierror"
+	"html2go-converter/converter"
+	"html2go-converter/jobs"
+)
+
+func singleInput(html string) jobs.Input {
+	return jobs.Input{Request: converter.Request{HTML: html, PackagePrefix: "h", Direction: converter.DirectionHTMLToGo}}
+}
+
+// waitFinished 轮询任务直到其结束
+func waitFinished(t *testing.T, m *jobs.Manager, id string) jobs.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get(%s) failed: %v", id, err)
+		}
+		if job.Status.Final() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+	return jobs.Job{}
+}
+
+// TestJobsHandler 测试通过HTTP提交、轮询并获取任务结果
+func TestJobsHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := jobs.NewManager(jobs.NewMemoryStore(), jobs.Options{})
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	api.UseJobManager(m)
+
+	rec := httptest.NewRecorder()
+	body := `{"html": "<div>a</div>", "packagePrefix": "h", "direction": "html2go"}`
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(body)))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var created api.JobResponse
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if rec.Header().Get("Location") != created.StatusURL || created.Progress.Total != 1 {
+		t.Errorf("Unexpected job: %+v, Location %q", created, rec.Header().Get("Location"))
+	}
+
+	waitFinished(t, m, created.ID)
+
+	rec = httptest.NewRecorder()
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodGet, created.StatusURL, nil))
+	var status api.JobResponse
+	json.Unmarshal(rec.Body.Bytes(), &status)
+	if status.Status != jobs.StatusSucceeded || status.Progress.Done != 1 {
+		t.Errorf("Status = %+v, want succeeded with progress 1/1", status)
+	}
+
+	rec = httptest.NewRecorder()
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodGet, created.ResultURL, nil))
+	var result converter.Response
+	json.Unmarshal(rec.Body.Bytes(), &result)
+	if rec.Code != http.StatusOK || !strings.Contains(result.Code, "h.Div(") {
+		t.Errorf("Result = %d %s", rec.Code, rec.Body.String())
+	}
+
+	// Invalid input is rejected before it is queued
+	rec = httptest.NewRecorder()
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"html": ""}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Empty HTML status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodGet, "/api/jobs/0123456789abcdef0123456789abcdef", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Unknown job status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+// TestBatchJobProgress 测试批量任务按项统计进度并返回批量结果
+func TestBatchJobProgress(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := jobs.NewManager(jobs.NewMemoryStore(), jobs.Options{})
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	input := jobs.Input{
+		Request: converter.Request{PackagePrefix: "h", Direction: converter.DirectionHTMLToGo},
+		Items:   []converter.BatchItem{{Name: "a", HTML: "<p>a</p>"}, {Name: "b", HTML: "<p>b</p>"}, {Name: "c", HTML: ""}},
+	}
+	job, err := m.Submit(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job = waitFinished(t, m, job.ID)
+	if job.Status != jobs.StatusSucceeded || job.Progress != (jobs.Progress{Done: 3, Total: 3}) {
+		t.Errorf("Job = %s %+v, want succeeded 3/3", job.Status, job.Progress)
+	}
+	if job.BatchResult == nil || job.BatchResult.Succeeded != 2 || job.BatchResult.Failed != 1 {
+		t.Errorf("Unexpected batch result: %+v", job.BatchResult)
+	}
+}
+
+// TestQueueLimitAndCancel 测试队列上限与取消排队中的任务
+func TestQueueLimitAndCancel(t *testing.T) {
+	// Without Start no worker picks up jobs
+	m := jobs.NewManager(jobs.NewMemoryStore(), jobs.Options{QueueSize: 1})
+	job, err := m.Submit(singleInput("<p>a</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Submit(singleInput("<p>b</p>")); err != jobs.ErrQueueFull {
+		t.Errorf("Submit on full queue = %v, want %v", err, jobs.ErrQueueFull)
+	}
+
+	cancelled, err := m.Cancel(job.ID)
+	if err != nil || cancelled.Status != jobs.StatusCancelled {
+		t.Fatalf("Cancel = %s, %v", cancelled.Status, err)
+	}
+	if _, err := m.Cancel(job.ID); err != jobs.ErrJobFinished {
+		t.Errorf("Second cancel = %v, want %v", err, jobs.ErrJobFinished)
+	}
+	if _, err := m.Submit(singleInput("<p>b</p>")); err != nil {
+		t.Errorf("Cancelling did not free the queue: %v", err)
+	}
+}
+
+// TestJobExpiry 测试任务超过TTL后不可见并被清理
+func TestJobExpiry(t *testing.T) {
+	store := jobs.NewMemoryStore()
+	m := jobs.NewManager(store, jobs.Options{TTL: 10 * time.Millisecond})
+	job, err := m.Submit(singleInput("<p>a</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := m.Get(job.ID); err != jobs.ErrNotFound {
+		t.Errorf("Get expired job = %v, want %v", err, jobs.ErrNotFound)
+	}
+	m.Sweep()
+	if _, err := store.Get(job.ID); err != jobs.ErrNotFound {
+		t.Errorf("Expired job still stored: %v", err)
+	}
+}
+
+// TestDirStoreResume 测试重启后继续执行持久化的排队任务
+func TestDirStoreResume(t *testing.T) {
+	dir := t.TempDir()
+	store, err := jobs.NewDirStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first manager never runs its queue, as if it stopped right away
+	job, err := jobs.NewManager(store, jobs.Options{}).Submit(singleInput("<p>resumed</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := jobs.NewDirStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := jobs.NewManager(reopened, jobs.Options{})
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	done := waitFinished(t, m, job.ID)
+	if done.Status != jobs.StatusSucceeded || done.Result == nil || !strings.Contains(done.Result.Code, `h.Text("resumed")`) {
+		t.Errorf("Resumed job = %+v", done)
+	}
+
+	if _, err := reopened.Get("../../etc/passwd"); err != jobs.ErrNotFound {
+		t.Errorf("Get with invalid ID = %v, want %v", err, jobs.ErrNotFound)
+	}
+}
+
+// TestJobLimits 测试任务不受单次请求的时长、并发与请求体限制，只受任务自身的限制
+func TestJobLimits(t *testing.T) {
+	defer converter.UseLimits(converter.CurrentLimits())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := jobs.NewManager(jobs.NewMemoryStore(), jobs.Options{})
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	api.UseJobManager(m)
+
+	// 请求的转换时长和请求体都远小于任务需要的
+	html := strings.Repeat(`<div class="a"><p>job</p></div>`, 2000)
+	api.UseLimits(converter.Limits{MaxBodyBytes: 64, Timeout: time.Nanosecond, MaxConcurrent: 1, QueueTimeout: time.Nanosecond}, nil)
+	if _, err := converter.ConvertContext(context.Background(), singleInput(html).Request); !errors.Is(err, converter.ErrTimeout) {
+		t.Fatalf("ConvertContext() error = %v, want %v", err, converter.ErrTimeout)
+	}
+
+	body, _ := json.Marshal(singleInput(html).Request)
+	rec := httptest.NewRecorder()
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(string(body))))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+	}
+	var created api.JobResponse
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if job := waitFinished(t, m, created.ID); job.Status != jobs.StatusSucceeded || job.Result == nil {
+		t.Errorf("Job = %s %q, want succeeded", job.Status, job.Error)
+	}
+
+	batch := jobs.Input{
+		Request: singleInput("").Request,
+		Items:   []converter.BatchItem{{Name: "a", HTML: html + "<p>a</p>"}, {Name: "b", HTML: html + "<p>b</p>"}},
+	}
+	job, err := m.Submit(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job = waitFinished(t, m, job.ID); job.BatchResult == nil || job.BatchResult.Succeeded != 2 {
+		t.Errorf("Batch result = %+v", job.BatchResult)
+	}
+
+	// 任务自身的时长与请求体限制仍然生效
+	api.UseLimits(converter.Limits{JobMaxBodyBytes: 64, JobTimeout: time.Nanosecond}, nil)
+	job, err = m.Submit(singleInput(html + "<p>timeout</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job = waitFinished(t, m, job.ID); job.Status != jobs.StatusFailed || !strings.Contains(job.Error, converter.ErrTimeout.Error()) {
+		t.Errorf("Job = %s %q, want failed with %v", job.Status, job.Error, converter.ErrTimeout)
+	}
+
+	rec = httptest.NewRecorder()
+	api.JobsHandler(rec, httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(string(body))))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Oversized job status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// TestFailedJobResult 测试失败任务的结果返回与同步转换相同的错误码和状态码
+func TestFailedJobResult(t *testing.T) {
+	defer converter.UseLimits(converter.CurrentLimits())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := jobs.NewManager(jobs.NewMemoryStore(), jobs.Options{})
+	if err := m.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	api.UseJobManager(m)
+	api.UseLimits(converter.Limits{MaxDepth: 2}, nil)
+
+	job, err := m.Submit(singleInput("<div><div><div>deep</div></div></div>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job = waitFinished(t, m, job.ID); job.Status != jobs.StatusFailed {
+		t.Fatalf("Job = %s, want failed", job.Status)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs/"+job.ID+"/result", nil)
+	req.Header.Set("Accept", "application/problem+json")
+	api.JobsHandler(rec, req)
+	var problem apierror.Problem
+	json.Unmarshal(rec.Body.Bytes(), &problem)
+	if rec.Code != http.StatusUnprocessableEntity || problem.Code != apierror.CodeTooDeep || problem.Field != "html" || problem.Details["maxDepth"] != 2.0 {
+		t.Errorf("Result = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,132 @@
+package live_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"html2go-converter/converter"
+	"html2go-converter/live"
+	"html2go-converter/middleware"
+	"html2go-converter/routes"
+)
+
+func edit(seq int64, html string) live.ClientMessage {
+	return live.ClientMessage{Seq: seq, HTML: &html}
+}
+
+// TestSessionCoalescesEdits 测试快速连续的编辑只转换最后一次
+func TestSessionCoalescesEdits(t *testing.T) {
+	results := make(chan live.ServerMessage, 10)
+	session := live.NewSession(converter.Request{PackagePrefix: "h"}, 50*time.Millisecond, func(msg live.ServerMessage) error {
+		results <- msg
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go session.Run(ctx)
+
+	for i := int64(1); i <= 5; i++ {
+		session.Edit(edit(i, "<p>edit</p>"))
+	}
+	session.Edit(edit(6, "<p>last</p>"))
+
+	select {
+	case msg := <-results:
+		if msg.Type != live.TypeResult || msg.Seq != 6 || !strings.Contains(msg.Code, `h.Text("last")`) {
+			t.Errorf("Result = %+v, want the conversion of edit 6", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No result received")
+	}
+
+	select {
+	case msg := <-results:
+		t.Errorf("Unexpected extra result: %+v", msg)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestSessionKeepsOptions 测试会话保留之前发送的选项并自动编号
+func TestSessionKeepsOptions(t *testing.T) {
+	results := make(chan live.ServerMessage, 10)
+	session := live.NewSession(converter.Request{PackagePrefix: "h"}, 0, func(msg live.ServerMessage) error {
+		results <- msg
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go session.Run(ctx)
+
+	prefix := "x"
+	first := edit(0, "<div>a</div>")
+	first.PackagePrefix = &prefix
+	session.Edit(first)
+	msg := <-results
+	if msg.Seq != 1 || !strings.Contains(msg.Code, "x.Div(") {
+		t.Errorf("First result = %+v", msg)
+	}
+
+	session.Edit(edit(0, "<span>b</span>"))
+	msg = <-results
+	if msg.Seq != 2 || !strings.Contains(msg.Code, "x.Span(") {
+		t.Errorf("Second result = %+v", msg)
+	}
+
+	session.Edit(edit(0, ""))
+	msg = <-results
+	if msg.Type != live.TypeError || msg.Error != converter.ErrHTMLRequired.Error() || msg.ErrorCode != "html_required" || msg.ErrorField != "html" || len(msg.Diagnostics) != 1 {
+		t.Errorf("Empty document result = %+v", msg)
+	}
+}
+
+// TestLiveWebSocket 测试通过完整中间件链建立WebSocket并收到转换结果
+func TestLiveWebSocket(t *testing.T) {
+	server := httptest.NewServer(routes.NewHandler(middleware.DefaultSecurityOptions))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/live"
+
+	config, err := websocket.NewConfig(wsURL, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Header.Set("Accept-Encoding", "gzip")
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer ws.Close()
+
+	if err := websocket.JSON.Send(ws, edit(7, "<ul><li>x</li></ul>")); err != nil {
+		t.Fatal(err)
+	}
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg live.ServerMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if msg.Seq != 7 || !strings.Contains(msg.Code, ".Ul(") {
+		t.Errorf("Result = %+v", msg)
+	}
+
+	// Handshakes from another site are rejected
+	config.Origin, _ = config.Origin.Parse("http://evil.example")
+	if _, err := websocket.DialConfig(config); err == nil {
+		t.Error("Cross-origin handshake succeeded")
+	}
+
+	// Plain HTTP requests are not upgraded
+	resp, err := http.Get(server.URL + "/api/live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired {
+		t.Errorf("Plain GET status = %d, want %d", resp.StatusCode, http.StatusUpgradeRequired)
+	}
+}
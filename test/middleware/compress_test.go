@@ -0,0 +1,183 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"html2go-converter/middleware"
+)
+
+// TestCompress 测试压缩中间件的协商、阈值与类型白名单
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("h.Div(h.Text(\"hello\")),\n", 200)
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		encoding    string
+		body        string
+		want        string
+	}{
+		{name: "Gzip JSON", accept: "gzip, deflate", contentType: "application/json", body: large, want: "gzip"},
+		{name: "Deflate preferred by q", accept: "gzip;q=0.5, deflate", contentType: "application/json", body: large, want: "deflate"},
+		{name: "Below threshold", accept: "gzip", contentType: "application/json", body: "{}", want: ""},
+		{name: "Not in allowlist", accept: "gzip", contentType: "image/png", body: large, want: ""},
+		{name: "Not accepted", accept: "br", contentType: "text/html", body: large, want: ""},
+		{name: "Already encoded", accept: "gzip", contentType: "application/javascript", encoding: "br", body: large, want: "br"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := middleware.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				io.WriteString(w, tt.body)
+			}), middleware.DefaultCompressOptions)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Encoding"); got != tt.want {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && rec.Body.String() != tt.body {
+				t.Errorf("Uncompressed body was modified")
+			}
+			if tt.want == "gzip" {
+				zr, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("Invalid gzip body: %v", err)
+				}
+				body, _ := io.ReadAll(zr)
+				if string(body) != tt.body {
+					t.Errorf("Decompressed body does not match the original")
+				}
+			}
+		})
+	}
+}
+
+// TestCompressStreaming 测试流式响应在Flush时即被压缩发送
+func TestCompressStreaming(t *testing.T) {
+	h := middleware.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i := 0; i < 3; i++ {
+			io.WriteString(w, "{\"index\":1}\n")
+			w.(http.Flusher).Flush()
+		}
+	}), middleware.DefaultCompressOptions)
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL) // Go客户端自动协商gzip并透明解压
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if !resp.Uncompressed {
+		t.Error("Expected the streamed response to be gzip-compressed")
+	}
+	if got := strings.Count(string(body), "\n"); got != 3 {
+		t.Errorf("Expected 3 lines, got %d: %q", got, body)
+	}
+}
+
+// TestCompressVary 测试允许压缩的类型无论是否压缩都带Vary: Accept-Encoding
+func TestCompressVary(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		accept      string
+		contentType string
+		body        string
+		want        string
+	}{
+		{name: "Compressed", method: http.MethodGet, accept: "gzip", contentType: "application/json", body: strings.Repeat("x", 2048), want: "Accept-Encoding"},
+		{name: "Below threshold", method: http.MethodGet, accept: "gzip", contentType: "application/json", body: "{}", want: "Accept-Encoding"},
+		{name: "Not accepted", method: http.MethodGet, contentType: "text/html", body: strings.Repeat("x", 2048), want: "Accept-Encoding"},
+		{name: "HEAD", method: http.MethodHead, accept: "gzip", contentType: "text/html", want: "Accept-Encoding"},
+		{name: "Not in allowlist", method: http.MethodGet, accept: "gzip", contentType: "image/png", body: strings.Repeat("x", 2048), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := middleware.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Header().Add("Vary", "Accept")
+				io.WriteString(w, tt.body)
+			}), middleware.DefaultCompressOptions)
+
+			req := httptest.NewRequest(tt.method, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept-Encoding", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			vary := rec.Header().Values("Vary")
+			if got := strings.Join(vary[1:], ","); vary[0] != "Accept" || got != tt.want {
+				t.Errorf("Vary = %q, want Accept and %q", vary, tt.want)
+			}
+		})
+	}
+}
+
+// TestCompressETag 测试压缩后的响应使用带编码后缀的ETag，且条件请求仍返回304
+func TestCompressETag(t *testing.T) {
+	const etag = `"abc"`
+	body := strings.Repeat("x", 2048)
+	h := middleware.Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, body)
+	}), middleware.DefaultCompressOptions)
+
+	tests := []struct {
+		name        string
+		accept      string
+		ifNoneMatch string
+		wantStatus  int
+		wantETag    string
+	}{
+		{name: "Identity", wantStatus: http.StatusOK, wantETag: etag},
+		{name: "Gzip", accept: "gzip", wantStatus: http.StatusOK, wantETag: `"abc-gzip"`},
+		{name: "Deflate", accept: "deflate", wantStatus: http.StatusOK, wantETag: `"abc-deflate"`},
+		{name: "Gzip revalidated", accept: "gzip", ifNoneMatch: `"abc-gzip"`, wantStatus: http.StatusNotModified, wantETag: `"abc-gzip"`},
+		{name: "Identity revalidated", ifNoneMatch: etag, wantStatus: http.StatusNotModified, wantETag: etag},
+		{name: "Other encoding", accept: "deflate", ifNoneMatch: `"abc-gzip"`, wantStatus: http.StatusOK, wantETag: `"abc-deflate"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept-Encoding", tt.accept)
+			}
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus || rec.Header().Get("ETag") != tt.wantETag {
+				t.Errorf("Response = %d %s, want %d %s", rec.Code, rec.Header().Get("ETag"), tt.wantStatus, tt.wantETag)
+			}
+		})
+	}
+}
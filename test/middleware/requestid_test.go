@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"html2go-converter/middleware"
+)
+
+// TestRequestIDs 测试请求ID的生成、沿用与校验
+func TestRequestIDs(t *testing.T) {
+	var seen string
+	h := middleware.RequestIDs(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = middleware.RequestID(r)
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		want     string
+	}{
+		{"generated", "", ""},
+		{"kept", "abc-123", "abc-123"},
+		{"rejected", "bad id\r\nX-Injected: 1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				r.Header.Set(middleware.RequestIDHeader, tt.incoming)
+			}
+			h.ServeHTTP(rec, r)
+
+			got := rec.Header().Get(middleware.RequestIDHeader)
+			if got == "" || got != seen {
+				t.Fatalf("Header = %q, handler saw %q", got, seen)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("Request ID = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && got == tt.incoming {
+				t.Errorf("Request ID = %q, want a generated ID", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"html2go-converter/api"
+	"html2go-converter/middleware"
+)
+
+// TestSecurityHeaders 测试安全响应头与页面脚本的nonce注入
+func TestSecurityHeaders(t *testing.T) {
+	api.UsePublicDir("")
+	h := middleware.SecurityHeaders(http.HandlerFunc(api.Index), middleware.DefaultSecurityOptions)
+
+	fetch := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+	rec := fetch()
+
+	for header, want := range map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"Referrer-Policy":        "strict-origin-when-cross-origin",
+		"Cache-Control":          "no-store",
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+	if rec.Header().Get("Permissions-Policy") == "" {
+		t.Error("Missing Permissions-Policy header")
+	}
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	if !strings.Contains(csp, "frame-ancestors 'none'") || !strings.Contains(csp, "report-uri /csp-report") {
+		t.Errorf("Unexpected Content-Security-Policy: %q", csp)
+	}
+	match := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(csp)
+	if match == nil {
+		t.Fatalf("Content-Security-Policy has no nonce: %q", csp)
+	}
+
+	// 页面中的每个script标签都带有本次请求的nonce
+	body := rec.Body.String()
+	scripts := strings.Count(body, "<script")
+	if scripts == 0 || strings.Count(body, `<script nonce="`+match[1]+`"`) != scripts {
+		t.Errorf("Not every script tag carries nonce %q", match[1])
+	}
+
+	// 每个请求的nonce都不同
+	if other := fetch().Header().Get("Content-Security-Policy"); other == csp {
+		t.Error("Expected a fresh nonce per request")
+	}
+}
+
+// TestCSPReport 测试CSP违规报告端点
+func TestCSPReport(t *testing.T) {
+	for name, body := range map[string]string{
+		"report-uri":    `{"csp-report":{"violated-directive":"script-src","blocked-uri":"https://evil.example"}}`,
+		"Reporting API": `[{"type":"csp-violation","body":{"effectiveDirective":"script-src","blockedURL":"inline"}}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/csp-report", strings.NewReader(body))
+			middleware.CSPReport(rec, req)
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("Status = %d, want %d", rec.Code, http.StatusNoContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package preview_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"html2go-converter/preview"
+)
+
+// TestSanitize 测试移除脚本、事件处理器和脚本URL，保留样式与普通标记
+func TestSanitize(t *testing.T) {
+	input := `<html><head><style>.a{color:red}</style><script>alert(1)</script><meta http-equiv="refresh" content="0;url=/"></head>
+<body><div class="a" onclick="alert(2)">hi<script>alert(3)</script></div>
+<a href=" java&#x09;script:alert(4)">x</a><a href="/ok">y</a>
+<img src="data:image/png;base64,AAAA" onerror="alert(5)"><iframe src="/"></iframe>
+<svg><script>alert(6)</script><a href="data:text/html,x">z</a></svg>
+<p title="data: not a URL">t</p><!-- comment --></body></html>`
+
+	body, removed, err := preview.Sanitize(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{"alert", "<iframe", "<meta", "comment", "javascript", "data:text"} {
+		if strings.Contains(body, bad) {
+			t.Errorf("Sanitized body contains %q:\n%s", bad, body)
+		}
+	}
+	for _, good := range []string{"<style>.a{color:red}</style>", `<div class="a">hi</div>`, `href="/ok"`, `src="data:image/png;base64,AAAA"`, `title="data: not a URL"`} {
+		if !strings.Contains(body, good) {
+			t.Errorf("Sanitized body is missing %q:\n%s", good, body)
+		}
+	}
+	want := []string{"<iframe>", "<meta>", "<script>", "href", "onclick", "onerror"}
+	if !slices.Equal(removed, want) {
+		t.Errorf("Removed = %q, want %q", removed, want)
+	}
+}
+
+// TestRender 测试生成的文档只引用可用的样式表
+func TestRender(t *testing.T) {
+	assets := fstest.MapFS{"tailwind.css": {Data: []byte(".p-4{padding:1rem}")}}
+	if got := preview.Available(assets); !slices.Equal(got, []string{"tailwind"}) {
+		t.Errorf("Available = %q, want [tailwind]", got)
+	}
+
+	doc, _, err := preview.Render(assets, `<div class="p-4">x</div>`, []string{"tailwind"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(doc, "<!DOCTYPE html>") || !strings.Contains(doc, `href="/preview/assets/tailwind.css"`) || !strings.Contains(doc, `<div class="p-4">x</div>`) {
+		t.Errorf("Render = %s", doc)
+	}
+
+	for _, name := range []string{"vuetify", "../tailwind"} {
+		if _, _, err := preview.Render(assets, "<p>x</p>", []string{name}); !errors.Is(err, preview.ErrUnknownStylesheet) {
+			t.Errorf("Render with %q: err = %v, want ErrUnknownStylesheet", name, err)
+		}
+	}
+}
+
+// TestStore 测试预览过期与数量上限
+func TestStore(t *testing.T) {
+	now := time.Now()
+	store := preview.NewStore(2, time.Minute)
+
+	first := store.Put("1", now)
+	if !preview.ValidToken(first.Token) {
+		t.Errorf("Token %q is not valid", first.Token)
+	}
+	if p, err := store.Get(first.Token, now); err != nil || p.Document != "1" {
+		t.Errorf("Get = %+v, %v", p, err)
+	}
+	if _, err := store.Get(first.Token, now.Add(time.Minute)); !errors.Is(err, preview.ErrNotFound) {
+		t.Errorf("Get expired: err = %v, want ErrNotFound", err)
+	}
+
+	store.Put("2", now)
+	third := store.Put("3", now)
+	if _, err := store.Get(first.Token, now); !errors.Is(err, preview.ErrNotFound) {
+		t.Errorf("Oldest preview was not evicted: err = %v", err)
+	}
+	if _, err := store.Get(third.Token, now); err != nil {
+		t.Errorf("Get newest: %v", err)
+	}
+
+	store.Put("4", now.Add(2*time.Minute))
+	if n := store.Len(); n != 1 {
+		t.Errorf("Len after expiry = %d, want 1", n)
+	}
+}
@@ -0,0 +1,69 @@
+package routes_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"html2go-converter/routes"
+)
+
+// TestVercelJSONInSync 测试vercel.json与Go路由表保持一致
+func TestVercelJSONInSync(t *testing.T) {
+	want, err := routes.VercelJSON()
+	if err != nil {
+		t.Fatalf("VercelJSON error: %v", err)
+	}
+	got, err := os.ReadFile("../../vercel.json")
+	if err != nil {
+		t.Fatalf("Failed to read vercel.json: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("vercel.json is out of date, run `go run ./cmd/vercelgen`.\nwant:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+// TestVercelDestinationsExist 测试路由目标文件存在
+func TestVercelDestinationsExist(t *testing.T) {
+	for _, route := range routes.Vercel().Routes {
+		if strings.Contains(route.Dest, "$") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join("../..", route.Dest)); err != nil {
+			t.Errorf("Route %s points to missing %s", route.Src, route.Dest)
+		}
+	}
+}
+
+// TestLocalRoutes 测试本地服务器注册了前端使用的路径
+func TestLocalRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	routes.Register(mux)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{method: http.MethodPost, path: "/api/convert", body: `{"html":"<div>hi</div>","direction":"html2go"}`, status: http.StatusOK},
+		{method: http.MethodPost, path: "/convert", body: `{"html":"<div>hi</div>","direction":"html2go"}`, status: http.StatusOK},
+		{method: http.MethodGet, path: "/", status: http.StatusOK},
+		{method: http.MethodGet, path: "/static/script.js", status: http.StatusOK},
+		{method: http.MethodGet, path: "/analytics.js", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.status {
+				t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, rec.Code, tt.status)
+			}
+		})
+	}
+}
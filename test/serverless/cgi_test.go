@@ -0,0 +1,60 @@
+package serverless_test
+
+import (
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"html2go-converter/middleware"
+	"html2go-converter/routes"
+	"html2go-converter/serverless"
+)
+
+// TestCGIHelperProcess 不是真正的测试：它在被CGI处理器启动时作为CGI脚本运行
+func TestCGIHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_CGI_HELPER") != "1" {
+		return
+	}
+	if err := serverless.ServeCGI(routes.NewHandler(middleware.DefaultSecurityOptions)); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// TestServeCGI 测试应用作为CGI脚本运行
+func TestServeCGI(t *testing.T) {
+	h := &cgi.Handler{
+		Path: os.Args[0],
+		Args: []string{"-test.run=^TestCGIHelperProcess$"},
+		Env:  []string{"GO_WANT_CGI_HELPER=1"},
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   string
+	}{
+		{method: http.MethodPost, path: "/api/convert", body: `{"html":"<div>hi</div>","direction":"html2go"}`, want: `"code":"Div(`},
+		{method: http.MethodGet, path: "/", want: "<html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Status = %d, body: %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("Body does not contain %q: %.200s", tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package serverless_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/middleware"
+	"html2go-converter/routes"
+	"html2go-converter/serverless"
+)
+
+// FastCGI记录类型
+const (
+	fcgiBeginRequest = 1
+	fcgiEndRequest   = 3
+	fcgiParams       = 4
+	fcgiStdin        = 5
+	fcgiStdout       = 6
+)
+
+// writeRecord 写入一条FastCGI记录
+func writeRecord(w io.Writer, recType byte, content []byte) error {
+	header := []byte{1, recType, 0, 1, byte(len(content) >> 8), byte(len(content)), 0, 0}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(content)
+	return err
+}
+
+// encodeParams 按FastCGI名值对格式编码参数
+func encodeParams(params map[string]string) []byte {
+	var buf bytes.Buffer
+	writeLen := func(n int) {
+		if n < 128 {
+			buf.WriteByte(byte(n))
+			return
+		}
+		binary.Write(&buf, binary.BigEndian, uint32(n)|1<<31)
+	}
+	for name, value := range params {
+		writeLen(len(name))
+		writeLen(len(value))
+		buf.WriteString(name)
+		buf.WriteString(value)
+	}
+	return buf.Bytes()
+}
+
+// fcgiRequest 作为Web服务器发送一个FastCGI请求并返回CGI格式的输出
+func fcgiRequest(t *testing.T, addr string, params map[string]string, body string) string {
+	t.Helper()
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	writeRecord(conn, fcgiBeginRequest, []byte{0, 1, 0, 0, 0, 0, 0, 0})
+	writeRecord(conn, fcgiParams, encodeParams(params))
+	writeRecord(conn, fcgiParams, nil)
+	if body != "" {
+		writeRecord(conn, fcgiStdin, []byte(body))
+	}
+	writeRecord(conn, fcgiStdin, nil)
+
+	var stdout bytes.Buffer
+	r := bufio.NewReader(conn)
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(r, header); err != nil {
+			t.Fatalf("Read record header failed: %v", err)
+		}
+		length := int(binary.BigEndian.Uint16(header[4:6]))
+		content := make([]byte, length+int(header[6]))
+		if _, err := io.ReadFull(r, content); err != nil {
+			t.Fatalf("Read record content failed: %v", err)
+		}
+		switch header[1] {
+		case fcgiStdout:
+			stdout.Write(content[:length])
+		case fcgiEndRequest:
+			return stdout.String()
+		}
+	}
+}
+
+// TestServeFastCGI 测试应用作为FastCGI应用运行
+func TestServeFastCGI(t *testing.T) {
+	// 先占用一个空闲端口，再交给FastCGI服务器监听
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	go serverless.ServeFastCGI(routes.NewHandler(middleware.DefaultSecurityOptions), addr)
+	for i := 0; i < 50; i++ {
+		if c, err := net.Dial("tcp", addr); err == nil {
+			c.Close()
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	body := `{"html":"<div>hi</div>","direction":"html2go"}`
+	out := fcgiRequest(t, addr, map[string]string{
+		"REQUEST_METHOD":  "POST",
+		"REQUEST_URI":     "/api/convert",
+		"SCRIPT_NAME":     "",
+		"SERVER_PROTOCOL": "HTTP/1.1",
+		"HTTP_HOST":       "localhost",
+		"CONTENT_TYPE":    "application/json",
+		"CONTENT_LENGTH":  strconv.Itoa(len(body)),
+	}, body)
+
+	if !strings.HasPrefix(out, "Status: 200") {
+		t.Fatalf("Unexpected FastCGI output: %.200q", out)
+	}
+	if !strings.Contains(out, `"code":"Div(`) {
+		t.Errorf("Response does not contain converted code: %.300q", out)
+	}
+}
+
+// TestServeFastCGISocketPath 测试只替换旧的Unix套接字，不删除其他文件
+func TestServeFastCGISocketPath(t *testing.T) {
+	// Unix套接字路径有长度限制，不使用t.TempDir
+	dir, err := os.MkdirTemp("", "fcgi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	handler := routes.NewHandler(middleware.DefaultSecurityOptions)
+
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, []byte("keep"), 0o644)
+	if err := serverless.ServeFastCGI(handler, file); err == nil {
+		t.Error("ServeFastCGI on a regular file succeeded")
+	}
+	if data, _ := os.ReadFile(file); string(data) != "keep" {
+		t.Error("ServeFastCGI removed a regular file")
+	}
+
+	// 进程退出后留下的套接字
+	sock := filepath.Join(dir, "sock")
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: sock, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+
+	errs := make(chan error, 1)
+	go func() { errs <- serverless.ServeFastCGI(handler, sock) }()
+	for i := 0; i < 50; i++ {
+		if c, err := net.Dial("unix", sock); err == nil {
+			c.Close()
+			return
+		}
+		select {
+		case err := <-errs:
+			t.Fatalf("ServeFastCGI on a stale socket: %v", err)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	t.Error("ServeFastCGI did not listen on the stale socket path")
+}
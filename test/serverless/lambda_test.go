@@ -0,0 +1,127 @@
+package serverless_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"html2go-converter/middleware"
+	"html2go-converter/routes"
+	"html2go-converter/serverless"
+)
+
+// lambdaEvent 构造一个API Gateway v2格式的事件
+func lambdaEvent(t *testing.T, raw string) serverless.LambdaRequest {
+	t.Helper()
+	var event serverless.LambdaRequest
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		t.Fatalf("Invalid test event: %v", err)
+	}
+	return event
+}
+
+// TestHandleLambdaEvent 测试函数URL事件与HTTP请求/响应之间的转换
+func TestHandleLambdaEvent(t *testing.T) {
+	app := routes.NewHandler(middleware.DefaultSecurityOptions)
+
+	t.Run("Convert API", func(t *testing.T) {
+		event := lambdaEvent(t, `{
+			"version": "2.0",
+			"rawPath": "/api/convert",
+			"headers": {"content-type": "application/json", "host": "abc.lambda-url.us-east-1.on.aws"},
+			"body": "{\"html\":\"<div>hi</div>\",\"direction\":\"html2go\"}",
+			"isBase64Encoded": false,
+			"requestContext": {"http": {"method": "POST", "path": "/api/convert", "sourceIp": "1.2.3.4"}}
+		}`)
+		resp, err := serverless.HandleLambdaEvent(context.Background(), app, event)
+		if err != nil {
+			t.Fatalf("HandleLambdaEvent error: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || resp.IsBase64Encoded {
+			t.Fatalf("Unexpected response: %+v", resp)
+		}
+		if !strings.Contains(resp.Body, `"code":"Div(`) {
+			t.Errorf("Unexpected body: %s", resp.Body)
+		}
+	})
+
+	t.Run("Base64 request and compressed response", func(t *testing.T) {
+		body := base64.StdEncoding.EncodeToString([]byte(`{"html":"<p>x</p>","direction":"html2go"}`))
+		event := lambdaEvent(t, `{
+			"rawPath": "/convert",
+			"headers": {"content-type": "application/json"},
+			"body": "`+body+`",
+			"isBase64Encoded": true,
+			"requestContext": {"http": {"method": "POST"}}
+		}`)
+		resp, err := serverless.HandleLambdaEvent(context.Background(), app, event)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("Unexpected response: %+v, %v", resp, err)
+		}
+
+		event = lambdaEvent(t, `{
+			"rawPath": "/static/script.js",
+			"headers": {"accept-encoding": "gzip"},
+			"requestContext": {"http": {"method": "GET"}}
+		}`)
+		resp, err = serverless.HandleLambdaEvent(context.Background(), app, event)
+		if err != nil {
+			t.Fatalf("HandleLambdaEvent error: %v", err)
+		}
+		if !resp.IsBase64Encoded || resp.Headers["Content-Encoding"] != "gzip" {
+			t.Errorf("Expected a base64 gzip body, got encoding %q base64 %v", resp.Headers["Content-Encoding"], resp.IsBase64Encoded)
+		}
+	})
+}
+
+// TestRunLambda 测试通过模拟的Lambda Runtime API处理一次调用
+func TestRunLambda(t *testing.T) {
+	responses := make(chan serverless.LambdaResponse, 1)
+	delivered := false
+
+	runtime := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/2018-06-01/runtime/invocation/next" && !delivered:
+			delivered = true
+			w.Header().Set("Lambda-Runtime-Aws-Request-Id", "req-1")
+			w.Header().Set("Lambda-Runtime-Deadline-Ms", "4102444800000")
+			io.WriteString(w, `{"rawPath":"/","requestContext":{"http":{"method":"GET"}}}`)
+		case r.URL.Path == "/2018-06-01/runtime/invocation/next":
+			<-r.Context().Done() // 没有更多调用
+		case r.URL.Path == "/2018-06-01/runtime/invocation/req-1/response":
+			var resp serverless.LambdaResponse
+			json.NewDecoder(r.Body).Decode(&resp)
+			w.WriteHeader(http.StatusAccepted)
+			responses <- resp
+		default:
+			t.Errorf("Unexpected runtime API call: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer runtime.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serverless.RunLambda(ctx, strings.TrimPrefix(runtime.URL, "http://"), routes.NewHandler(middleware.DefaultSecurityOptions))
+	}()
+
+	select {
+	case resp := <-responses:
+		if resp.StatusCode != http.StatusOK || !strings.Contains(resp.Body, "<html") {
+			t.Errorf("Unexpected invocation response: %d %.60q", resp.StatusCode, resp.Body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the invocation response")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("RunLambda returned %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,139 @@
+package snippets_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"html2go-converter/converter"
+	"html2go-converter/snippets"
+)
+
+// TestStores 测试内存与BoltDB存储的增删查和过期清理
+func TestStores(t *testing.T) {
+	bolt, err := snippets.OpenBoltStore(filepath.Join(t.TempDir(), "snippets.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bolt.Close()
+
+	for name, store := range map[string]snippets.Store{"memory": snippets.NewMemoryStore(), "bolt": bolt} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now()
+			req := converter.Request{HTML: "<p>a</p>", PackagePrefix: "h"}
+			kept, _ := snippets.New(req, "h.P()", 0, now)
+			expiring, _ := snippets.New(req, "h.P()", time.Hour, now)
+			for _, s := range []snippets.Snippet{kept, expiring} {
+				if err := store.Create(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := store.Create(kept); !errors.Is(err, snippets.ErrExists) {
+				t.Errorf("Create duplicate error = %v, want ErrExists", err)
+			}
+
+			got, err := store.Get(kept.ID)
+			if err != nil || got.HTML != req.HTML || got.Code != "h.P()" || got.ExpiresAt != nil {
+				t.Errorf("Get = %+v, %v", got, err)
+			}
+
+			if n, err := store.Sweep(now.Add(2 * time.Hour)); n != 1 || err != nil {
+				t.Errorf("Sweep = %d, %v, want 1", n, err)
+			}
+			if _, err := store.Get(expiring.ID); !errors.Is(err, snippets.ErrNotFound) {
+				t.Errorf("Get swept snippet error = %v, want ErrNotFound", err)
+			}
+
+			if err := store.Delete(kept.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Get(kept.ID); !errors.Is(err, snippets.ErrNotFound) {
+				t.Errorf("Get deleted snippet error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+// TestBoltStoreReopen 测试BoltDB存储在重新打开后保留代码片段
+func TestBoltStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snippets.db")
+	store, err := snippets.OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := snippets.New(converter.Request{HTML: "<p>kept</p>"}, "code", time.Hour, time.Now())
+	store.Create(s)
+	store.Close()
+
+	store, err = snippets.OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if got, err := store.Get(s.ID); err != nil || got.HTML != "<p>kept</p>" {
+		t.Errorf("Get after reopen = %+v, %v", got, err)
+	}
+}
+
+// TestNew 测试新代码片段的ID、删除令牌与过期时间
+func TestNew(t *testing.T) {
+	now := time.Now()
+	s, token := snippets.New(converter.Request{HTML: "<p>a</p>"}, "code", time.Hour, now)
+	if !snippets.ValidID(s.ID) {
+		t.Errorf("ID %q is not valid", s.ID)
+	}
+	if !s.CheckToken(token) || s.CheckToken("wrong") || s.CheckToken("") {
+		t.Error("CheckToken accepts the wrong tokens")
+	}
+	if s.TokenHash == token {
+		t.Error("Token is stored in clear")
+	}
+	if s.ExpiresAt == nil || !s.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("ExpiresAt = %v, want %v", s.ExpiresAt, now.Add(time.Hour))
+	}
+	if s.Expired(now) || !s.Expired(now.Add(time.Hour)) {
+		t.Error("Expired does not honour ExpiresAt")
+	}
+
+	other, _ := snippets.New(converter.Request{}, "", 0, now)
+	if other.ID == s.ID {
+		t.Errorf("Two snippets got ID %q", s.ID)
+	}
+	for _, id := range []string{"", "abc", "../../etc", "abcdefgh0"} {
+		if snippets.ValidID(id) {
+			t.Errorf("ValidID(%q) = true", id)
+		}
+	}
+}
+
+// TestPolicy 测试有效期选项与最长有效期
+func TestPolicy(t *testing.T) {
+	tests := []struct {
+		policy  snippets.Policy
+		expiry  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{snippets.DefaultPolicy, "", 7 * 24 * time.Hour, false},
+		{snippets.DefaultPolicy, "1h", time.Hour, false},
+		{snippets.DefaultPolicy, "never", 0, false},
+		{snippets.DefaultPolicy, "1y", 0, true},
+		{snippets.Policy{DefaultExpiry: "1d", MaxTTL: 24 * time.Hour}, "", 24 * time.Hour, false},
+		{snippets.Policy{DefaultExpiry: "1d", MaxTTL: 24 * time.Hour}, "30d", 0, true},
+		{snippets.Policy{DefaultExpiry: "1d", MaxTTL: 24 * time.Hour}, "never", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := tt.policy.TTL(tt.expiry)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%+v.TTL(%q) = %v, %v, want %v", tt.policy, tt.expiry, got, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, snippets.ErrInvalidExpiry) {
+			t.Errorf("TTL(%q) error = %v, want ErrInvalidExpiry", tt.expiry, err)
+		}
+	}
+
+	if names := snippets.ExpiryNames(); names[0] != "1h" || names[len(names)-1] != "never" {
+		t.Errorf("ExpiryNames = %v", names)
+	}
+}
@@ -0,0 +1,25 @@
+// Package web embeds the web UI assets of the public directory into the
+// binary, so the server can run from any working directory. The embedding
+// lives outside public so that no Go source is published with the assets.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:public
+var public embed.FS
+
+// FS contains the static files of the web UI, every file of the public
+// directory whatever its type.
+var FS = mustSub(public, "public")
+
+// mustSub returns the subtree of fsys at dir, which go:embed guarantees
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
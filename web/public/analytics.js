@@ -4,8 +4,17 @@ import { inject } from 'https://esm.sh/@vercel/analytics';
 // Inject Vercel Analytics
 inject();
 
+// Runtime configuration rendered into index.html by the server
+const appConfig = (function () {
+  try {
+    return JSON.parse(document.getElementById('app-config').textContent) || {};
+  } catch (e) {
+    return {};
+  }
+})();
+
 // App version
-const APP_VERSION = '1.0.0';
+const APP_VERSION = appConfig.version || '1.0.0';
 
 // Determine the current environment
 const determineEnvironment = function () {
@@ -16,23 +25,8 @@ const determineEnvironment = function () {
     return envParam;
   }
 
-  const hostname = window.location.hostname;
-  const port = window.location.port;
-
-  // Check if URL contains development indicators
-  const isDev = hostname === 'localhost' ||
-    hostname === '127.0.0.1' ||
-    hostname.startsWith('192.168.') ||
-    hostname.startsWith('10.') ||
-    port === '3000' ||
-    port === '5000' ||
-    port === '8000' ||
-    port === '8080' ||
-    hostname.includes('dev.') ||
-    hostname.includes('-dev') ||
-    (hostname.includes('vercel.app') && (hostname.includes('preview') || hostname.includes('dev-')));
-
-  return isDev ? 'local' : 'prod';
+  // Use the environment configured on the server (APP_ENV)
+  return appConfig.environment === 'prod' || appConfig.environment === 'production' ? 'prod' : 'local';
 };
 
 // Get the current environment
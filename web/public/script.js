@@ -0,0 +1,1098 @@
+// 初始化Monaco编辑器
+require.config({ paths: { vs: 'https://cdnjs.cloudflare.com/ajax/libs/monaco-editor/0.36.1/min/vs' } });
+
+// 编辑器实例
+let htmlEditor, goEditor;
+
+// 读取服务端注入的运行时配置
+const appConfig = (function () {
+  try {
+    return JSON.parse(document.getElementById('app-config').textContent) || {};
+  } catch (e) {
+    console.warn('无法读取运行时配置，使用默认值', e);
+    return {};
+  }
+})();
+const appDefaults = appConfig.defaults || {};
+
+// 读取服务端注入的分享代码片段（通过 /s/{id} 打开时存在）
+const snippetData = (function () {
+  try {
+    return JSON.parse(document.getElementById('snippet-data').textContent) || {};
+  } catch (e) {
+    return {};
+  }
+})();
+const sharedSnippet = snippetData.snippet || null;
+
+// 转换选项，分享的代码片段优先于默认值
+let packagePrefix = (sharedSnippet && sharedSnippet.packagePrefix) || appDefaults.packagePrefix || "h"; // 默认包前缀
+let vuetifyPrefix = (sharedSnippet && sharedSnippet.vuetifyPrefix) || appDefaults.vuetifyPrefix || "v"; // 默认Vuetify包前缀
+let vuetifyXPrefix = (sharedSnippet && sharedSnippet.vuetifyXPrefix) || appDefaults.vuetifyXPrefix || "vx"; // 默认VuetifyX包前缀
+let isUpdating = false; // 防止无限循环更新的标志
+
+// 定义One Dark Pro主题
+const oneDarkPro = {
+  base: 'vs-dark',
+  inherit: true,
+  rules: [
+    { token: 'comment', foreground: '5c6370', fontStyle: 'italic' },
+    { token: 'keyword', foreground: 'c678dd' },
+    { token: 'string', foreground: '98c379' },
+    { token: 'number', foreground: 'd19a66' },
+    { token: 'type', foreground: '61afef' },
+    { token: 'function', foreground: '61afef' },
+    { token: 'variable', foreground: 'e06c75' },
+    { token: 'constant', foreground: 'd19a66' },
+    { token: 'error', foreground: 'e06c75', fontStyle: 'bold underline' },
+  ],
+  colors: {
+    'editor.background': '#282c34',
+    'editor.foreground': '#abb2bf',
+    'editor.lineHighlightBackground': '#2c313c',
+    'editorCursor.foreground': '#528bff',
+    'editorWhitespace.foreground': '#3b4048',
+    'editorIndentGuide.background': '#3b4048',
+    'editor.selectionBackground': '#3e4451',
+    'editor.inactiveSelectionBackground': '#3e4451',
+    'editorError.foreground': '#e06c75',
+    'editorWarning.foreground': '#d19a66',
+    'editorInfo.foreground': '#61afef',
+  },
+};
+
+// 初始化编辑器
+require(['vs/editor/editor.main'], function () {
+  // 注册One Dark Pro主题
+  monaco.editor.defineTheme('oneDarkPro', oneDarkPro);
+
+  // 安全地禁用HTML和JavaScript语法校验
+  try {
+    if (monaco.languages.html && monaco.languages.html.htmlDefaults &&
+      typeof monaco.languages.html.htmlDefaults.setDiagnosticsOptions === 'function') {
+      monaco.languages.html.htmlDefaults.setDiagnosticsOptions({
+        validate: false
+      });
+    }
+  } catch (e) {
+    console.warn('无法配置HTML语言校验', e);
+  }
+
+  try {
+    if (monaco.languages.typescript && monaco.languages.typescript.javascriptDefaults &&
+      typeof monaco.languages.typescript.javascriptDefaults.setDiagnosticsOptions === 'function') {
+      monaco.languages.typescript.javascriptDefaults.setDiagnosticsOptions({
+        noSemanticValidation: true,
+        noSyntaxValidation: true
+      });
+    }
+  } catch (e) {
+    console.warn('无法配置JavaScript语言校验', e);
+  }
+
+  // 尝试禁用Go语言的语法校验（如果Monaco支持）
+  try {
+    if (monaco.languages.go && monaco.languages.go.goDefaults &&
+      typeof monaco.languages.go.goDefaults.setDiagnosticsOptions === 'function') {
+      monaco.languages.go.goDefaults.setDiagnosticsOptions({
+        validate: false,
+        noSemanticValidation: true,
+        noSyntaxValidation: true
+      });
+    }
+  } catch (e) {
+    console.warn('无法配置Go语言校验', e);
+  }
+
+  // 创建HTML编辑器
+  htmlEditor = monaco.editor.create(document.getElementById('leftEditor'), {
+    value: sharedSnippet ? sharedSnippet.html : '<div class="container">\n  <h1 class="text-xl font-bold">Hello World</h1>\n  <p class="text-gray-600">这是一个示例</p>\n</div>',
+    language: 'html',
+    theme: 'vs-light',
+    minimap: { enabled: false },
+    automaticLayout: true,
+    fontSize: 14,
+    lineHeight: 21,
+    padding: { top: 16, bottom: 16 },
+    formatOnPaste: true,
+    formatOnType: true,
+    // 禁用语法校验
+    validate: false
+  });
+
+  // 跟踪HTML编辑器创建
+  // if (window.Analytics) {
+  //   window.Analytics.trackEditorCreated('html');
+  // }
+
+  // 创建Go编辑器
+  createGoEditor();
+
+  // 配置编辑器的语法校验
+  configureEditorValidation();
+
+  // 设置编辑器的事件监听器
+  setupEditorListeners();
+
+  // 初始化包前缀输入框
+  const pkgInput = document.getElementById('packagePrefix');
+  const vuetifyInput = document.getElementById('vuetifyPrefix');
+  const vuetifyXInput = document.getElementById('vuetifyXPrefix');
+
+  if (pkgInput) {
+    pkgInput.value = packagePrefix;
+    pkgInput.addEventListener('change', function () {
+      packagePrefix = this.value;
+      if (htmlEditor.getValue().trim() !== '') {
+        htmlToGoConversion();
+      }
+    });
+  }
+
+  if (vuetifyInput) {
+    vuetifyInput.value = vuetifyPrefix;
+    vuetifyInput.addEventListener('change', function () {
+      vuetifyPrefix = this.value;
+      if (htmlEditor.getValue().trim() !== '') {
+        htmlToGoConversion();
+      }
+    });
+  }
+
+  if (vuetifyXInput) {
+    vuetifyXInput.value = vuetifyXPrefix;
+    vuetifyXInput.addEventListener('change', function () {
+      vuetifyXPrefix = this.value;
+      if (htmlEditor.getValue().trim() !== '') {
+        htmlToGoConversion();
+      }
+    });
+  }
+
+  // 清除所有编辑器标记
+  if (htmlEditor && htmlEditor.getModel()) {
+    monaco.editor.setModelMarkers(htmlEditor.getModel(), 'html', []);
+  }
+  if (goEditor && goEditor.getModel()) {
+    monaco.editor.setModelMarkers(goEditor.getModel(), 'go', []);
+  }
+
+  // 设置分享链接
+  setupSharing();
+
+  // 加载转换历史
+  conversionHistory.setup();
+
+  // 加载示例库
+  exampleGallery.setup();
+
+  // 设置实时预览
+  livePreview.setup();
+
+  // 初始转换，分享的代码片段直接显示保存的结果
+  if (sharedSnippet) {
+    goEditor.setValue(sharedSnippet.code || '');
+  } else {
+    htmlToGoConversion();
+  }
+
+  // 测试前缀设置是否正确
+  testPrefixes();
+});
+
+// 创建Go编辑器的函数
+function createGoEditor() {
+  // 如果已经存在编辑器，先销毁它
+  if (goEditor) {
+    goEditor.dispose();
+  }
+
+  // 创建新的Go编辑器
+  goEditor = monaco.editor.create(document.getElementById('rightEditor'), {
+    value: '// Go代码将在这里显示',
+    language: 'go',
+    theme: 'oneDarkPro',
+    automaticLayout: true,
+    formatOnPaste: true,
+    formatOnType: true,
+    wordWrap: 'on',
+    lineNumbers: 'on',
+    renderWhitespace: 'selection',
+    scrollBeyondLastLine: false,
+    fontSize: 14,
+    lineHeight: 21,
+    tabSize: 2,
+    padding: { top: 16, bottom: 16 },
+    renderIndentGuides: true,
+    bracketPairColorization: { enabled: true },
+    guides: {
+      bracketPairs: true,
+      indentation: true,
+    },
+    readOnly: false, // 确保编辑器不是只读的
+    domReadOnly: false, // 确保DOM元素不是只读的
+    // 禁用语法校验
+    validate: false
+  });
+
+  // 跟踪Go编辑器创建
+  // if (window.Analytics) {
+  //   window.Analytics.trackEditorCreated('go');
+  // }
+
+  console.log("Go编辑器已重新创建");
+
+  // 添加点击事件，确保编辑器可以获得焦点
+  document.getElementById('rightEditor').addEventListener('click', function () {
+    if (goEditor) {
+      goEditor.focus();
+      console.log("Go编辑器已获得焦点");
+    }
+  });
+}
+
+// 配置编辑器语法校验
+function configureEditorValidation() {
+  // 禁用所有语法校验
+  const htmlModel = htmlEditor.getModel();
+  if (htmlModel) {
+    monaco.editor.setModelMarkers(htmlModel, 'html', []);
+    // validateHTML(htmlModel); // 已禁用
+  }
+
+  // Go校验
+  const goModel = goEditor.getModel();
+  if (goModel) {
+    monaco.editor.setModelMarkers(goModel, 'go', []);
+    // validateGo(goModel); // 已禁用
+  }
+
+  // 禁用编辑器内容变化监听器的语法校验
+  // htmlEditor.onDidChangeModelContent(debounce(function () {
+  //   const model = htmlEditor.getModel();
+  //   if (model) {
+  //     validateHTML(model);
+  //   }
+  // }, 300));
+
+  // goEditor.onDidChangeModelContent(debounce(function () {
+  //   const model = goEditor.getModel();
+  //   if (model) {
+  //     validateGo(model);
+  //   }
+  // }, 300));
+}
+
+// HTML语法校验 - 已禁用
+function validateHTML(model) {
+  // 函数保留但不执行任何校验
+  return;
+
+  // 以下代码已被禁用
+  // const content = model.getValue();
+  // const markers = [];
+  // ...
+}
+
+// Go语法校验 - 已禁用
+function validateGo(model) {
+  // 函数保留但不执行任何校验
+  return;
+
+  // 以下代码已被禁用
+  // const content = model.getValue();
+  // const markers = [];
+  // ...
+}
+
+// 防抖函数
+function debounce(func, wait) {
+  let timeout;
+  return function () {
+    const context = this;
+    const args = arguments;
+    clearTimeout(timeout);
+    timeout = setTimeout(() => func.apply(context, args), wait);
+  };
+}
+
+// 获取API URL，基础地址由服务端配置提供，默认与页面同源
+function getApiUrl(endpoint) {
+  return `${appConfig.apiBaseUrl || ''}${endpoint}`;
+}
+
+// HTML到Go转换函数
+async function htmlToGoConversion() {
+  if (isUpdating) return;
+  if (liveConversion.send()) return;
+  isUpdating = true;
+
+  try {
+    // 获取HTML内容
+    const htmlInput = htmlEditor.getValue();
+    console.log("HTML输入内容:", htmlInput);
+
+    if (!htmlInput.trim()) {
+      goEditor.setValue('// 请在左侧输入HTML代码');
+      isUpdating = false;
+      return;
+    }
+
+    // 准备请求体
+    const requestBody = {
+      html: htmlInput,
+      packagePrefix: packagePrefix,
+      vuetifyPrefix: vuetifyPrefix,
+      vuetifyXPrefix: vuetifyXPrefix,
+      direction: "html2go"
+    };
+
+    console.log("发送转换请求:", JSON.stringify(requestBody));
+
+    // 发送转换请求，使用getApiUrl获取正确的URL
+    const response = await fetch(getApiUrl('/api/v1/convert'), {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(requestBody),
+    });
+
+    console.log("收到响应状态:", response.status);
+
+    if (!response.ok) {
+      // 尝试解析错误响应
+      let errorData;
+      try {
+        errorData = await response.json();
+        console.log("错误响应数据:", errorData);
+      } catch (e) {
+        const errorText = await response.text();
+        console.log("错误响应文本:", errorText);
+        throw new Error(errorText);
+      }
+
+      if (errorData && errorData.error) {
+        throw new Error(errorData.error);
+      } else {
+        throw new Error('转换失败: 未知错误');
+      }
+    }
+
+    const data = await response.json();
+    console.log("转换成功，响应数据:", data);
+
+    // 更新Go编辑器
+    goEditor.setValue(data.code || '// 转换失败');
+
+    // 保存为转换历史的新版本
+    conversionHistory.save(requestBody);
+  } catch (error) {
+    console.error('HTML到Go转换错误:', error);
+    goEditor.setValue(`// 转换错误: ${error.message}`);
+  } finally {
+    isUpdating = false;
+  }
+}
+
+// Go到HTML的转换
+function goToHtmlConversion() {
+  if (isUpdating) {
+    return;
+  }
+
+  isUpdating = true;
+  console.log("开始Go到HTML转换");
+  const goCode = goEditor.getValue();
+  console.log("获取到Go代码:", goCode);
+
+  if (!goCode || !goCode.trim()) {
+    console.log("Go代码为空，不执行转换");
+    htmlEditor.setValue('');
+    isUpdating = false;
+    return;
+  }
+
+  // 检查Go代码是否包含变量n的定义
+  let processedGoCode = goCode;
+
+  // 检查直接if表达式错误 (最常见的错误模式)
+  if (goCode.match(/var\s+n\s*=\s*if/) || goCode.match(/n\s*:=\s*if/) ||
+    goCode.includes('n = if') || goCode.includes('= if true') ||
+    goCode.includes('= if false')) {
+    // 直接if条件表达式错误
+    htmlEditor.setValue(`<!-- 编译或执行错误: syntax error: unexpected if, expected expression -->
+<!-- 请尝试以下正确写法: -->
+<!--
+    // 方法1: 使用函数
+    var n = htmlgo.Div().Text(func() string {
+        if condition {
+            return "真"
+        }
+        return "假"
+    }())
+    
+    // 方法2: 使用map模拟三元运算符
+    condition := true
+    var n = htmlgo.Div().Text(map[bool]string{true: "真", false: "假"}[condition])
+-->
+`);
+    isUpdating = false;
+    return;
+  }
+
+  // 检查常见语法错误
+  if (goCode.includes('if') &&
+    (goCode.includes('if {') ||
+      goCode.includes('if true {') ||
+      goCode.includes('if false {') ||
+      goCode.match(/var\s+n\s*=\s*if/))) {
+    // 条件语句语法错误
+    htmlEditor.setValue(`<!-- 警告: Go代码中存在条件语句语法错误，请检查您的代码 -->`);
+    isUpdating = false;
+    return;
+  }
+
+  // 检查括号匹配
+  const openBraces = (goCode.match(/\{/g) || []).length;
+  const closeBraces = (goCode.match(/\}/g) || []).length;
+  if (openBraces !== closeBraces) {
+    htmlEditor.setValue(`<!-- 警告: Go代码中的花括号不匹配，开括号: ${openBraces}，闭括号: ${closeBraces} -->`);
+    isUpdating = false;
+    return;
+  }
+
+  const openParens = (goCode.match(/\(/g) || []).length;
+  const closeParens = (goCode.match(/\)/g) || []).length;
+  if (openParens !== closeParens) {
+    htmlEditor.setValue(`<!-- 警告: Go代码中的圆括号不匹配，开括号: ${openParens}，闭括号: ${closeParens} -->`);
+    isUpdating = false;
+    return;
+  }
+
+  if (!goCode.includes('var n =') && !goCode.includes('n :=')) {
+    // 尝试提取主要表达式
+    const lines = goCode.split('\n');
+    let mainExpr = '';
+
+    // 找到第一个非空且非注释的行
+    for (const line of lines) {
+      const trimmedLine = line.trim();
+      if (trimmedLine && !trimmedLine.startsWith('//')) {
+        mainExpr = trimmedLine;
+        break;
+      }
+    }
+
+    if (mainExpr) {
+      // 将主要表达式包装在变量定义中
+      processedGoCode = `var n = ${mainExpr}`;
+      console.log("处理后的Go代码:", processedGoCode);
+    }
+  }
+
+  const requestBody = {
+    goCode: processedGoCode,
+    direction: "go2html"
+  };
+
+  console.log("发送请求:", JSON.stringify(requestBody));
+
+  fetch(getApiUrl('/api/convert'), {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json'
+    },
+    body: JSON.stringify(requestBody)
+  })
+    .then(response => {
+      console.log("收到响应状态:", response.status);
+      if (!response.ok) {
+        throw new Error(`HTTP错误! 状态: ${response.status}`);
+      }
+      return response.json();
+    })
+    .then(data => {
+      console.log("收到响应数据:", data);
+      if (data.error) {
+        console.error("转换错误:", data.error);
+        htmlEditor.setValue(`<!-- 转换错误: ${data.error} -->`);
+      } else {
+        htmlEditor.setValue(data.html || '');
+      }
+    })
+    .catch(error => {
+      console.error('转换错误:', error);
+      htmlEditor.setValue(`<!-- 转换错误: ${error.message} -->`);
+    })
+    .finally(() => {
+      isUpdating = false;
+      console.log("Go到HTML转换完成");
+    });
+}
+
+// 示例库：示例及其推荐选项和转换结果由服务端的 /api/examples 提供
+const exampleGallery = {
+  setup() {
+    const category = document.getElementById('exampleCategory');
+    const search = document.getElementById('exampleSearch');
+    if (category) {
+      category.addEventListener('change', () => this.refresh());
+    }
+    if (search) {
+      search.addEventListener('input', debounce(() => this.refresh(), 300));
+    }
+    this.refresh();
+  },
+
+  async refresh() {
+    const container = document.getElementById('examples');
+    if (!container) return;
+    const category = document.getElementById('exampleCategory');
+    const search = document.getElementById('exampleSearch');
+
+    const params = new URLSearchParams();
+    if (category && category.value) params.set('category', category.value);
+    if (search && search.value.trim()) params.set('q', search.value.trim());
+
+    try {
+      const response = await fetch(getApiUrl(`/api/examples?${params}`));
+      if (!response.ok) {
+        throw new Error(`HTTP错误! 状态: ${response.status}`);
+      }
+      const data = await response.json();
+
+      // 首次加载时填充分类选项
+      if (category && category.options.length === 1) {
+        data.categories.forEach(function (name) {
+          const option = document.createElement('option');
+          option.value = name;
+          option.textContent = name;
+          category.appendChild(option);
+        });
+      }
+
+      container.innerHTML = '';
+      data.examples.forEach(function (example) {
+        const card = document.createElement('div');
+        card.className = 'bg-white rounded-lg shadow p-4 cursor-pointer hover:shadow-lg transition duration-300';
+        card.dataset.exampleId = example.id;
+        card.dataset.exampleName = example.title;
+
+        const title = document.createElement('h3');
+        title.className = 'text-lg font-semibold text-gray-800 mb-2';
+        title.textContent = example.title;
+        card.appendChild(title);
+
+        const description = document.createElement('p');
+        description.className = 'text-gray-600 text-sm';
+        description.textContent = example.description || '';
+        card.appendChild(description);
+
+        const tags = document.createElement('p');
+        tags.className = 'mt-2 text-xs text-gray-400';
+        tags.textContent = [example.category].concat(example.tags).join(' · ');
+        card.appendChild(tags);
+
+        container.appendChild(card);
+      });
+      if (data.examples.length === 0) {
+        container.textContent = '没有匹配的示例';
+      }
+    } catch (error) {
+      console.error('加载示例失败:', error);
+      container.textContent = `加载示例失败: ${error.message}`;
+    }
+  },
+};
+
+// 加载示例代码，使用示例推荐的选项和服务端预先转换的结果
+async function loadExample(id) {
+  console.log(`加载示例 ${id}`); // 添加日志，帮助调试
+
+  if (!htmlEditor) {
+    console.error('HTML编辑器未初始化');
+    return;
+  }
+
+  try {
+    const response = await fetch(getApiUrl(`/api/examples/${encodeURIComponent(id)}`));
+    if (!response.ok) {
+      throw new Error(`HTTP错误! 状态: ${response.status}`);
+    }
+    const example = await response.json();
+
+    isUpdating = true;
+    packagePrefix = example.options.packagePrefix;
+    vuetifyPrefix = example.options.vuetifyPrefix;
+    vuetifyXPrefix = example.options.vuetifyXPrefix;
+    document.getElementById('packagePrefix').value = packagePrefix;
+    document.getElementById('vuetifyPrefix').value = vuetifyPrefix;
+    document.getElementById('vuetifyXPrefix').value = vuetifyXPrefix;
+    htmlEditor.setValue(example.html);
+    goEditor.setValue(example.code);
+  } catch (error) {
+    console.error('加载示例失败:', error);
+    htmlEditor.setValue('<div>示例加载失败</div>');
+  } finally {
+    isUpdating = false;
+  }
+}
+
+// 添加编辑器内容变化监听器
+function setupEditorListeners() {
+  // 不再自动转换，改为手动按钮触发
+
+  // 使用Analytics模块设置所有编辑器分析事件
+  if (window.Analytics) {
+    window.Analytics.setupEditorAnalytics(htmlEditor, goEditor);
+  }
+
+  // 启用实时转换时，每次编辑都发送给服务端
+  if (liveConversion.enabled()) {
+    liveConversion.connect();
+    htmlEditor.onDidChangeModelContent(function () {
+      if (!isUpdating) {
+        liveConversion.send();
+      }
+    });
+  }
+
+  // 添加HTML到Go转换按钮事件
+  const htmlToGoBtn = document.getElementById('htmlToGoBtn');
+  if (htmlToGoBtn) {
+    htmlToGoBtn.addEventListener('click', function () {
+      console.log("点击了HTML到Go转换按钮");
+      htmlToGoConversion();
+    });
+  }
+
+  // 添加Go到HTML转换按钮事件
+  const goToHtmlBtn = document.getElementById('goToHtmlBtn');
+  if (goToHtmlBtn) {
+    goToHtmlBtn.addEventListener('click', function () {
+      console.log("点击了Go到HTML转换按钮");
+      goToHtmlConversion();
+    });
+  }
+
+  // 确保Go编辑器是可编辑的
+  setTimeout(function () {
+    // 强制设置Go编辑器为可编辑状态
+    if (goEditor) {
+      goEditor.updateOptions({ readOnly: false, domReadOnly: false });
+      console.log("已强制设置Go编辑器为可编辑状态");
+
+      // 获取编辑器DOM元素并确保它不是只读的
+      const goEditorElement = document.getElementById('rightEditor');
+      if (goEditorElement) {
+        const editorDomNode = goEditorElement.querySelector('.monaco-editor');
+        if (editorDomNode) {
+          editorDomNode.setAttribute('aria-readonly', 'false');
+          console.log("已设置编辑器DOM元素为非只读");
+        }
+      }
+    }
+  }, 1000);
+}
+
+// 分享链接：保存当前HTML、选项和转换结果，生成 /s/{id} 永久链接
+function setupSharing() {
+  const shareBtn = document.getElementById('shareBtn');
+  const snippetError = document.getElementById('snippetError');
+
+  if (snippetData.error && snippetError) {
+    snippetError.textContent = `分享的代码片段无法打开: ${snippetData.error}`;
+    snippetError.classList.remove('hidden');
+  }
+
+  if (shareBtn) {
+    shareBtn.addEventListener('click', shareSnippet);
+  }
+}
+
+async function shareSnippet() {
+  const shareResult = document.getElementById('shareResult');
+  const shareLink = document.getElementById('shareLink');
+  const shareInfo = document.getElementById('shareInfo');
+  const expiry = document.getElementById('snippetExpiry');
+
+  try {
+    const response = await fetch(getApiUrl('/api/snippets'), {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({
+        html: htmlEditor.getValue(),
+        packagePrefix: packagePrefix,
+        vuetifyPrefix: vuetifyPrefix,
+        vuetifyXPrefix: vuetifyXPrefix,
+        direction: "html2go",
+        expiresIn: expiry ? expiry.value : '',
+      }),
+    });
+    const data = await response.json();
+    if (!response.ok) {
+      throw new Error(data.error || '分享失败');
+    }
+
+    // 删除令牌只返回一次，保存在本地以便之后删除
+    const tokens = JSON.parse(localStorage.getItem('snippetTokens') || '{}');
+    tokens[data.id] = data.deleteToken;
+    localStorage.setItem('snippetTokens', JSON.stringify(tokens));
+
+    shareLink.value = new URL(data.url, window.location.href).href;
+    shareInfo.textContent = data.expiresAt
+      ? `有效期至 ${new Date(data.expiresAt).toLocaleString()}，删除令牌: ${data.deleteToken}`
+      : `永久有效，删除令牌: ${data.deleteToken}`;
+    shareResult.classList.remove('hidden');
+    shareLink.select();
+  } catch (error) {
+    console.error('分享失败:', error);
+    shareInfo.textContent = `分享失败: ${error.message}`;
+    shareResult.classList.remove('hidden');
+  }
+}
+
+// 转换历史：每次转换保存为服务端的不可变版本，可以恢复或对比任意版本
+const conversionHistory = {
+  id: localStorage.getItem('historyId'),
+
+  setup() {
+    const restoreBtn = document.getElementById('historyRestoreBtn');
+    const diffBtn = document.getElementById('historyDiffBtn');
+    if (restoreBtn) {
+      restoreBtn.addEventListener('click', () => this.restore());
+    }
+    if (diffBtn) {
+      diffBtn.addEventListener('click', () => this.diff());
+    }
+    this.refresh();
+  },
+
+  async save(requestBody) {
+    try {
+      const url = this.id ? `/api/history/${this.id}/revisions` : '/api/history';
+      const response = await fetch(getApiUrl(url), {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(requestBody),
+      });
+      // 历史已不存在时改为新建一个历史
+      if (response.status === 404 && this.id) {
+        this.id = null;
+        localStorage.removeItem('historyId');
+        return this.save(requestBody);
+      }
+      if (!response.ok) {
+        return;
+      }
+      const revision = await response.json();
+      this.id = revision.historyId;
+      localStorage.setItem('historyId', this.id);
+      this.refresh();
+    } catch (error) {
+      console.warn('保存转换历史失败:', error);
+    }
+  },
+
+  async refresh() {
+    if (!this.id) return;
+    const response = await fetch(getApiUrl(`/api/history/${this.id}/revisions`));
+    if (!response.ok) {
+      // 历史已不存在（例如服务端只保存在内存中且已重启）
+      if (response.status === 404) {
+        this.id = null;
+        localStorage.removeItem('historyId');
+      }
+      return;
+    }
+    const data = await response.json();
+    const select = document.getElementById('historySelect');
+    select.innerHTML = '';
+    data.revisions.slice().reverse().forEach(function (revision) {
+      const option = document.createElement('option');
+      option.value = revision.number;
+      let label = `版本 ${revision.number} · ${new Date(revision.createdAt).toLocaleString()}`;
+      if (revision.restoredFrom) {
+        label += `（恢复自版本 ${revision.restoredFrom}）`;
+      }
+      option.textContent = label;
+      select.appendChild(option);
+    });
+    this.head = data.head;
+    document.getElementById('historyPanel').classList.remove('hidden');
+  },
+
+  async restore() {
+    const number = document.getElementById('historySelect').value;
+    if (!this.id || !number) return;
+    const response = await fetch(getApiUrl(`/api/history/${this.id}/revisions/${number}/restore`), { method: 'POST' });
+    if (!response.ok) return;
+    const revision = await response.json();
+
+    // 恢复输入、选项和转换结果，不触发新的保存
+    isUpdating = true;
+    packagePrefix = revision.packagePrefix;
+    vuetifyPrefix = revision.vuetifyPrefix;
+    vuetifyXPrefix = revision.vuetifyXPrefix;
+    document.getElementById('packagePrefix').value = packagePrefix;
+    document.getElementById('vuetifyPrefix').value = vuetifyPrefix;
+    document.getElementById('vuetifyXPrefix').value = vuetifyXPrefix;
+    htmlEditor.setValue(revision.html);
+    goEditor.setValue(revision.code);
+    isUpdating = false;
+    this.refresh();
+  },
+
+  async diff() {
+    const number = document.getElementById('historySelect').value;
+    if (!this.id || !number) return;
+    const response = await fetch(getApiUrl(`/api/history/${this.id}/diff?from=${number}&to=${this.head}`));
+    if (!response.ok) return;
+    const data = await response.json();
+
+    const lines = [];
+    data.options.forEach(function (change) {
+      lines.push(`选项 ${change.path}: ${change.old} → ${change.new}`);
+    });
+    data.html.forEach(function (change) {
+      const labels = { added: '新增', removed: '删除', changed: '修改' };
+      lines.push(`HTML ${labels[change.op]} ${change.path} ${change.old || ''}${change.op === 'changed' ? ' → ' : ''}${change.new || ''}`);
+    });
+    if (data.go.unified) {
+      lines.push('', data.go.unified);
+    }
+    const pre = document.getElementById('historyDiff');
+    pre.textContent = lines.length ? lines.join('\n') : '两个版本相同';
+    pre.classList.remove('hidden');
+  },
+};
+
+// 实时预览：HTML经服务端清理后保存为 /preview/{token}，在无权限的沙箱iframe中显示
+const livePreview = {
+  stylesheets: [],
+
+  async setup() {
+    const enabled = document.getElementById('previewEnabled');
+    if (!enabled) return;
+    enabled.checked = localStorage.getItem('previewEnabled') === 'true';
+    enabled.addEventListener('change', () => {
+      localStorage.setItem('previewEnabled', enabled.checked);
+      this.refresh();
+    });
+
+    // 只显示服务端已内置的样式表
+    try {
+      const response = await fetch(getApiUrl('/api/preview'));
+      if (response.ok) {
+        const data = await response.json();
+        const container = document.getElementById('previewStylesheets');
+        data.stylesheets.forEach((name) => {
+          const label = document.createElement('label');
+          label.className = 'flex items-center space-x-1';
+          const input = document.createElement('input');
+          input.type = 'checkbox';
+          input.value = name;
+          input.addEventListener('change', () => this.refresh());
+          const text = document.createElement('span');
+          text.textContent = name;
+          label.append(input, text);
+          container.appendChild(label);
+        });
+      }
+    } catch (error) {
+      console.warn('加载预览样式表失败:', error);
+    }
+
+    htmlEditor.onDidChangeModelContent(debounce(() => this.refresh(), 800));
+    this.refresh();
+  },
+
+  async refresh() {
+    const frame = document.getElementById('previewFrame');
+    const info = document.getElementById('previewInfo');
+    if (!document.getElementById('previewEnabled').checked) {
+      frame.classList.add('hidden');
+      frame.removeAttribute('src');
+      info.textContent = '';
+      return;
+    }
+    const html = htmlEditor.getValue();
+    if (!html.trim()) return;
+
+    const stylesheets = Array.from(document.querySelectorAll('#previewStylesheets input:checked')).map((input) => input.value);
+    try {
+      const response = await fetch(getApiUrl('/api/preview'), {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify({ html: html, stylesheets: stylesheets }),
+      });
+      const data = await response.json();
+      if (!response.ok) {
+        throw new Error(data.error || '预览失败');
+      }
+      frame.src = getApiUrl(data.url);
+      frame.classList.remove('hidden');
+      info.textContent = data.removed.length ? `预览中已移除: ${data.removed.join(', ')}` : '';
+    } catch (error) {
+      console.error('预览失败:', error);
+      info.textContent = `预览失败: ${error.message}`;
+    }
+  },
+};
+
+// 实时转换：启用live功能时通过WebSocket发送每次编辑，服务端合并快速编辑并只返回最新结果
+const liveConversion = {
+  socket: null,
+  seq: 0,
+  retryDelay: 1000,
+
+  enabled() {
+    return (appConfig.features || []).includes('live');
+  },
+
+  connect() {
+    const url = new URL(getApiUrl('/api/live'), window.location.href);
+    url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+    const socket = new WebSocket(url.href);
+
+    socket.onopen = () => {
+      this.socket = socket;
+      this.retryDelay = 1000;
+      this.send();
+    };
+    socket.onmessage = (event) => this.receive(JSON.parse(event.data));
+    socket.onclose = () => {
+      // 断开后回退到HTTP转换，并稍后重连
+      this.socket = null;
+      setTimeout(() => this.connect(), this.retryDelay);
+      this.retryDelay = Math.min(this.retryDelay * 2, 30000);
+    };
+  },
+
+  // 发送当前文档和转换选项，返回是否已通过WebSocket发送
+  send() {
+    if (!this.socket || this.socket.readyState !== WebSocket.OPEN || !htmlEditor) {
+      return false;
+    }
+    const html = htmlEditor.getValue();
+    if (!html.trim()) {
+      return false;
+    }
+    this.seq++;
+    this.socket.send(JSON.stringify({
+      seq: this.seq,
+      html: html,
+      packagePrefix: packagePrefix,
+      vuetifyPrefix: vuetifyPrefix,
+      vuetifyXPrefix: vuetifyXPrefix,
+      direction: "html2go"
+    }));
+    return true;
+  },
+
+  receive(message) {
+    // 忽略已被后续编辑取代的结果
+    if (message.seq !== this.seq || isUpdating) {
+      return;
+    }
+    isUpdating = true;
+    try {
+      if (message.type === 'result') {
+        goEditor.setValue(message.code || '// 转换失败');
+      } else {
+        goEditor.setValue(`// 转换错误: ${message.error}`);
+      }
+      (message.diagnostics || []).forEach((d) => console.warn('转换诊断:', d));
+    } finally {
+      isUpdating = false;
+    }
+  }
+};
+
+// 测试Vuetify和VuetifyX前缀设置
+function testPrefixes() {
+  console.log("正在测试Vuetify和VuetifyX前缀设置...");
+
+  // 测试HTML
+  const testHTML = `<div>
+    <v-btn>测试按钮</v-btn>
+    <vx-dialog title="测试对话框">测试内容</vx-dialog>
+  </div>`;
+
+  // 准备请求体
+  const requestBody = {
+    html: testHTML,
+    packagePrefix: "h",
+    vuetifyPrefix: vuetifyPrefix,
+    vuetifyXPrefix: vuetifyXPrefix,
+    direction: "html2go"
+  };
+
+  // 在开发环境中测试前缀
+  fetch(getApiUrl('/api/v1/convert'), {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(requestBody)
+  })
+    .then(response => response.json())
+    .then(data => {
+      if (data.code) {
+        // 检查生成的代码是否包含正确的前缀
+        const hasVuetifyPrefix = data.code.includes(`${vuetifyPrefix}.VBtn`);
+        const hasVuetifyXPrefix = data.code.includes(`${vuetifyXPrefix}.VXDialog`);
+        const hasHVPrefix = data.code.includes('hv.');
+        const hasHVXPrefix = data.code.includes('hvx.');
+
+        console.log(`前缀测试结果:
+        - Vuetify前缀(${vuetifyPrefix})正确: ${hasVuetifyPrefix ? '✓' : '✗'}
+        - VuetifyX前缀(${vuetifyXPrefix})正确: ${hasVuetifyXPrefix ? '✓' : '✗'}
+        - 存在错误前缀hv: ${hasHVPrefix ? '✗' : '✓'}
+        - 存在错误前缀hvx: ${hasHVXPrefix ? '✗' : '✓'}
+      `);
+
+        if (!hasVuetifyPrefix || !hasVuetifyXPrefix || hasHVPrefix || hasHVXPrefix) {
+          console.error("前缀测试失败! 请检查默认前缀设置。");
+          console.error("生成的代码:", data.code);
+        } else {
+          console.log("前缀测试通过!");
+        }
+      } else {
+        console.error("前缀测试失败! 未收到有效的代码响应。");
+        console.error("响应数据:", data);
+      }
+    })
+    .catch(error => {
+      console.error("前缀测试错误:", error);
+    });
+}
+// 通过事件委托绑定示例卡片和埋点，页面中不使用内联事件处理器以满足CSP
+document.addEventListener('click', function (event) {
+  const example = event.target.closest('[data-example-id]');
+  if (example) {
+    const id = example.dataset.exampleId;
+    loadExample(id);
+    if (window.Analytics) {
+      window.Analytics.trackButtonClick('load_example', { example_id: id, example_name: example.dataset.exampleName });
+    }
+    return;
+  }
+
+  const tracked = event.target.closest('[data-track]');
+  if (tracked && window.Analytics) {
+    window.Analytics.trackButtonClick(tracked.dataset.track);
+  }
+});
+
+document.addEventListener('change', function (event) {
+  const input = event.target.closest('[data-track-change]');
+  if (input && window.Analytics) {
+    window.Analytics.trackButtonClick(input.dataset.trackChange, { value: input.value });
+  }
+});